@@ -3,6 +3,7 @@ package app
 import (
 	"io"
 	"os"
+	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	cmn "github.com/tendermint/tendermint/libs/common"
@@ -28,6 +29,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/x/staking"
 	"github.com/cosmos/cosmos-sdk/x/supply"
 
+	marketclient "github.com/xar-network/xar-network/x/market/client"
 	markettypes "github.com/xar-network/xar-network/x/market/types"
 	"github.com/xar-network/xar-network/x/nft"
 
@@ -49,6 +51,7 @@ import (
 	"github.com/xar-network/xar-network/embedded/fill"
 	embeddedorder "github.com/xar-network/xar-network/embedded/order"
 	"github.com/xar-network/xar-network/embedded/price"
+	"github.com/xar-network/xar-network/embedded/stream"
 	"github.com/xar-network/xar-network/execution"
 	"github.com/xar-network/xar-network/types"
 	"github.com/xar-network/xar-network/x/market"
@@ -56,7 +59,13 @@ import (
 	ordertypes "github.com/xar-network/xar-network/x/order/types"
 )
 
-const appName = "xar"
+const (
+	appName = "xar"
+
+	// localConsumerName is the event queue subscription of the embedded
+	// market data keepers.
+	localConsumerName = "local"
+)
 
 var (
 	// default home directories for xarcli
@@ -74,7 +83,12 @@ var (
 		staking.AppModuleBasic{},
 		mint.AppModuleBasic{},
 		distr.AppModuleBasic{},
-		gov.NewAppModuleBasic(paramsclient.ProposalHandler, distr.ProposalHandler),
+		gov.NewAppModuleBasic(
+			paramsclient.ProposalHandler,
+			distr.ProposalHandler,
+			marketclient.CreateMarketProposalHandler,
+			marketclient.UpdateMarketProposalHandler,
+		),
 		params.AppModuleBasic{},
 		crisis.AppModuleBasic{},
 		slashing.AppModuleBasic{},
@@ -128,7 +142,7 @@ func MakeCodec() *codec.Codec {
 type XarApp struct {
 	*bam.BaseApp
 	cdc *codec.Codec
-	mq  types.Backend
+	mq  *types.LogBackend
 
 	invCheckPeriod uint
 
@@ -181,19 +195,23 @@ func NewXarApp(
 	cdc := MakeCodec()
 
 	fillKeeper := fill.NewKeeper(mktDataDB, cdc)
+	if count := fillKeeper.MigrateOwnerIndex(); count > 0 {
+		logger.Info("indexed fills by owner", "count", count)
+	}
 	priceKeeper := price.NewKeeper(mktDataDB, cdc)
+	if count := priceKeeper.MigrateCandles(); count > 0 {
+		logger.Info("rolled up ticks into candles", "count", count)
+	}
 	embOrderKeeper := embeddedorder.NewKeeper(mktDataDB, cdc)
 	batchKeeper := batch.NewKeeper(mktDataDB, cdc)
 
-	queue := types.NewMemBackend()
-	queue.Start()
-	consumer := types.NewLocalConsumer(queue, []types.EventHandler{
+	queue := types.NewLogBackend(mktDataDB)
+	consumer := types.NewLocalConsumer(queue.Subscribe(localConsumerName), []types.EventHandler{
 		fillKeeper,
 		priceKeeper,
 		embOrderKeeper,
 		batchKeeper,
 	})
-	consumer.Start()
 
 	bApp := bam.NewBaseApp(appName, logger, db, auth.DefaultTxDecoder(cdc), baseAppOptions...)
 	bApp.SetCommitMultiStoreTracer(traceStore)
@@ -213,6 +231,7 @@ func NewXarApp(
 	app := &XarApp{
 		BaseApp:        bApp,
 		cdc:            cdc,
+		mq:             queue,
 		invCheckPeriod: invCheckPeriod,
 		keys:           keys,
 		tKeys:          tKeys,
@@ -261,8 +280,8 @@ func NewXarApp(
 	app.liquidatorKeeper = liquidator.NewKeeper(app.cdc, keys[liquidator.StoreKey], liquidatorSubspace, app.csdtKeeper, app.auctionKeeper, app.bankKeeper, app.supplyKeeper)
 
 	app.marketKeeper = market.NewKeeper(keys[markettypes.StoreKey], app.cdc, marketSubspace, market.DefaultCodespace)
-	app.orderKeeper = order.NewKeeper(app.supplyKeeper, app.marketKeeper, keys[ordertypes.StoreKey], queue, app.cdc)
-	app.execKeeper = execution.NewKeeper(queue, app.marketKeeper, app.orderKeeper, app.bankKeeper)
+	app.orderKeeper = order.NewKeeper(app.supplyKeeper, app.marketKeeper, app.oracleKeeper, keys[ordertypes.StoreKey], queue, app.cdc)
+	app.execKeeper = execution.NewKeeper(queue, app.marketKeeper, app.orderKeeper, app.bankKeeper, auth.FeeCollectorName)
 
 	app.denominationsKeeper = denominations.NewKeeper(keys[denominations.StoreKey], app.cdc, app.accountKeeper, app.supplyKeeper, denominationsSubspace, denominations.DefaultCodespace)
 
@@ -275,7 +294,8 @@ func NewXarApp(
 	govRouter := gov.NewRouter()
 	govRouter.AddRoute(gov.RouterKey, gov.ProposalHandler).
 		AddRoute(params.RouterKey, params.NewParamChangeProposalHandler(app.paramsKeeper)).
-		AddRoute(distr.RouterKey, distr.NewCommunityPoolSpendProposalHandler(app.distrKeeper))
+		AddRoute(distr.RouterKey, distr.NewCommunityPoolSpendProposalHandler(app.distrKeeper)).
+		AddRoute(market.RouterKey, market.NewProposalHandler(app.marketKeeper))
 	app.govKeeper = gov.NewKeeper(app.cdc, keys[gov.StoreKey], govSubspace,
 		app.supplyKeeper, &stakingKeeper, gov.DefaultCodespace, govRouter)
 
@@ -325,6 +345,7 @@ func NewXarApp(
 		mint.ModuleName,
 		distr.ModuleName,
 		slashing.ModuleName,
+		order.ModuleName,
 	)
 
 	app.mm.SetOrderEndBlockers(
@@ -350,7 +371,8 @@ func NewXarApp(
 		AddRoute("fill", fill.NewQuerier(fillKeeper)).
 		AddRoute("price", price.NewQuerier(priceKeeper)).
 		AddRoute("book", book.NewQuerier(embOrderKeeper)).
-		AddRoute("batch", batch.NewQuerier(batchKeeper))
+		AddRoute("batch", batch.NewQuerier(batchKeeper)).
+		AddRoute("stream", stream.NewQuerier(queue))
 
 	app.mm.RegisterInvariants(&app.crisisKeeper)
 	app.mm.RegisterRoutes(app.Router(), app.QueryRouter())
@@ -402,22 +424,66 @@ func NewXarApp(
 			cmn.Exit(err.Error())
 		}
 	}
+	if err := queue.Recover(app.LastBlockHeight()); err != nil {
+		cmn.Exit(err.Error())
+	}
+	consumer.Start()
 	return app
 }
 
 // application updates every begin block
 func (app *XarApp) BeginBlocker(ctx sdk.Context, req abci.RequestBeginBlock) abci.ResponseBeginBlock {
+	defer app.mq.CommitTx()
 	return app.mm.BeginBlock(ctx, req)
 }
 
 // application updates every end block
 func (app *XarApp) EndBlocker(ctx sdk.Context, req abci.RequestEndBlock) abci.ResponseEndBlock {
+	defer app.mq.CommitTx()
 	app.performMatching(ctx)
 	return app.mm.EndBlock(ctx, req)
 }
 
+// DeliverTx implements the ABCI interface. Events published by a transaction
+// are only kept if it succeeds.
+func (app *XarApp) DeliverTx(req abci.RequestDeliverTx) abci.ResponseDeliverTx {
+	res := app.BaseApp.DeliverTx(req)
+	if res.IsOK() {
+		app.mq.CommitTx()
+	} else {
+		app.mq.DiscardTx()
+	}
+	return res
+}
+
+// Query implements the ABCI interface. Events published while simulating a
+// transaction are dropped.
+func (app *XarApp) Query(req abci.RequestQuery) abci.ResponseQuery {
+	defer app.mq.DiscardTx()
+	return app.BaseApp.Query(req)
+}
+
+// Commit implements the ABCI interface. The events of the block are written
+// to the queue before the application state is committed, and delivered to
+// consumers after.
+func (app *XarApp) Commit() abci.ResponseCommit {
+	height := app.LastBlockHeight() + 1
+	if err := app.mq.WriteBlock(height); err != nil {
+		panic(err)
+	}
+	res := app.BaseApp.Commit()
+	// the block is not committed if the node halted
+	if app.LastBlockHeight() == height {
+		if err := app.mq.Commit(); err != nil {
+			panic(err)
+		}
+	}
+	return res
+}
+
 // application update at chain initialization
 func (app *XarApp) InitChainer(ctx sdk.Context, req abci.RequestInitChain) abci.ResponseInitChain {
+	defer app.mq.CommitTx()
 	//app.Logger().Error(fmt.Sprintf("%s", req.String()))
 	var genesisState GenesisState
 	app.cdc.MustUnmarshalJSON(req.AppStateBytes, &genesisState)
@@ -453,6 +519,19 @@ func (app *XarApp) MQ() types.Backend {
 	return app.mq
 }
 
+// WaitForMarketData blocks until the embedded market data keepers have
+// handled every committed event.
+func (app *XarApp) WaitForMarketData() {
+	for app.mq.Acked(localConsumerName) < app.mq.Head() {
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// Close stops delivering events to the embedded market data keepers.
+func (app *XarApp) Close() {
+	app.mq.Close()
+}
+
 func (app *XarApp) MarketKeeper() market.Keeper {
 	return app.marketKeeper
 }
@@ -461,6 +540,10 @@ func (app *XarApp) OrderKeeper() order.Keeper {
 	return app.orderKeeper
 }
 
+func (app *XarApp) OracleKeeper() oracle.Keeper {
+	return app.oracleKeeper
+}
+
 func (app *XarApp) BankKeeper() bank.Keeper {
 	return app.bankKeeper
 }
@@ -32,6 +32,8 @@ import (
 	"github.com/tendermint/tendermint/libs/cli"
 
 	"github.com/xar-network/xar-network/app"
+	batchclient "github.com/xar-network/xar-network/embedded/batch/client"
+	bookclient "github.com/xar-network/xar-network/embedded/book/client"
 	embeddedclient "github.com/xar-network/xar-network/embedded/client"
 )
 
@@ -72,9 +74,8 @@ func main() {
 		queryCmd(cdc),
 		txCmd(cdc),
 		client.LineBreak,
-		lcd.ServeCommand(cdc, func(server *lcd.RestServer) {
-			registerRoutes(server, cdc)
-		}),
+		restServerCmd(cdc),
+		embeddedclient.RotateSessionKeysCmd(),
 		client.LineBreak,
 		keys.Commands(),
 		client.LineBreak,
@@ -111,6 +112,10 @@ func queryCmd(cdc *amino.Codec) *cobra.Command {
 
 	// add modules' query commands
 	app.ModuleBasics.AddQueryCommands(queryCmd, cdc)
+	queryCmd.AddCommand(
+		batchclient.NewModuleClient(cdc).GetQueryCmd(),
+		bookclient.NewModuleClient(cdc).GetQueryCmd(),
+	)
 
 	return queryCmd
 }
@@ -151,6 +156,16 @@ func txCmd(cdc *amino.Codec) *cobra.Command {
 	return txCmd
 }
 
+func restServerCmd(cdc *amino.Codec) *cobra.Command {
+	cmd := lcd.ServeCommand(cdc, func(server *lcd.RestServer) {
+		registerRoutes(server, cdc)
+	})
+	cmd.PreRunE = func(_ *cobra.Command, _ []string) error {
+		return embeddedclient.InitAuth()
+	}
+	return embeddedclient.AddAuthFlags(cmd)
+}
+
 // registerRoutes registers the routes from the different modules for the LCD.
 // NOTE: details on the routes added for each module are in the module documentation
 // NOTE: If making updates here you also need to update the test helper in client/lcd/test_helper.go
@@ -78,6 +78,7 @@ func main() {
 	rootCmd.AddCommand(client.NewCompletionCmd(rootCmd, true))
 	rootCmd.AddCommand(testnetCmd(ctx, cdc, app.ModuleBasics, auth.GenesisAccountIterator{}))
 	rootCmd.AddCommand(replayCmd())
+	rootCmd.AddCommand(rebuildMarketDataCmd(ctx))
 	rootCmd.AddCommand(debug.Cmd(cdc))
 
 	server.AddCommands(ctx, cdc, rootCmd, newApp, exportAppStateAndTMValidators)
@@ -128,5 +129,5 @@ func exportAppStateAndTMValidators(
 
 func initMktDataDB() (dbm.DB, error) {
 	dir := path.Join(viper.GetString(cli.HomeFlag), "data")
-	return dbm.NewGoLevelDB("mktdata", dir)
+	return dbm.NewGoLevelDB(mktDataDBName, dir)
 }
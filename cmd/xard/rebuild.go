@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/cli"
+	"github.com/tendermint/tendermint/proxy"
+	tmsm "github.com/tendermint/tendermint/state"
+	tmstore "github.com/tendermint/tendermint/store"
+	tm "github.com/tendermint/tendermint/types"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/xar-network/xar-network/app"
+
+	"github.com/cosmos/cosmos-sdk/baseapp"
+	"github.com/cosmos/cosmos-sdk/server"
+	"github.com/cosmos/cosmos-sdk/store"
+)
+
+const (
+	mktDataDBName        = "mktdata"
+	rebuildMktDataDBName = "mktdata_rebuild"
+	rebuildAppDBName     = "application_rebuild"
+)
+
+func rebuildMarketDataCmd(ctx *server.Context) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rebuild-market-data",
+		Short: "Regenerate the embedded market data by replaying stored blocks",
+		Long: `Replays every block in the block store through a fresh application
+to regenerate the market data event stream, and replaces the market data
+database with the result. The previous database is kept as a backup. The
+node must be stopped while the command runs.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			// the market data database is opened for every command, and
+			// leveldb only allows a single process to hold it.
+			mktDataDB.Close()
+			return rebuildMarketData(ctx)
+		},
+		Args: cobra.NoArgs,
+	}
+}
+
+func rebuildMarketData(ctx *server.Context) error {
+	dataDir := filepath.Join(viper.GetString(cli.HomeFlag), "data")
+
+	fmt.Fprintln(os.Stderr, "Opening tendermint state database")
+	tmDB, err := dbm.NewGoLevelDB("state", dataDir)
+	if err != nil {
+		return err
+	}
+	defer tmDB.Close()
+
+	fmt.Fprintln(os.Stderr, "Opening blockstore database")
+	bcDB, err := dbm.NewGoLevelDB("blockstore", dataDir)
+	if err != nil {
+		return err
+	}
+	defer bcDB.Close()
+	blockStore := tmstore.NewBlockStore(bcDB)
+
+	// start from scratch if a previous rebuild was interrupted
+	for _, name := range []string{rebuildAppDBName, rebuildMktDataDBName} {
+		if err := os.RemoveAll(dbDir(dataDir, name)); err != nil {
+			return err
+		}
+	}
+	appDB, err := dbm.NewGoLevelDB(rebuildAppDBName, dataDir)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dbDir(dataDir, rebuildAppDBName))
+	defer appDB.Close()
+	mdb, err := dbm.NewGoLevelDB(rebuildMktDataDBName, dataDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "Creating application")
+	myapp := app.NewXarApp(
+		ctx.Logger, appDB, mdb, nil, true, uint(0),
+		baseapp.SetPruning(store.PruneEverything),
+	)
+	proxyApp := proxy.NewAppConns(proxy.NewLocalClientCreator(myapp))
+	if err := proxyApp.Start(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = proxyApp.Stop()
+	}()
+
+	fmt.Fprintln(os.Stderr, "Sending InitChain msg")
+	genDoc, err := tm.GenesisDocFromFile(ctx.Config.GenesisFile())
+	if err != nil {
+		return err
+	}
+	genState, err := tmsm.MakeGenesisState(genDoc)
+	if err != nil {
+		return err
+	}
+	_, err = proxyApp.Consensus().InitChainSync(abci.RequestInitChain{
+		Time:            genDoc.GenesisTime,
+		ChainId:         genDoc.ChainID,
+		ConsensusParams: tm.TM2PB.ConsensusParams(genDoc.ConsensusParams),
+		Validators:      tm.TM2PB.ValidatorUpdates(genState.Validators),
+		AppStateBytes:   genDoc.AppState,
+	})
+	if err != nil {
+		return err
+	}
+
+	height := blockStore.Height()
+	for i := int64(1); i <= height; i++ {
+		block := blockStore.LoadBlock(i)
+		if block == nil {
+			return fmt.Errorf("couldn't find block %d", i)
+		}
+		appHash, err := tmsm.ExecCommitBlock(proxyApp.Consensus(), block, ctx.Logger, tmDB)
+		if err != nil {
+			return err
+		}
+
+		// the app hash of a block is committed to by the next one
+		if next := blockStore.LoadBlockMeta(i + 1); next != nil && !bytes.Equal(appHash, next.Header.AppHash) {
+			return fmt.Errorf("app hash mismatch at block %d: got %X, expected %X", i, appHash, next.Header.AppHash)
+		}
+		if i%1000 == 0 {
+			fmt.Fprintf(os.Stderr, "Replayed block %d of %d\n", i, height)
+		}
+	}
+
+	fmt.Fprintln(os.Stderr, "Waiting for market data to be written")
+	myapp.WaitForMarketData()
+	myapp.Close()
+	mdb.Close()
+
+	oldDir := dbDir(dataDir, mktDataDBName)
+	backupDir := fmt.Sprintf("%s.%d.bak", oldDir, time.Now().Unix())
+	if _, err := os.Stat(oldDir); err == nil {
+		if err := os.Rename(oldDir, backupDir); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Moved previous market data to %s\n", backupDir)
+	}
+	if err := os.Rename(dbDir(dataDir, rebuildMktDataDBName), oldDir); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Rebuilt market data from %d blocks\n", height)
+	return nil
+}
+
+func dbDir(dataDir string, name string) string {
+	return filepath.Join(dataDir, name+".db")
+}
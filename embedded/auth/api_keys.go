@@ -0,0 +1,345 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/mintkey"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Scope limits what an API key can be used for.
+type Scope string
+
+const (
+	ScopeRead     Scope = "read"
+	ScopeTrade    Scope = "trade"
+	ScopeWithdraw Scope = "withdraw"
+
+	apiKeyHeader       = "X-API-Key"
+	apiNonceHeader     = "X-API-Nonce"
+	apiSignatureHeader = "X-API-Signature"
+
+	// nonces are millisecond timestamps and must be this close to the
+	// server's clock
+	nonceWindow = 30 * time.Second
+)
+
+var validScopes = map[Scope]bool{
+	ScopeRead:     true,
+	ScopeTrade:    true,
+	ScopeWithdraw: true,
+}
+
+// APIKey lets a program act as an account without a session. Requests are
+// signed with the secret. Keys that can trade or withdraw also hold the
+// account's private key, encrypted with the server's encryption key, which is
+// kept apart from the API keys so that their file alone cannot unlock it.
+type APIKey struct {
+	ID      string         `json:"id"`
+	Name    string         `json:"name"`
+	Address sdk.AccAddress `json:"address"`
+	Scopes  []Scope        `json:"scopes"`
+	Secret  string         `json:"secret,omitempty"`
+	Armor   string         `json:"armor,omitempty"`
+	Created time.Time      `json:"created"`
+}
+
+// HasScope reports whether a key has a scope. Every key can read.
+func (k APIKey) HasScope(scope Scope) bool {
+	if scope == ScopeRead {
+		return true
+	}
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Public returns the key without its secrets.
+func (k APIKey) Public() APIKey {
+	k.Secret = ""
+	k.Armor = ""
+	return k
+}
+
+type apiKeyEntry struct {
+	key           APIKey
+	kb            *Keybase
+	hotPassphrase string
+	lastNonce     int64
+}
+
+var apiKeys = make(map[string]*apiKeyEntry)
+var apiKeyFile string
+var apiKeyPassphrase string
+var apiKeyMtx sync.Mutex
+
+// LoadAPIKeys loads the API keys persisted in a file, which is created when
+// the first key is added. The private keys they hold are encrypted with the
+// key in encryptionKeyFile, which is generated if it does not exist.
+func LoadAPIKeys(path string, encryptionKeyFile string) error {
+	apiKeyMtx.Lock()
+	defer apiKeyMtx.Unlock()
+
+	passphrase, err := loadEncryptionKey(encryptionKeyFile)
+	if err != nil {
+		return err
+	}
+	apiKeyPassphrase = passphrase
+	apiKeyFile = path
+	apiKeys = make(map[string]*apiKeyEntry)
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var keys []APIKey
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return fmt.Errorf("invalid API key file %s: %v", path, err)
+	}
+	for _, key := range keys {
+		entry, err := newAPIKeyEntry(key, passphrase)
+		if err != nil {
+			return fmt.Errorf("invalid API key %s, or wrong encryption key: %v", key.ID, err)
+		}
+		apiKeys[key.ID] = entry
+	}
+	return nil
+}
+
+// AddAPIKey creates an API key for the account of a hot keybase. It returns
+// the key including its secret, which is not shown again.
+func AddAPIKey(kb *Keybase, passphrase string, scopes []Scope) (APIKey, error) {
+	for _, scope := range scopes {
+		if !validScopes[scope] {
+			return APIKey{}, fmt.Errorf("invalid scope %s", scope)
+		}
+	}
+
+	key := APIKey{
+		ID:      ReadStrN(16),
+		Name:    kb.GetName(),
+		Address: kb.GetAddr(),
+		Scopes:  scopes,
+		Secret:  ReadStr32(),
+		Created: now().UTC(),
+	}
+	apiKeyMtx.Lock()
+	cryptPassphrase := apiKeyPassphrase
+	apiKeyMtx.Unlock()
+	if key.HasScope(ScopeTrade) || key.HasScope(ScopeWithdraw) {
+		if cryptPassphrase == "" {
+			return APIKey{}, errors.New("API keys are not configured")
+		}
+		pk, err := mintkey.UnarmorDecryptPrivKey(kb.armor, passphrase)
+		if err != nil {
+			return APIKey{}, err
+		}
+		key.Armor = mintkey.EncryptArmorPrivKey(pk, cryptPassphrase)
+	}
+	entry, err := newAPIKeyEntry(key, cryptPassphrase)
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	apiKeyMtx.Lock()
+	defer apiKeyMtx.Unlock()
+	apiKeys[key.ID] = entry
+	if err := saveAPIKeysLocked(); err != nil {
+		delete(apiKeys, key.ID)
+		return APIKey{}, err
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns the keys of an account without their secrets, oldest
+// first.
+func ListAPIKeys(addr sdk.AccAddress) []APIKey {
+	apiKeyMtx.Lock()
+	defer apiKeyMtx.Unlock()
+
+	out := make([]APIKey, 0)
+	for _, entry := range apiKeys {
+		if entry.key.Address.Equals(addr) {
+			out = append(out, entry.key.Public())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Created.Before(out[j].Created)
+	})
+	return out
+}
+
+// RevokeAPIKey deletes a key of an account.
+func RevokeAPIKey(addr sdk.AccAddress, id string) error {
+	apiKeyMtx.Lock()
+	defer apiKeyMtx.Unlock()
+
+	entry, ok := apiKeys[id]
+	if !ok || !entry.key.Address.Equals(addr) {
+		return errors.New("API key not found")
+	}
+	delete(apiKeys, id)
+	if err := saveAPIKeysLocked(); err != nil {
+		apiKeys[id] = entry
+		return err
+	}
+	return nil
+}
+
+// SignRequest returns the signature of a request, the hex encoded HMAC-SHA256
+// of its method, path with query, nonce and body separated by newlines.
+func SignRequest(secret string, method string, uri string, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + uri + "\n" + nonce + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// authenticateAPIKey checks the signature of a request made with an API key
+// and returns the key. Nonces must increase with every request.
+func authenticateAPIKey(r *http.Request) (*apiKeyEntry, error) {
+	nonceStr := r.Header.Get(apiNonceHeader)
+	nonce, err := strconv.ParseInt(nonceStr, 10, 64)
+	if err != nil {
+		return nil, errors.New("invalid nonce")
+	}
+	if d := now().Sub(time.Unix(0, nonce*int64(time.Millisecond))); d > nonceWindow || d < -nonceWindow {
+		return nil, errors.New("nonce outside of the allowed window")
+	}
+
+	var body []byte
+	if r.Body != nil {
+		body, err = ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	apiKeyMtx.Lock()
+	defer apiKeyMtx.Unlock()
+
+	entry, ok := apiKeys[r.Header.Get(apiKeyHeader)]
+	if !ok {
+		return nil, errors.New("invalid API key")
+	}
+	sig, err := hex.DecodeString(r.Header.Get(apiSignatureHeader))
+	if err != nil {
+		return nil, errors.New("invalid signature")
+	}
+	expected, _ := hex.DecodeString(SignRequest(entry.key.Secret, r.Method, r.URL.RequestURI(), nonceStr, body))
+	if !hmac.Equal(sig, expected) {
+		return nil, errors.New("invalid signature")
+	}
+	if nonce <= entry.lastNonce {
+		return nil, errors.New("nonce already used")
+	}
+	entry.lastNonce = nonce
+	return entry, nil
+}
+
+// newAPIKeyEntry decrypts the private key of an API key, if it has one, into
+// a hot keybase.
+func newAPIKeyEntry(key APIKey, passphrase string) (*apiKeyEntry, error) {
+	entry := &apiKeyEntry{key: key}
+	if key.Armor == "" {
+		// read only keys cannot sign
+		entry.kb = &Keybase{name: key.Name, addr: key.Address}
+		return entry, nil
+	}
+	pk, err := mintkey.UnarmorDecryptPrivKey(key.Armor, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	entry.hotPassphrase = ReadStr32()
+	entry.kb = NewHotKeybase(key.Name, entry.hotPassphrase, pk)
+	return entry, nil
+}
+
+// loadEncryptionKey reads the hex encoded key that encrypts the private keys
+// of API keys, generating it if the file does not exist.
+func loadEncryptionKey(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err == nil {
+		key := strings.TrimSpace(string(b))
+		if raw, err := hex.DecodeString(key); err != nil || len(raw) < 32 {
+			return "", fmt.Errorf("invalid API key encryption key in %s", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	key := hex.EncodeToString(raw)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(path, []byte(key+"\n"), 0600); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func saveAPIKeysLocked() error {
+	if apiKeyFile == "" {
+		return nil
+	}
+	keys := make([]APIKey, 0, len(apiKeys))
+	for _, entry := range apiKeys {
+		keys = append(keys, entry.key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+	b, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(apiKeyFile), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(apiKeyFile, b, 0600)
+}
+
+type contextKey int
+
+const (
+	kbContextKey contextKey = iota
+	kbPassphraseContextKey
+)
+
+// withKB returns a request acting as the account of a keybase.
+func withKB(r *http.Request, kb *Keybase, passphrase string) *http.Request {
+	ctx := context.WithValue(r.Context(), kbContextKey, kb)
+	ctx = context.WithValue(ctx, kbPassphraseContextKey, passphrase)
+	return r.WithContext(ctx)
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+
+	"github.com/xar-network/xar-network/testutil/testflags"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/mintkey"
+)
+
+func TestAPIKeys(t *testing.T) {
+	testflags.UnitTest(t)
+	dir, err := ioutil.TempDir("", "api_keys")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "api_keys.json")
+	cryptKeyFile := filepath.Join(dir, "api_key_encryption_key")
+	require.NoError(t, LoadAPIKeys(keyFile, cryptKeyFile))
+
+	start := time.Unix(1000, 0)
+	now = func() time.Time { return start }
+	defer func() { now = time.Now }()
+
+	pk := secp256k1.GenPrivKey()
+	kb := NewHotKeybase("alice", "hot", pk)
+	readKey, err := AddAPIKey(kb, "hot", nil)
+	require.NoError(t, err)
+	tradeKey, err := AddAPIKey(kb, "hot", []Scope{ScopeTrade})
+	require.NoError(t, err)
+	_, err = AddAPIKey(kb, "hot", []Scope{"admin"})
+	assert.Error(t, err)
+
+	var seen *Keybase
+	var seenPassphrase string
+	handler := TradeAuthMW(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = MustGetKBFromSession(r)
+		seenPassphrase = MustGetKBPassphraseFromSession(r)
+		body, _ := ioutil.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+
+	nonce := start.UnixNano() / int64(time.Millisecond)
+	request := func(key APIKey, nonce int64, signedBody string, body string) *httptest.ResponseRecorder {
+		nonceStr := strconv.FormatInt(nonce, 10)
+		r := httptest.NewRequest("POST", "/exchange/orders?foo=bar", bytes.NewBufferString(body))
+		r.Header.Set(apiKeyHeader, key.ID)
+		r.Header.Set(apiNonceHeader, nonceStr)
+		r.Header.Set(apiSignatureHeader, SignRequest(key.Secret, "POST", "/exchange/orders?foo=bar", nonceStr, []byte(signedBody)))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("authenticates signed requests", func(t *testing.T) {
+		w := request(tradeKey, nonce, "{}", "{}")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "{}", w.Body.String())
+		assert.Equal(t, "alice", seen.GetName())
+		assert.Equal(t, kb.GetAddr(), seen.GetAddr())
+
+		// the hot keybase of the API key can sign
+		_, pub, err := seen.Sign("alice", seenPassphrase, []byte("msg"))
+		require.NoError(t, err)
+		assert.Equal(t, pk.PubKey(), pub)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		nonce++
+		assert.Equal(t, http.StatusUnauthorized, request(tradeKey, nonce, "{}", `{"price":"1"}`).Code)
+		assert.Equal(t, http.StatusUnauthorized, request(APIKey{ID: "foo", Secret: tradeKey.Secret}, nonce, "{}", "{}").Code)
+		assert.Equal(t, http.StatusUnauthorized, request(tradeKey, nonce-1, "{}", "{}").Code)
+		assert.Equal(t, http.StatusUnauthorized, request(tradeKey, nonce+int64(time.Minute/time.Millisecond), "{}", "{}").Code)
+		assert.Equal(t, http.StatusForbidden, request(readKey, nonce, "{}", "{}").Code)
+	})
+
+	t.Run("persists keys", func(t *testing.T) {
+		require.NoError(t, LoadAPIKeys(keyFile, cryptKeyFile))
+		keys := ListAPIKeys(kb.GetAddr())
+		require.Len(t, keys, 2)
+		assert.Empty(t, keys[0].Secret)
+
+		nonce++
+		assert.Equal(t, http.StatusOK, request(tradeKey, nonce, "{}", "{}").Code)
+	})
+
+	t.Run("keeps private keys apart from their secrets", func(t *testing.T) {
+		b, err := ioutil.ReadFile(keyFile)
+		require.NoError(t, err)
+		var stored []APIKey
+		require.NoError(t, json.Unmarshal(b, &stored))
+		for _, key := range stored {
+			if key.Armor == "" {
+				continue
+			}
+			_, err := mintkey.UnarmorDecryptPrivKey(key.Armor, key.Secret)
+			assert.Error(t, err)
+		}
+
+		other := filepath.Join(dir, "other_encryption_key")
+		assert.Error(t, LoadAPIKeys(keyFile, other), "keys cannot be loaded with another encryption key")
+		require.NoError(t, LoadAPIKeys(keyFile, cryptKeyFile))
+	})
+
+	t.Run("revokes keys", func(t *testing.T) {
+		assert.Error(t, RevokeAPIKey(NewHotKeybase("bob", "hot", secp256k1.GenPrivKey()).GetAddr(), tradeKey.ID))
+		require.NoError(t, RevokeAPIKey(kb.GetAddr(), tradeKey.ID))
+		nonce++
+		assert.Equal(t, http.StatusUnauthorized, request(tradeKey, nonce, "{}", "{}").Code)
+
+		require.NoError(t, LoadAPIKeys(keyFile, cryptKeyFile))
+		assert.Len(t, ListAPIKeys(kb.GetAddr()), 1)
+	})
+}
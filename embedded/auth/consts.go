@@ -2,5 +2,6 @@ package auth
 
 const (
 	sessionName = "uex_session"
+	// AccountName is the key the faucet sends from.
 	AccountName = "zafx"
 )
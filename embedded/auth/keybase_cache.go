@@ -4,17 +4,35 @@ import (
 	"errors"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/tendermint/tendermint/crypto"
 
 	"github.com/xar-network/xar-network/embedded/session"
 )
 
-var kb *Keybase
-var currID string
+// DefaultSessionTTL is how long a login stays valid.
+const DefaultSessionTTL = 24 * time.Hour
+
+type cachedKB struct {
+	kb      *Keybase
+	expires time.Time
+}
+
+// hot keybases of logged in users, by session keybase ID
+var kbs = make(map[string]cachedKB)
+var sessionTTL = DefaultSessionTTL
 var mtx sync.RWMutex
 
+// now is replaced in tests.
+var now = time.Now
+
+// GetKBFromSession returns the keybase a request acts as, either that of its
+// session or that of the API key it was signed with.
 func GetKBFromSession(r *http.Request) (*Keybase, error) {
+	if kb, ok := r.Context().Value(kbContextKey).(*Keybase); ok {
+		return kb, nil
+	}
 	id, err := session.GetStr(r, keybaseIDKey)
 	if err != nil {
 		return nil, err
@@ -35,23 +53,73 @@ func MustGetKBFromSession(r *http.Request) *Keybase {
 }
 
 func MustGetKBPassphraseFromSession(r *http.Request) string {
+	if passphrase, ok := r.Context().Value(kbPassphraseContextKey).(string); ok {
+		return passphrase
+	}
 	return session.MustGetStr(r, keybasePassphraseKey)
 }
 
+// GetKB returns the hot keybase of a session, or nil if the session has
+// expired or been revoked.
 func GetKB(id string) *Keybase {
 	mtx.RLock()
 	defer mtx.RUnlock()
-	if currID != id {
+	entry, ok := kbs[id]
+	if !ok || !now().Before(entry.expires) {
 		return nil
 	}
 
-	return kb
+	return entry.kb
+}
+
+// AddKB caches a hot keybase for a new session and returns the session's
+// keybase ID. Other sessions of the same user are left alone.
+func AddKB(name string, passphrase string, pk crypto.PrivKey) string {
+	mtx.Lock()
+	defer mtx.Unlock()
+	pruneLocked()
+	id := ReadStr32()
+	kbs[id] = cachedKB{
+		kb:      NewHotKeybase(name, passphrase, pk),
+		expires: now().Add(sessionTTL),
+	}
+	return id
 }
 
-func ReplaceKB(name string, passphrase string, pk crypto.PrivKey) string {
+// SetSessionTTL sets how long new logins stay valid.
+func SetSessionTTL(ttl time.Duration) {
 	mtx.Lock()
 	defer mtx.Unlock()
-	currID = ReadStr32()
-	kb = NewHotKeybase(name, passphrase, pk)
-	return currID
+	sessionTTL = ttl
+}
+
+// RevokeKB ends a session.
+func RevokeKB(id string) {
+	mtx.Lock()
+	defer mtx.Unlock()
+	delete(kbs, id)
+}
+
+// RevokeKBsByName ends every session of a user and returns how many were
+// ended.
+func RevokeKBsByName(name string) int {
+	mtx.Lock()
+	defer mtx.Unlock()
+	var count int
+	for id, entry := range kbs {
+		if entry.kb.GetName() == name {
+			delete(kbs, id)
+			count++
+		}
+	}
+	return count
+}
+
+func pruneLocked() {
+	t := now()
+	for id, entry := range kbs {
+		if !t.Before(entry.expires) {
+			delete(kbs, id)
+		}
+	}
 }
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+
+	"github.com/xar-network/xar-network/testutil/testflags"
+)
+
+func TestKeybaseCache(t *testing.T) {
+	testflags.UnitTest(t)
+	start := time.Unix(1000, 0)
+	now = func() time.Time { return start }
+	defer func() { now = time.Now }()
+
+	alice := secp256k1.GenPrivKey()
+	aliceID := AddKB("alice", "pw", alice)
+	aliceID2 := AddKB("alice", "pw", alice)
+	bobID := AddKB("bob", "pw", secp256k1.GenPrivKey())
+
+	t.Run("keeps a hot keybase per session", func(t *testing.T) {
+		require.NotNil(t, GetKB(aliceID))
+		assert.Equal(t, "alice", GetKB(aliceID).GetName())
+		assert.Equal(t, alice.PubKey().Address().Bytes(), GetKB(aliceID2).GetAddr().Bytes())
+		assert.Equal(t, "bob", GetKB(bobID).GetName())
+		assert.Nil(t, GetKB("unknown"))
+	})
+
+	t.Run("revokes sessions", func(t *testing.T) {
+		RevokeKB(aliceID)
+		assert.Nil(t, GetKB(aliceID))
+		assert.NotNil(t, GetKB(aliceID2))
+
+		assert.Equal(t, 1, RevokeKBsByName("alice"))
+		assert.Nil(t, GetKB(aliceID2))
+		assert.NotNil(t, GetKB(bobID))
+	})
+
+	t.Run("expires sessions", func(t *testing.T) {
+		now = func() time.Time { return start.Add(DefaultSessionTTL - time.Second) }
+		assert.NotNil(t, GetKB(bobID))
+		now = func() time.Time { return start.Add(DefaultSessionTTL) }
+		assert.Nil(t, GetKB(bobID))
+
+		// expired sessions are pruned on the next login
+		AddKB("carol", "pw", secp256k1.GenPrivKey())
+		assert.Equal(t, 0, RevokeKBsByName("bob"))
+	})
+}
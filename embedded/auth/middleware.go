@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -18,10 +19,42 @@ const (
 	csrfHeader           = "X-CSRF-Token"
 )
 
+// DefaultAuthMW lets through logged in users and requests signed with any
+// API key.
 func DefaultAuthMW(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		LoginRequiredMW(next).ServeHTTP(w, r)
-	})
+	return AuthMW(ScopeRead)(next)
+}
+
+// TradeAuthMW lets through logged in users and requests signed with an API
+// key that can trade.
+func TradeAuthMW(next http.Handler) http.Handler {
+	return AuthMW(ScopeTrade)(next)
+}
+
+// AuthMW lets through logged in users, and requests signed with an API key
+// that has the given scope. Requests carrying an API key header are never
+// authenticated by their session.
+func AuthMW(scope Scope) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(apiKeyHeader) == "" {
+				LoginRequiredMW(next).ServeHTTP(w, r)
+				return
+			}
+
+			entry, err := authenticateAPIKey(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			if !entry.key.HasScope(scope) {
+				http.Error(w, fmt.Sprintf("API key lacks the %s scope.", scope), http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, withKB(r, entry.kb, entry.hotPassphrase))
+		})
+	}
 }
 
 func LoginRequiredMW(next http.Handler) http.Handler {
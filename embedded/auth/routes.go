@@ -19,9 +19,13 @@ import (
 func RegisterRoutes(ctx context.CLIContext, r *mux.Router, cdc *codec.Codec) {
 	sub := r.PathPrefix("/auth").Subrouter()
 	sub.HandleFunc("/login", loginHandler()).Methods("POST")
-	sub.Handle("/logout", DefaultAuthMW(logoutHandler())).Methods("POST")
+	sub.Handle("/logout", LoginRequiredMW(logoutHandler())).Methods("POST")
+	sub.Handle("/logout_all", LoginRequiredMW(logoutAllHandler())).Methods("POST")
 	sub.HandleFunc("/csrf_token", csrfTokenHandler()).Methods("GET")
 	sub.Handle("/me", DefaultAuthMW(meHandler(ctx, cdc))).Methods("GET")
+	sub.Handle("/api_keys", LoginRequiredMW(createAPIKeyHandler())).Methods("POST")
+	sub.Handle("/api_keys", LoginRequiredMW(listAPIKeysHandler(ctx))).Methods("GET")
+	sub.Handle("/api_keys/{id}", LoginRequiredMW(revokeAPIKeyHandler())).Methods("DELETE")
 }
 
 type LoginRequest struct {
@@ -43,12 +47,12 @@ func loginHandler() http.HandlerFunc {
 			return
 		}
 
-		if req.Username != AccountName {
+		if req.Username == "" {
 			http.Error(w, "Invalid username or password.", http.StatusUnauthorized)
 			return
 		}
 
-		kbID, hotPW, err := authorize(req.Password)
+		kbID, hotPW, err := authorize(req.Username, req.Password)
 		if err != nil {
 			http.Error(w, "Invalid username or password.", http.StatusUnauthorized)
 			return
@@ -72,7 +76,11 @@ func logoutHandler() http.HandlerFunc {
 			return
 		}
 
+		if kbID, ok := store.Values[keybaseIDKey].(string); ok {
+			RevokeKB(kbID)
+		}
 		delete(store.Values, keybaseIDKey)
+		delete(store.Values, keybasePassphraseKey)
 		err = store.Save(r, w)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -83,6 +91,15 @@ func logoutHandler() http.HandlerFunc {
 	}
 }
 
+// logoutAllHandler ends every session of the logged in user, including
+// those in other browsers.
+func logoutAllHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		RevokeKBsByName(MustGetKBFromSession(r).GetName())
+		logoutHandler()(w, r)
+	}
+}
+
 func csrfTokenHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		tok, err := GetCSRFToken(r)
@@ -98,6 +115,7 @@ func csrfTokenHandler() http.HandlerFunc {
 }
 
 type MeResponse struct {
+	Name    string `json:"name"`
 	Address string `json:"address"`
 }
 
@@ -105,23 +123,74 @@ func meHandler(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		owner := MustGetKBFromSession(r)
 		addr := owner.GetAddr().String()
-		res := &MeResponse{Address: addr}
+		res := &MeResponse{Name: owner.GetName(), Address: addr}
 		resB := cdc.MustMarshalJSON(res)
 		embedded.PostProcessResponse(w, ctx, resB)
 	}
 }
 
-func authorize(passphrase string) (string, string, error) {
+type CreateAPIKeyRequest struct {
+	Scopes []Scope `json:"scopes"`
+}
+
+func createAPIKeyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var req CreateAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		key, err := AddAPIKey(MustGetKBFromSession(r), MustGetKBPassphraseFromSession(r), req.Scopes)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(key)
+	}
+}
+
+func listAPIKeysHandler(ctx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		keys := ListAPIKeys(MustGetKBFromSession(r).GetAddr())
+		resB, err := json.Marshal(keys)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		embedded.PostProcessResponse(w, ctx, resB)
+	}
+}
+
+func revokeAPIKeyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)["id"]
+		if err := RevokeAPIKey(MustGetKBFromSession(r).GetAddr(), id); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// authorize decrypts the named key from the home keyring and caches it as a
+// hot keybase for a new session. It returns the session's keybase ID and the
+// passphrase of the hot key.
+func authorize(name string, passphrase string) (string, string, error) {
 	kb, err := keys.NewKeyringFromHomeFlag(strings.NewReader(passphrase + "\n" + passphrase + "\n"))
 	if err != nil {
 		return "", "", err
 	}
 
-	pk, err := kb.ExportPrivateKeyObject(AccountName, passphrase)
+	pk, err := kb.ExportPrivateKeyObject(name, passphrase)
 	if err != nil {
 		return "", "", err
 	}
 
 	hotPassphrase := ReadStr32()
-	return ReplaceKB(AccountName, hotPassphrase, pk), hotPassphrase, nil
+	return AddKB(name, hotPassphrase, pk), hotPassphrase, nil
 }
@@ -22,7 +22,7 @@ import (
 
 func RegisterRoutes(ctx context.CLIContext, r *mux.Router, cdc *codec.Codec, enableFaucet bool) {
 	r.Handle("/user/balances", auth.DefaultAuthMW(getBalanceHandler(ctx, cdc))).Methods("GET")
-	r.Handle("/user/transfer", auth.LoginRequiredMW(auth.OTPRequiredMW(transferBalanceHandler(ctx, cdc)))).Methods("POST")
+	r.Handle("/user/transfer", auth.AuthMW(auth.ScopeWithdraw)(auth.OTPRequiredMW(transferBalanceHandler(ctx, cdc)))).Methods("POST")
 
 	if enableFaucet {
 		r.Handle("/faucet/transfer", faucetHandler(ctx, cdc)).Methods("POST")
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+
+	"github.com/xar-network/xar-network/embedded/batch"
+)
+
+func GetCmdGet(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get [market id] [block number]",
+		Short: "get the batch auction of a market at a block",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCLIContext().WithCodec(cdc)
+			out, err := queryBatch(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			return ctx.PrintOutput(out)
+		},
+	}
+}
+
+func GetCmdVerify(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify [market id] [block number]",
+		Short: "re-run the batch auction of a market at a block and check it against the record",
+		Long: `Fetches the batch auction of a market at a block, matches the orders it was
+run with again, and checks that the match table, clearing price, pro-rata
+factor and every fill agree with what was recorded.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCLIContext().WithCodec(cdc)
+			out, err := queryBatch(ctx, args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			diffs := out.Verify()
+			if len(diffs) > 0 {
+				return fmt.Errorf("batch does not match its record:\n%s", strings.Join(diffs, "\n"))
+			}
+			fmt.Printf(
+				"verified batch of market %s at block %d: clearing price %s, pro-rata factor %s, %d fills\n",
+				out.MarketID,
+				out.BlockNumber,
+				out.ClearingPrice,
+				out.ProRata,
+				len(out.Fills),
+			)
+			return nil
+		},
+	}
+}
+
+func queryBatch(ctx context.CLIContext, mktID string, blockNumber string) (batch.Batch, error) {
+	var out batch.Batch
+	res, _, err := ctx.QueryWithData(fmt.Sprintf("custom/batch/%s/%s/%s", batch.QueryGet, mktID, blockNumber), nil)
+	if err != nil {
+		return out, err
+	}
+	if len(res) == 0 {
+		return out, errors.New("no batch found")
+	}
+	err = ctx.Codec.UnmarshalJSON(res, &out)
+	return out, err
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tendermint/go-amino"
+
+	"github.com/cosmos/cosmos-sdk/client"
+
+	"github.com/xar-network/xar-network/embedded/batch/client/cli"
+)
+
+type ModuleClient struct {
+	cdc *amino.Codec
+}
+
+func NewModuleClient(cdc *amino.Codec) ModuleClient {
+	return ModuleClient{
+		cdc: cdc,
+	}
+}
+
+func (mc ModuleClient) GetQueryCmd() *cobra.Command {
+	batchQueryCmd := &cobra.Command{
+		Use:   "batch",
+		Short: "queries batch auction data",
+	}
+	batchQueryCmd.AddCommand(client.GetCommands(
+		cli.GetCmdGet(mc.cdc),
+		cli.GetCmdVerify(mc.cdc),
+	)...)
+	return batchQueryCmd
+}
@@ -45,6 +45,17 @@ func (k Keeper) LatestByMarket(marketID store.EntityID) (Batch, sdk.Error) {
 	return res, nil
 }
 
+// Get returns the batch of a market at the given block.
+func (k Keeper) Get(marketID store.EntityID, blockNumber int64) (Batch, sdk.Error) {
+	var res Batch
+	b := k.as.Get(batchKey(marketID, blockNumber))
+	if b == nil {
+		return res, errs.ErrNotFound("batch not found")
+	}
+	k.cdc.MustUnmarshalBinaryBare(b, &res)
+	return res, nil
+}
+
 func (k Keeper) OnBatchEvent(event types.Batch) {
 	batch := Batch{
 		BlockNumber:   event.BlockNumber,
@@ -53,6 +64,13 @@ func (k Keeper) OnBatchEvent(event types.Batch) {
 		ClearingPrice: event.ClearingPrice,
 		Bids:          event.Bids,
 		Asks:          event.Asks,
+		MatchTable:    event.MatchTable,
+		AggSupply:     event.AggSupply,
+		AggDemand:     event.AggDemand,
+		ProRata:       event.ProRata,
+		BidOrders:     event.BidOrders,
+		AskOrders:     event.AskOrders,
+		Fills:         event.Fills,
 	}
 	k.as.Set(batchKey(batch.MarketID, batch.BlockNumber), k.cdc.MustMarshalBinaryBare(batch))
 }
@@ -0,0 +1,65 @@
+package batch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/testutil/testflags"
+	"github.com/xar-network/xar-network/types"
+	"github.com/xar-network/xar-network/types/errs"
+	"github.com/xar-network/xar-network/types/store"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestKeeper(t *testing.T) {
+	testflags.UnitTest(t)
+	k := NewKeeper(dbm.NewMemDB(), codec.New())
+	mktID := store.NewEntityID(1)
+
+	m := matcheng.NewMatcher()
+	m.EnqueueOrder(matcheng.Bid, store.NewEntityID(1), sdk.NewUint(10), sdk.NewUint(30))
+	m.EnqueueOrder(matcheng.Bid, store.NewEntityID(2), sdk.NewUint(11), sdk.NewUint(30))
+	m.EnqueueOrder(matcheng.Ask, store.NewEntityID(3), sdk.NewUint(9), sdk.NewUint(40))
+	res := m.Match()
+	require.NotNil(t, res)
+	require.NoError(t, k.OnEvent(types.Batch{
+		BlockNumber:   10,
+		BlockTime:     time.Unix(1000, 0),
+		MarketID:      mktID,
+		ClearingPrice: res.ClearingPrice,
+		Bids:          res.BidAggregates,
+		Asks:          res.AskAggregates,
+		MatchTable:    res.MatchTable,
+		AggSupply:     res.AggSupply,
+		AggDemand:     res.AggDemand,
+		ProRata:       res.ProRata,
+		BidOrders:     res.Bids,
+		AskOrders:     res.Asks,
+		Fills:         res.Fills,
+	}))
+
+	t.Run("returns not found for a block without a batch", func(t *testing.T) {
+		_, err := k.Get(mktID, 11)
+		require.Error(t, err)
+		assert.Equal(t, errs.CodeNotFound, err.Code())
+	})
+	t.Run("verifies a stored batch", func(t *testing.T) {
+		stored, err := k.Get(mktID, 10)
+		require.NoError(t, err)
+		assert.True(t, res.ProRata.Equal(stored.ProRata))
+		assert.Empty(t, stored.Verify())
+	})
+	t.Run("reports a tampered fill", func(t *testing.T) {
+		stored, err := k.Get(mktID, 10)
+		require.NoError(t, err)
+		stored.Fills[0].QtyFilled = stored.Fills[0].QtyFilled.AddUint64(1)
+		assert.Len(t, stored.Verify(), 1)
+	})
+}
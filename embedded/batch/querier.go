@@ -1,6 +1,8 @@
 package batch
 
 import (
+	"strconv"
+
 	abci "github.com/tendermint/tendermint/abci/types"
 
 	"github.com/xar-network/xar-network/types/errs"
@@ -12,6 +14,7 @@ import (
 
 const (
 	QueryLatest = "latest"
+	QueryGet    = "get"
 )
 
 func NewQuerier(keeper Keeper) sdk.Querier {
@@ -19,6 +22,8 @@ func NewQuerier(keeper Keeper) sdk.Querier {
 		switch path[0] {
 		case QueryLatest:
 			return queryLatest(path[1:], keeper)
+		case QueryGet:
+			return queryGet(path[1:], keeper)
 		default:
 			return nil, sdk.ErrUnknownRequest("unknown batch query endpoint")
 		}
@@ -46,3 +51,29 @@ func queryLatest(path []string, keeper Keeper) ([]byte, sdk.Error) {
 	}
 	return b, nil
 }
+
+func queryGet(path []string, keeper Keeper) ([]byte, sdk.Error) {
+	if len(path) != 2 {
+		return nil, errs.ErrInvalidArgument("must specify a market ID and block number")
+	}
+
+	marketID := store.NewEntityIDFromString(path[0])
+	blockNumber, err := strconv.ParseInt(path[1], 10, 64)
+	if err != nil {
+		return nil, errs.ErrInvalidArgument("invalid block number")
+	}
+	res, sdkErr := keeper.Get(marketID, blockNumber)
+	if sdkErr != nil {
+		if sdkErr.Code() == errs.CodeNotFound {
+			return nil, nil
+		}
+
+		return nil, sdkErr
+	}
+
+	b, err := codec.MarshalJSONIndent(keeper.cdc, res)
+	if err != nil {
+		return nil, errs.ErrMarshalFailure("failed to marshal batch")
+	}
+	return b, nil
+}
@@ -15,6 +15,7 @@ import (
 
 func RegisterRoutes(ctx context.CLIContext, r *mux.Router, cdc *codec.Codec) {
 	r.Handle("/markets/{marketID}/batches", latestBatch(ctx, cdc)).Methods("GET")
+	r.Handle("/markets/{marketID}/batches/{blockNumber}", getBatch(ctx, cdc)).Methods("GET")
 }
 
 func latestBatch(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
@@ -40,3 +41,23 @@ func latestBatch(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
 		embedded.PostProcessResponse(w, ctx, res)
 	}
 }
+
+func getBatch(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		mktId := vars["marketID"]
+		blockNumber := vars["blockNumber"]
+
+		res, height, err := ctx.QueryWithData(fmt.Sprintf("custom/batch/get/%s/%s", mktId, blockNumber), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if res == nil {
+			w.WriteHeader(404)
+			return
+		}
+		ctx = ctx.WithHeight(height)
+		embedded.PostProcessResponse(w, ctx, res)
+	}
+}
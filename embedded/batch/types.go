@@ -9,6 +9,10 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+// Batch is the record of a market's batch auction in a block. Besides the
+// aggregates it holds everything needed to audit the auction: the orders it
+// was matched with, the full match table, the supply and demand the clearing
+// price was derived from, the pro-rata factor and the resulting fills.
 type Batch struct {
 	BlockNumber   int64                     `json:"block_number"`
 	BlockTime     time.Time                 `json:"block_time"`
@@ -16,4 +20,26 @@ type Batch struct {
 	ClearingPrice sdk.Uint                  `json:"clearing_price"`
 	Bids          []matcheng.AggregatePrice `json:"bids"`
 	Asks          []matcheng.AggregatePrice `json:"asks"`
+	MatchTable    []matcheng.MatchEntry     `json:"match_table"`
+	AggSupply     sdk.Uint                  `json:"agg_supply"`
+	AggDemand     sdk.Uint                  `json:"agg_demand"`
+	ProRata       sdk.Dec                   `json:"pro_rata"`
+	BidOrders     []matcheng.Order          `json:"bid_orders"`
+	AskOrders     []matcheng.Order          `json:"ask_orders"`
+	Fills         []matcheng.Fill           `json:"fills"`
+}
+
+// Verify re-runs the auction on the orders it was matched with and describes
+// every way in which the outcome differs from the record. It returns nil when
+// the record is reproduced exactly.
+func (b Batch) Verify() []string {
+	recorded := &matcheng.MatchResults{
+		ClearingPrice: b.ClearingPrice,
+		Fills:         b.Fills,
+		MatchTable:    b.MatchTable,
+		AggSupply:     b.AggSupply,
+		AggDemand:     b.AggDemand,
+		ProRata:       b.ProRata,
+	}
+	return matcheng.DiffResults(recorded, matcheng.Replay(b.BidOrders, b.AskOrders))
 }
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+
+	"github.com/xar-network/xar-network/embedded/book"
+	"github.com/xar-network/xar-network/x/order/types"
+)
+
+func GetCmdPlot(cdc *codec.Codec) *cobra.Command {
+	var format string
+	plotCmd := &cobra.Command{
+		Use:   "plot [market id]",
+		Short: "plot the supply and demand curves of a market's order book",
+		Long: `Fetches the open orders of a market at the latest block, or the one given by
+--height, and prints the cumulative supply and demand curves as gnuplot data,
+CSV or an SVG image. The gnuplot data can be plotted with:
+
+xarcli query book plot 1 > book.dat
+gnuplot -p -e "plot 'book.dat' index 0 with lines title columnheader(1), '' index 1 with lines title columnheader(1)"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCLIContext().WithCodec(cdc)
+			res, _, err := ctx.QueryWithData(fmt.Sprintf("custom/%s/book/%s", types.RouterKey, args[0]), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.BookQueryResult
+			cdc.MustUnmarshalJSON(res, &out)
+			plot, _, err := book.Plot(out, format)
+			if err != nil {
+				return err
+			}
+			fmt.Print(plot)
+			return nil
+		},
+	}
+	plotCmd.Flags().StringVar(&format, "format", book.FormatGnuplot, "output format: gnuplot, csv or svg")
+	return plotCmd
+}
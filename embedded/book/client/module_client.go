@@ -0,0 +1,31 @@
+package client
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/tendermint/go-amino"
+
+	"github.com/cosmos/cosmos-sdk/client"
+
+	"github.com/xar-network/xar-network/embedded/book/client/cli"
+)
+
+type ModuleClient struct {
+	cdc *amino.Codec
+}
+
+func NewModuleClient(cdc *amino.Codec) ModuleClient {
+	return ModuleClient{
+		cdc: cdc,
+	}
+}
+
+func (mc ModuleClient) GetQueryCmd() *cobra.Command {
+	bookQueryCmd := &cobra.Command{
+		Use:   "book",
+		Short: "queries order book data",
+	}
+	bookQueryCmd.AddCommand(client.GetCommands(
+		cli.GetCmdPlot(mc.cdc),
+	)...)
+	return bookQueryCmd
+}
@@ -0,0 +1,30 @@
+package book
+
+import (
+	"fmt"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/x/order/types"
+)
+
+const (
+	FormatGnuplot = "gnuplot"
+	FormatCSV     = "csv"
+	FormatSVG     = "svg"
+)
+
+// Plot renders the supply and demand curves of a book in the given format. It
+// returns the rendered plot and its content type.
+func Plot(book types.BookQueryResult, format string) (string, string, error) {
+	bids, asks := book.Aggregates()
+	switch format {
+	case FormatGnuplot:
+		return matcheng.PlotCurves(bids, asks), "text/plain", nil
+	case FormatCSV:
+		return matcheng.PlotCSV(bids, asks), "text/csv", nil
+	case FormatSVG:
+		return matcheng.PlotSVG(bids, asks), "image/svg+xml", nil
+	default:
+		return "", "", fmt.Errorf("unknown plot format %s", format)
+	}
+}
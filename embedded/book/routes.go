@@ -17,10 +17,12 @@ import (
 	"github.com/xar-network/xar-network/embedded/order"
 	"github.com/xar-network/xar-network/pkg/matcheng"
 	"github.com/xar-network/xar-network/types/store"
+	"github.com/xar-network/xar-network/x/order/types"
 )
 
 func RegisterRoutes(ctx context.CLIContext, r *mux.Router, cdc *codec.Codec) {
 	r.Handle("/markets/{marketID}/book", bookHandler(ctx, cdc)).Methods("GET")
+	r.Handle("/markets/{marketID}/book/plot", plotHandler(ctx, cdc)).Methods("GET")
 }
 
 func bookHandler(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
@@ -96,3 +98,41 @@ func bookHandler(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
 		embedded.PostProcessResponse(w, ctx, qRes)
 	}
 }
+
+// plotHandler renders the book of a market at the requested height, or the
+// latest one, as gnuplot data, CSV or SVG depending on the format parameter.
+func plotHandler(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		mktId := vars["marketID"]
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = FormatGnuplot
+		}
+
+		ctx, ok := rest.ParseQueryHeightOrReturnBadRequest(w, ctx, r)
+		if !ok {
+			return
+		}
+
+		resJSON, _, err := ctx.QueryWithData(fmt.Sprintf("custom/%s/book/%s", types.RouterKey, mktId), nil)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var res types.BookQueryResult
+		if err := cdc.UnmarshalJSON(resJSON, &res); err != nil {
+			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		out, contentType, err := Plot(res, format)
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write([]byte(out))
+	}
+}
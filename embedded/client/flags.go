@@ -0,0 +1,121 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/tendermint/tendermint/libs/cli"
+
+	"github.com/xar-network/xar-network/embedded/auth"
+	"github.com/xar-network/xar-network/embedded/session"
+	"github.com/xar-network/xar-network/embedded/stream"
+)
+
+const (
+	FlagSessionKeyFile = "session-key-file"
+	FlagAPIKeyFile     = "api-key-file"
+	FlagAPIKeyCryptKey = "api-key-encryption-key-file"
+	FlagSessionTTL     = "session-ttl"
+	FlagCookieSecure   = "cookie-secure"
+	FlagCookieHTTPOnly = "cookie-http-only"
+	FlagCookieSameSite = "cookie-same-site"
+	FlagAllowedOrigins = "allowed-origins"
+
+	defaultSessionKeyFile = "config/session_keys"
+	defaultAPIKeyFile     = "config/api_keys.json"
+	defaultAPIKeyCryptKey = "config/api_key_encryption_key"
+)
+
+var sameSiteModes = map[string]http.SameSite{
+	"default": http.SameSiteDefaultMode,
+	"lax":     http.SameSiteLaxMode,
+	"strict":  http.SameSiteStrictMode,
+	"none":    http.SameSiteNoneMode,
+}
+
+// AddAuthFlags adds the flags configuring the sessions and API keys of the
+// embedded API to a command.
+func AddAuthFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String(FlagSessionKeyFile, "", fmt.Sprintf("file holding the session keys, generated if missing (default <home>/%s)", defaultSessionKeyFile))
+	cmd.Flags().Duration(FlagSessionTTL, auth.DefaultSessionTTL, "how long logins stay valid")
+	cmd.Flags().Bool(FlagCookieSecure, false, "only send session cookies over HTTPS")
+	cmd.Flags().Bool(FlagCookieHTTPOnly, true, "hide session cookies from scripts")
+	cmd.Flags().String(FlagCookieSameSite, "lax", "SameSite mode of session cookies: default, lax, strict or none")
+	cmd.Flags().String(FlagAPIKeyFile, "", fmt.Sprintf("file holding the API keys (default <home>/%s)", defaultAPIKeyFile))
+	cmd.Flags().String(FlagAPIKeyCryptKey, "", fmt.Sprintf("file holding the key that encrypts the private keys of API keys, generated if missing (default <home>/%s)", defaultAPIKeyCryptKey))
+	cmd.Flags().StringSlice(FlagAllowedOrigins, nil, "origins besides the server's own allowed to open the WebSocket, e.g. https://app.example.com")
+	return cmd
+}
+
+// InitAuth configures the sessions and API keys of the embedded API from the
+// flags added by AddAuthFlags.
+func InitAuth() error {
+	sameSite, ok := sameSiteModes[viper.GetString(FlagCookieSameSite)]
+	if !ok {
+		return fmt.Errorf("invalid %s: %s", FlagCookieSameSite, viper.GetString(FlagCookieSameSite))
+	}
+	ttl := viper.GetDuration(FlagSessionTTL)
+	if ttl <= 0 {
+		return fmt.Errorf("%s must be positive", FlagSessionTTL)
+	}
+
+	auth.SetSessionTTL(ttl)
+	stream.SetAllowedOrigins(viper.GetStringSlice(FlagAllowedOrigins))
+	err := session.Init(session.Config{
+		KeyFile:  SessionKeyFile(),
+		MaxAge:   int(ttl.Seconds()),
+		Secure:   viper.GetBool(FlagCookieSecure),
+		HTTPOnly: viper.GetBool(FlagCookieHTTPOnly),
+		SameSite: sameSite,
+	})
+	if err != nil {
+		return err
+	}
+
+	return auth.LoadAPIKeys(homeFile(FlagAPIKeyFile, defaultAPIKeyFile), homeFile(FlagAPIKeyCryptKey, defaultAPIKeyCryptKey))
+}
+
+// homeFile returns the path given by a flag, or the default path under the
+// home directory.
+func homeFile(flag string, defaultPath string) string {
+	if path := viper.GetString(flag); path != "" {
+		return path
+	}
+	return filepath.Join(viper.GetString(cli.HomeFlag), defaultPath)
+}
+
+// SessionKeyFile returns the configured session key file.
+func SessionKeyFile() string {
+	return homeFile(FlagSessionKeyFile, defaultSessionKeyFile)
+}
+
+const flagKeep = "keep"
+
+// RotateSessionKeysCmd adds a new session key. Cookies signed with the
+// previous keys stay valid until they are dropped by later rotations, and a
+// running REST server picks up the new key when restarted.
+func RotateSessionKeysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-session-keys",
+		Short: "Add a new key for signing embedded API sessions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keep := viper.GetInt(flagKeep)
+			if keep < 0 {
+				return fmt.Errorf("%s cannot be negative", flagKeep)
+			}
+			path := SessionKeyFile()
+			if _, err := session.RotateKeyFile(path, keep); err != nil {
+				return err
+			}
+			fmt.Printf("Rotated session keys in %s\n", path)
+			return nil
+		},
+	}
+	cmd.Flags().String(FlagSessionKeyFile, "", fmt.Sprintf("file holding the session keys (default <home>/%s)", defaultSessionKeyFile))
+	cmd.Flags().Int(flagKeep, 1, "number of previous keys to keep accepting")
+	return cmd
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/xar-network/xar-network/embedded/market"
 	"github.com/xar-network/xar-network/embedded/order"
 	"github.com/xar-network/xar-network/embedded/price"
+	"github.com/xar-network/xar-network/embedded/stream"
 )
 
 func RegisterRoutes(ctx context.CLIContext, r *mux.Router, cdc *codec.Codec, enableFaucet bool) {
@@ -32,5 +33,6 @@ func RegisterRoutes(ctx context.CLIContext, r *mux.Router, cdc *codec.Codec, ena
 	price.RegisterRoutes(ctx, sub, cdc)
 	book.RegisterRoutes(ctx, sub, cdc)
 	batch.RegisterRoutes(ctx, sub, cdc)
+	stream.RegisterRoutes(ctx, sub, cdc)
 	//ui.RegisterRoutes(ctx, r, cdc)
 }
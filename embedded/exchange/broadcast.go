@@ -0,0 +1,166 @@
+package exchange
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkauth "github.com/cosmos/cosmos-sdk/x/auth"
+	"github.com/cosmos/cosmos-sdk/x/auth/client/utils"
+
+	"github.com/xar-network/xar-network/embedded/auth"
+	"github.com/xar-network/xar-network/types/store"
+	"github.com/xar-network/xar-network/x/order/types"
+)
+
+var validModes = map[string]bool{
+	flags.BroadcastBlock: true,
+	flags.BroadcastSync:  true,
+	flags.BroadcastAsync: true,
+}
+
+// seqExpiry is how long a locally tracked sequence is kept while the chain's
+// sequence does not advance. Transactions broadcast asynchronously can still
+// be rejected, leaving the local sequence ahead of anything the chain will
+// accept.
+const seqExpiry = 30 * time.Second
+
+// sequences tracks the next sequence of accounts with transactions that
+// have been broadcast but may not be in a block yet, since the sequence
+// stored on chain only advances once they are.
+type sequences struct {
+	mtx   sync.Mutex
+	locks map[string]*sync.Mutex
+	next  map[string]*localSeq
+}
+
+type localSeq struct {
+	next uint64
+	// the chain's sequence when it last advanced, and when that was seen
+	onChain uint64
+	since   time.Time
+}
+
+var accountSeqs = &sequences{
+	locks: make(map[string]*sync.Mutex),
+	next:  make(map[string]*localSeq),
+}
+
+// lock serializes the transactions of an account.
+func (s *sequences) lock(addr sdk.AccAddress) func() {
+	s.mtx.Lock()
+	l, ok := s.locks[addr.String()]
+	if !ok {
+		l = new(sync.Mutex)
+		s.locks[addr.String()] = l
+	}
+	s.mtx.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// get returns the sequence to sign the next transaction of an account with.
+// The local sequence is dropped once the chain has caught up with it, or if
+// the chain has not advanced for seqExpiry.
+func (s *sequences) get(addr sdk.AccAddress, onChain uint64, now time.Time) uint64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	local, ok := s.next[addr.String()]
+	if !ok {
+		return onChain
+	}
+	if onChain >= local.next {
+		delete(s.next, addr.String())
+		return onChain
+	}
+	if onChain > local.onChain {
+		local.onChain = onChain
+		local.since = now
+	} else if now.Sub(local.since) > seqExpiry {
+		delete(s.next, addr.String())
+		return onChain
+	}
+	return local.next
+}
+
+func (s *sequences) set(addr sdk.AccAddress, next uint64, onChain uint64, now time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	local, ok := s.next[addr.String()]
+	if !ok {
+		local = &localSeq{onChain: onChain, since: now}
+		s.next[addr.String()] = local
+	}
+	local.next = next
+}
+
+func (s *sequences) reset(addr sdk.AccAddress) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.next, addr.String())
+}
+
+// signAndBroadcast signs msgs with a hot keybase and broadcasts them in the
+// given mode. Transactions of an account can be broadcast back to back
+// without waiting for blocks, as sequences are tracked locally. An async
+// broadcast cannot tell whether the node accepted the transaction, so a
+// rejected one holds up the account's later transactions until its local
+// sequence expires.
+func signAndBroadcast(ctx context.CLIContext, cdc *codec.Codec, kb *auth.Keybase, passphrase string, msgs []sdk.Msg, mode string) (sdk.TxResponse, error) {
+	if !validModes[mode] {
+		return sdk.TxResponse{}, fmt.Errorf("invalid broadcast mode %s", mode)
+	}
+	owner := kb.GetAddr()
+	ctx = ctx.WithFromAddress(owner).WithBroadcastMode(mode)
+
+	unlock := accountSeqs.lock(owner)
+	defer unlock()
+
+	bldr := sdkauth.NewTxBuilderFromCLI(nil).
+		WithTxEncoder(utils.GetTxEncoder(cdc)).
+		WithKeybase(kb)
+	bldr, err := utils.PrepareTxBuilder(bldr, ctx)
+	if err != nil {
+		return sdk.TxResponse{}, err
+	}
+	onChain := bldr.Sequence()
+	seq := accountSeqs.get(owner, onChain, time.Now())
+	bldr = bldr.WithSequence(seq)
+
+	txB, err := bldr.BuildAndSign(kb.GetName(), passphrase, msgs)
+	if err != nil {
+		return sdk.TxResponse{}, err
+	}
+	res, err := ctx.BroadcastTx(txB)
+	if err != nil || res.Code != 0 {
+		// fall back to the chain's sequence in case ours is wrong
+		accountSeqs.reset(owner)
+		return res, err
+	}
+	accountSeqs.set(owner, seq+1, onChain, time.Now())
+	return res, nil
+}
+
+// orderIDsFromEvents returns the IDs of the orders named by events of a given
+// type in a transaction's logs.
+func orderIDsFromEvents(res sdk.TxResponse, eventType string) []store.EntityID {
+	ids := make([]store.EntityID, 0)
+	for _, log := range res.Logs {
+		for _, event := range log.Events {
+			if event.Type != eventType {
+				continue
+			}
+			for _, attr := range event.Attributes {
+				if attr.Key == types.AttributeKeyOrderID {
+					ids = append(ids, store.NewEntityIDFromString(attr.Value))
+				}
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,65 @@
+package exchange
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xar-network/xar-network/testutil"
+	"github.com/xar-network/xar-network/testutil/testflags"
+	"github.com/xar-network/xar-network/types/store"
+	"github.com/xar-network/xar-network/x/order/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestOrderIDsFromEvents(t *testing.T) {
+	testflags.UnitTest(t)
+	events := sdk.Events{
+		sdk.NewEvent(types.EventTypePostOrder, sdk.NewAttribute(types.AttributeKeyOrderID, "3"), sdk.NewAttribute(types.AttributeKeyMarketID, "1")),
+		sdk.NewEvent(types.EventTypeCancelOrder, sdk.NewAttribute(types.AttributeKeyOrderID, "1")),
+		sdk.NewEvent(types.EventTypePostOrder, sdk.NewAttribute(types.AttributeKeyOrderID, "4"), sdk.NewAttribute(types.AttributeKeyMarketID, "1")),
+	}
+	res := sdk.TxResponse{
+		Logs: sdk.ABCIMessageLogs{sdk.NewABCIMessageLog(0, true, "", events)},
+	}
+
+	assert.Equal(t, []store.EntityID{store.NewEntityID(3), store.NewEntityID(4)}, orderIDsFromEvents(res, types.EventTypePostOrder))
+	assert.Equal(t, []store.EntityID{store.NewEntityID(1)}, orderIDsFromEvents(res, types.EventTypeCancelOrder))
+	assert.Empty(t, orderIDsFromEvents(res, types.EventTypeAmendOrder))
+}
+
+func TestSequences(t *testing.T) {
+	testflags.UnitTest(t)
+	seqs := &sequences{
+		locks: make(map[string]*sync.Mutex),
+		next:  make(map[string]*localSeq),
+	}
+	addr := testutil.RandAddr()
+	start := time.Unix(1000, 0)
+
+	assert.EqualValues(t, 5, seqs.get(addr, 5, start))
+	seqs.set(addr, 7, 5, start)
+	assert.EqualValues(t, 7, seqs.get(addr, 5, start))
+	// the chain caught up
+	assert.EqualValues(t, 8, seqs.get(addr, 8, start))
+	seqs.set(addr, 9, 8, start)
+	seqs.reset(addr)
+	assert.EqualValues(t, 5, seqs.get(addr, 5, start))
+
+	t.Run("keeps the local sequence while the chain advances", func(t *testing.T) {
+		seqs.set(addr, 10, 5, start)
+		assert.EqualValues(t, 10, seqs.get(addr, 6, start.Add(seqExpiry)))
+		assert.EqualValues(t, 10, seqs.get(addr, 7, start.Add(2*seqExpiry)))
+		seqs.reset(addr)
+	})
+	t.Run("drops the local sequence once the chain stops advancing", func(t *testing.T) {
+		seqs.set(addr, 10, 5, start)
+		seqs.set(addr, 11, 5, start.Add(time.Second))
+		assert.EqualValues(t, 11, seqs.get(addr, 5, start.Add(seqExpiry)))
+		assert.EqualValues(t, 5, seqs.get(addr, 5, start.Add(seqExpiry+time.Second)))
+		assert.EqualValues(t, 5, seqs.get(addr, 5, start.Add(seqExpiry+time.Second)))
+	})
+}
@@ -1,7 +1,9 @@
 package exchange
 
 import (
+	"encoding/hex"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -12,19 +14,29 @@ import (
 	"github.com/xar-network/xar-network/x/order/types"
 
 	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/rest"
-	sdkauth "github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/auth/client/utils"
 )
 
 func RegisterRoutes(ctx context.CLIContext, r *mux.Router, cdc *codec.Codec) {
+	// registered ahead of the subrouter, as it only needs read access
+	r.Handle("/exchange/txs/{hash}", auth.DefaultAuthMW(txStatusHandler(ctx))).Methods("GET")
+
 	sub := r.PathPrefix("/exchange").Subrouter()
-	sub.Use(auth.DefaultAuthMW)
+	sub.Use(auth.TradeAuthMW)
 	sub.HandleFunc("/orders", postOrderHandler(ctx, cdc)).Methods("POST")
+	sub.HandleFunc("/orders", cancelAllOrdersHandler(ctx, cdc)).Methods("DELETE")
+	sub.HandleFunc("/orders/{id}", cancelOrderHandler(ctx, cdc)).Methods("DELETE")
+	sub.HandleFunc("/orders/{id}", amendOrderHandler(ctx, cdc)).Methods("PATCH")
 }
 
+// postOrderHandler posts an order. The mode parameter picks how the
+// transaction is broadcast: block waits for it to be committed, while sync
+// and async return as soon as the node has checked or received it, leaving
+// the order pending until its status is polled.
 func postOrderHandler(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req OrderCreationRequest
@@ -32,71 +44,239 @@ func postOrderHandler(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc
 			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
 			return
 		}
+		mode, ok := readBroadcastMode(w, r)
+		if !ok {
+			return
+		}
 
 		kb := auth.MustGetKBFromSession(r)
-		owner := kb.GetAddr()
-		ctx = ctx.WithFromAddress(owner)
+		msg := types.NewMsgPostWithType(kb.GetAddr(), req.MarketID, req.Type, req.Direction, req.Price, req.Quantity, req.MaxSpend, req.TimeInForce)
+		broadcastRes, ok := broadcastMsg(w, r, ctx, cdc, msg, mode)
+		if !ok {
+			return
+		}
 
-		msg := types.NewMsgPost(owner, req.MarketID, req.Direction, req.Price, req.Quantity, req.TimeInForce)
-		msgs := []sdk.Msg{msg}
-		err := msg.ValidateBasic()
-		if err != nil {
-			rest.WriteErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		res := OrderCreationResponse{
+			BlockInclusion: blockInclusion(broadcastRes),
+			MarketID:       msg.MarketID,
+			Direction:      msg.Direction,
+			Price:          msg.Price,
+			Quantity:       msg.Quantity,
+			Type:           msg.OrderType,
+			TimeInForce:    msg.TimeInForce,
+			Status:         TxStatusPending,
+		}
+		if mode == flags.BroadcastBlock {
+			res.Status = "OPEN"
+			if ids := orderIDsFromEvents(broadcastRes, types.EventTypePostOrder); len(ids) > 0 {
+				res.ID = &ids[0]
+			}
+		}
+		writeJSON(w, cdc, res)
+	}
+}
+
+// cancelOrderHandler cancels a single order.
+func cancelOrderHandler(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := readOrderID(w, r)
+		if !ok {
+			return
+		}
+		mode, ok := readBroadcastMode(w, r)
+		if !ok {
 			return
 		}
 
-		bldr := sdkauth.NewTxBuilderFromCLI(nil).
-			WithTxEncoder(utils.GetTxEncoder(cdc)).
-			WithKeybase(kb)
+		kb := auth.MustGetKBFromSession(r)
+		msg := types.NewMsgCancel(kb.GetAddr(), id)
+		broadcastRes, ok := broadcastMsg(w, r, ctx, cdc, msg, mode)
+		if !ok {
+			return
+		}
+		writeJSON(w, cdc, cancellationResponse(broadcastRes, mode))
+	}
+}
 
-		bldr, sdkErr := utils.PrepareTxBuilder(bldr, ctx)
-		if sdkErr != nil {
-			rest.WriteErrorResponse(w, http.StatusInternalServerError, sdkErr.Error())
+// cancelAllOrdersHandler cancels every open order the user has in the market
+// given by the market_id parameter, or in every market if it is left out.
+func cancelAllOrdersHandler(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		mktID := store.NewEntityID(0)
+		if param := r.URL.Query().Get("market_id"); param != "" {
+			n, err := strconv.ParseUint(param, 10, 64)
+			if err != nil || n == 0 {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, "invalid market ID")
+				return
+			}
+			mktID = store.NewEntityID(n)
+		}
+		mode, ok := readBroadcastMode(w, r)
+		if !ok {
 			return
 		}
 
-		broadcastResB, sdkErr := bldr.BuildAndSign(kb.GetName(), auth.MustGetKBPassphraseFromSession(r), msgs)
-		if sdkErr != nil {
-			rest.WriteErrorResponse(w, http.StatusInternalServerError, sdkErr.Error())
+		kb := auth.MustGetKBFromSession(r)
+		msg := types.NewMsgCancelAll(kb.GetAddr(), mktID)
+		broadcastRes, ok := broadcastMsg(w, r, ctx, cdc, msg, mode)
+		if !ok {
 			return
 		}
-		broadcastRes, sdkErr := ctx.BroadcastTxCommit(broadcastResB)
-		if sdkErr != nil {
-			rest.WriteErrorResponse(w, http.StatusInternalServerError, sdkErr.Error())
+		writeJSON(w, cdc, cancellationResponse(broadcastRes, mode))
+	}
+}
+
+// amendOrderHandler changes the price and quantity of an open order.
+func amendOrderHandler(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := readOrderID(w, r)
+		if !ok {
+			return
+		}
+		var req OrderAmendmentRequest
+		if !rest.ReadRESTReq(w, r, cdc, &req) {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "failed to parse request")
+			return
+		}
+		mode, ok := readBroadcastMode(w, r)
+		if !ok {
 			return
 		}
 
-		var orderIDStr string
-		for _, log := range broadcastRes.Logs {
-			if strings.HasPrefix(log.Log, "order_id") {
-				orderIDStr = strings.TrimPrefix(log.Log, "order_id:")
-				break
-			}
+		kb := auth.MustGetKBFromSession(r)
+		msg := types.NewMsgAmend(kb.GetAddr(), id, req.Price, req.Quantity)
+		broadcastRes, ok := broadcastMsg(w, r, ctx, cdc, msg, mode)
+		if !ok {
+			return
 		}
-		orderID := store.NewEntityIDFromString(orderIDStr)
-		res := OrderCreationResponse{
-			BlockInclusion: embedded.BlockInclusion{
-				BlockNumber:     broadcastRes.Height,
-				TransactionHash: broadcastRes.TxHash,
-				BlockTimestamp:  broadcastRes.Timestamp,
-			},
-			ID:          orderID,
-			MarketID:    msg.MarketID,
-			Direction:   msg.Direction,
-			Price:       msg.Price,
-			Quantity:    msg.Quantity,
-			Type:        req.Type,
-			TimeInForce: msg.TimeInForce,
-			Status:      "OPEN",
-		}
-
-		out, sdkErr := cdc.MarshalJSON(res)
-		if sdkErr != nil {
-			rest.WriteErrorResponse(w, http.StatusInternalServerError, sdkErr.Error())
+
+		res := OrderAmendmentResponse{
+			BlockInclusion: blockInclusion(broadcastRes),
+			ID:             id,
+			Price:          msg.Price,
+			Quantity:       msg.Quantity,
+			Status:         TxStatusPending,
+		}
+		if mode == flags.BroadcastBlock {
+			res.Status = "AMENDED"
+		}
+		writeJSON(w, cdc, res)
+	}
+}
+
+func readOrderID(w http.ResponseWriter, r *http.Request) (store.EntityID, bool) {
+	n, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil || n == 0 {
+		rest.WriteErrorResponse(w, http.StatusBadRequest, "invalid order ID")
+		return store.EntityID{}, false
+	}
+	return store.NewEntityID(n), true
+}
+
+func readBroadcastMode(w http.ResponseWriter, r *http.Request) (string, bool) {
+	mode := r.URL.Query().Get("mode")
+	if mode == "" {
+		mode = flags.BroadcastBlock
+	}
+	if !validModes[mode] {
+		rest.WriteErrorResponse(w, http.StatusBadRequest, "invalid broadcast mode")
+		return "", false
+	}
+	return mode, true
+}
+
+// broadcastMsg validates msg, then signs it with the session's key and
+// broadcasts it. Errors are written to w, in which case it returns false.
+func broadcastMsg(w http.ResponseWriter, r *http.Request, ctx context.CLIContext, cdc *codec.Codec, msg sdk.Msg, mode string) (sdk.TxResponse, bool) {
+	if err := msg.ValidateBasic(); err != nil {
+		rest.WriteErrorResponse(w, http.StatusUnprocessableEntity, err.Error())
+		return sdk.TxResponse{}, false
+	}
+
+	kb := auth.MustGetKBFromSession(r)
+	res, err := signAndBroadcast(ctx, cdc, kb, auth.MustGetKBPassphraseFromSession(r), []sdk.Msg{msg}, mode)
+	if err != nil {
+		rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return res, false
+	}
+	if res.Code != 0 {
+		rest.WriteErrorResponse(w, http.StatusUnprocessableEntity, res.RawLog)
+		return res, false
+	}
+	return res, true
+}
+
+func blockInclusion(res sdk.TxResponse) embedded.BlockInclusion {
+	return embedded.BlockInclusion{
+		BlockNumber:     res.Height,
+		TransactionHash: res.TxHash,
+		BlockTimestamp:  res.Timestamp,
+	}
+}
+
+func cancellationResponse(broadcastRes sdk.TxResponse, mode string) OrderCancellationResponse {
+	res := OrderCancellationResponse{
+		BlockInclusion: blockInclusion(broadcastRes),
+		Status:         TxStatusPending,
+	}
+	if mode == flags.BroadcastBlock {
+		res.Status = "CANCELLED"
+		res.IDs = orderIDsFromEvents(broadcastRes, types.EventTypeCancelOrder)
+	}
+	return res
+}
+
+func writeJSON(w http.ResponseWriter, cdc *codec.Codec, res interface{}) {
+	out, err := cdc.MarshalJSON(res)
+	if err != nil {
+		rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if _, err := w.Write(out); err != nil {
+		rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// txStatusHandler reports whether a transaction has been committed, and the
+// orders it posted, amended or cancelled if so.
+func txStatusHandler(ctx context.CLIContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		hash := mux.Vars(r)["hash"]
+		if _, err := hex.DecodeString(hash); err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "invalid transaction hash")
 			return
 		}
-		if _, err := w.Write(out); err != nil {
-			rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+
+		res := TxStatusResponse{
+			TransactionHash: strings.ToUpper(hash),
+			Status:          TxStatusPending,
+		}
+		txRes, err := utils.QueryTx(ctx, hash)
+		if err != nil {
+			// the node only indexes committed transactions
+			if !strings.Contains(err.Error(), "not found") {
+				rest.WriteErrorResponse(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			embedded.PostProcessResponse(w, ctx, res)
+			return
+		}
+
+		res.BlockInclusion = &embedded.BlockInclusion{
+			BlockNumber:     txRes.Height,
+			TransactionHash: txRes.TxHash,
+			BlockTimestamp:  txRes.Timestamp,
+		}
+		res.Code = txRes.Code
+		if txRes.Code != 0 {
+			res.Status = TxStatusFailed
+			res.Log = txRes.RawLog
+		} else {
+			res.Status = TxStatusCommitted
+			res.PostedOrderIDs = orderIDsFromEvents(txRes, types.EventTypePostOrder)
+			res.AmendedOrderIDs = orderIDsFromEvents(txRes, types.EventTypeAmendOrder)
+			res.CancelledOrderIDs = orderIDsFromEvents(txRes, types.EventTypeCancelOrder)
 		}
+		embedded.PostProcessResponse(w, ctx, res)
 	}
 }
@@ -0,0 +1,47 @@
+package exchange
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xar-network/xar-network/testutil/testflags"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+)
+
+func TestOrderRoutes_InvalidParams(t *testing.T) {
+	testflags.UnitTest(t)
+	r := mux.NewRouter()
+	// handlers are mounted without auth, as the requests below are
+	// rejected before the session is read
+	ctx := context.CLIContext{}
+	cdc := codec.New()
+	r.HandleFunc("/exchange/orders", cancelAllOrdersHandler(ctx, cdc)).Methods("DELETE")
+	r.HandleFunc("/exchange/orders/{id}", cancelOrderHandler(ctx, cdc)).Methods("DELETE")
+	r.HandleFunc("/exchange/orders/{id}", amendOrderHandler(ctx, cdc)).Methods("PATCH")
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+	}{
+		{"cancel non-numeric ID", "DELETE", "/exchange/orders/abc"},
+		{"cancel zero ID", "DELETE", "/exchange/orders/0"},
+		{"cancel invalid mode", "DELETE", "/exchange/orders/1?mode=foo"},
+		{"cancel all invalid market", "DELETE", "/exchange/orders?market_id=-1"},
+		{"cancel all invalid mode", "DELETE", "/exchange/orders?mode=foo"},
+		{"amend non-numeric ID", "PATCH", "/exchange/orders/abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -13,18 +13,64 @@ type OrderCreationRequest struct {
 	Direction   matcheng.Direction `json:"direction"`
 	Price       sdk.Uint           `json:"price"`
 	Quantity    sdk.Uint           `json:"quantity"`
-	Type        string             `json:"type"`
+	Type        matcheng.OrderType `json:"type"`
+	MaxSpend    sdk.Uint           `json:"max_spend"`
 	TimeInForce uint16             `json:"time_in_force"`
 }
 
+const (
+	TxStatusPending   = "PENDING"
+	TxStatusCommitted = "COMMITTED"
+	TxStatusFailed    = "FAILED"
+)
+
+// OrderCreationResponse describes a posted order. The ID is only known once
+// the order's transaction is in a block, so it is left out when the order
+// was broadcast without waiting for one.
 type OrderCreationResponse struct {
 	BlockInclusion embedded.BlockInclusion `json:"block_inclusion"`
-	ID             store.EntityID          `json:"id"`
+	ID             *store.EntityID         `json:"id,omitempty"`
 	MarketID       store.EntityID          `json:"market_id"`
 	Direction      matcheng.Direction      `json:"direction"`
 	Price          sdk.Uint                `json:"price"`
 	Quantity       sdk.Uint                `json:"quantity"`
-	Type           string                  `json:"type"`
+	Type           matcheng.OrderType      `json:"type"`
 	TimeInForce    uint16                  `json:"time_in_force"`
 	Status         string                  `json:"status"`
 }
+
+// TxStatusResponse describes a transaction broadcast by the exchange API.
+// Transactions that are not in a block yet are reported as pending.
+type TxStatusResponse struct {
+	TransactionHash   string                   `json:"transaction_hash"`
+	Status            string                   `json:"status"`
+	BlockInclusion    *embedded.BlockInclusion `json:"block_inclusion,omitempty"`
+	Code              uint32                   `json:"code,omitempty"`
+	Log               string                   `json:"log,omitempty"`
+	PostedOrderIDs    []store.EntityID         `json:"posted_order_ids,omitempty"`
+	AmendedOrderIDs   []store.EntityID         `json:"amended_order_ids,omitempty"`
+	CancelledOrderIDs []store.EntityID         `json:"cancelled_order_ids,omitempty"`
+}
+
+type OrderAmendmentRequest struct {
+	Price    sdk.Uint `json:"price"`
+	Quantity sdk.Uint `json:"quantity"`
+}
+
+// OrderAmendmentResponse describes an amended order. The order keeps its ID,
+// so unlike a posted order it is known before the transaction is committed.
+type OrderAmendmentResponse struct {
+	BlockInclusion embedded.BlockInclusion `json:"block_inclusion"`
+	ID             store.EntityID          `json:"id"`
+	Price          sdk.Uint                `json:"price"`
+	Quantity       sdk.Uint                `json:"quantity"`
+	Status         string                  `json:"status"`
+}
+
+// OrderCancellationResponse lists the orders a cancellation removed. As with
+// posted orders, the IDs are only known once the transaction is in a block.
+type OrderCancellationResponse struct {
+	BlockInclusion embedded.BlockInclusion `json:"block_inclusion"`
+	IDs            []store.EntityID        `json:"ids,omitempty"`
+	Status         string                  `json:"status"`
+}
@@ -1,45 +1,80 @@
 package fill
 
 import (
+	"encoding/binary"
+	"math"
+
 	dbm "github.com/tendermint/tm-db"
 
 	"github.com/xar-network/xar-network/types"
 	"github.com/xar-network/xar-network/types/store"
 
 	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 const (
-	TableKey = "fill"
+	TableKey      = "fill"
+	OwnerTableKey = "owned_fill"
+
+	indexVersionKey = "idxver"
+
+	// indexVersion is the version of the owner index. Fills stored before
+	// the index existed are indexed by MigrateOwnerIndex.
+	indexVersion uint64 = 1
 )
 
 type IteratorCB func(fill Fill) bool
 
 type Keeper struct {
-	as  store.ArchiveStore
-	cdc *codec.Codec
+	as     store.ArchiveStore
+	owners store.ArchiveStore
+	cdc    *codec.Codec
 }
 
 func NewKeeper(db dbm.DB, cdc *codec.Codec) Keeper {
 	return Keeper{
-		as:  store.NewTable(db, TableKey),
-		cdc: cdc,
+		as:     store.NewTable(db, TableKey),
+		owners: store.NewTable(db, OwnerTableKey),
+		cdc:    cdc,
 	}
 }
 
 func (k Keeper) OnFillEvent(event types.Fill) {
 	fill := Fill{
 		OrderID:     event.OrderID,
+		MarketID:    event.MarketID,
 		Owner:       event.Owner,
 		Pair:        event.Pair,
 		Direction:   event.Direction,
 		QtyFilled:   event.QtyFilled,
 		QtyUnfilled: event.QtyUnfilled,
 		BlockNumber: event.BlockNumber,
+		BlockTime:   event.BlockTime,
 		Price:       event.Price,
+		QuoteQty:    event.QuoteQty,
+		Fee:         event.Fee,
+		FeeDenom:    event.FeeDenom,
 	}
 	storedB := k.cdc.MustMarshalBinaryBare(fill)
-	k.as.Set(fillKey(event.BlockNumber, event.OrderID), storedB)
+	key := fillKey(event.BlockNumber, k.nextSeq(event.BlockNumber))
+	k.as.Set(key, storedB)
+	k.owners.Set(ownerFillKey(event.Owner, key), key)
+}
+
+// nextSeq returns the sequence of the next fill stored in a block. An order
+// can fill several times in a block, so fills are keyed by the order they
+// arrive in rather than by order ID.
+func (k Keeper) nextSeq(blockNum int64) uint64 {
+	var seq uint64
+	k.as.ReversePrefixIterator(fillIterKey(blockNum), func(key []byte, _ []byte) bool {
+		// fills stored before sequences were used are keyed by order ID
+		if last := key[len(fillIterKey(blockNum))+1:]; len(last) == 8 {
+			seq = binary.BigEndian.Uint64(last) + 1
+		}
+		return false
+	})
+	return seq
 }
 
 func (k Keeper) IterOverBlockNumbers(start int64, end int64, cb IteratorCB) {
@@ -50,6 +85,39 @@ func (k Keeper) IterOverBlockNumbers(start int64, end int64, cb IteratorCB) {
 	})
 }
 
+// IterOverOwner iterates over the fills of an owner between the start and
+// end blocks, oldest first.
+func (k Keeper) IterOverOwner(owner sdk.AccAddress, start int64, end int64, cb IteratorCB) {
+	k.owners.Iterator(ownerFillIterKey(owner, start), ownerFillIterKey(owner, end), func(_ []byte, v []byte) bool {
+		var fill Fill
+		k.cdc.MustUnmarshalBinaryBare(k.as.Get(v), &fill)
+		return cb(fill)
+	})
+}
+
+// MigrateOwnerIndex indexes every stored fill by owner if the fills were
+// written before the owner index existed. Those fills would otherwise never
+// be found by owner. It returns the number of fills that were indexed.
+func (k Keeper) MigrateOwnerIndex() int {
+	if ver := k.owners.Get([]byte(indexVersionKey)); ver != nil && binary.BigEndian.Uint64(ver) >= indexVersion {
+		return 0
+	}
+
+	var count int
+	k.as.Iterator(fillIterKey(0), fillIterKey(math.MaxInt64), func(key []byte, v []byte) bool {
+		var fill Fill
+		k.cdc.MustUnmarshalBinaryBare(v, &fill)
+		k.owners.Set(ownerFillKey(fill.Owner, key), key)
+		count++
+		return true
+	})
+
+	var ver [8]byte
+	binary.BigEndian.PutUint64(ver[:], indexVersion)
+	k.owners.Set([]byte(indexVersionKey), ver[:])
+	return count
+}
+
 func (k Keeper) OnEvent(event interface{}) error {
 	switch ev := event.(type) {
 	case types.Fill:
@@ -63,6 +131,16 @@ func fillIterKey(blockNum int64) []byte {
 	return store.PrefixKeyBytes(store.Int64Subkey(blockNum))
 }
 
-func fillKey(blockNum int64, orderId store.EntityID) []byte {
-	return store.PrefixKeyBytes(fillIterKey(blockNum), orderId.Bytes())
+func fillKey(blockNum int64, seq uint64) []byte {
+	return store.PrefixKeyBytes(fillIterKey(blockNum), store.Uint64Subkey(seq))
+}
+
+func ownerFillIterKey(owner sdk.AccAddress, blockNum int64) []byte {
+	return store.PrefixKeyBytes(owner.Bytes(), fillIterKey(blockNum))
+}
+
+// ownerFillKey indexes the fill stored under key. key starts with the block
+// number, so an owner's fills are ordered by block.
+func ownerFillKey(owner sdk.AccAddress, key []byte) []byte {
+	return store.PrefixKeyBytes(owner.Bytes(), key)
 }
@@ -0,0 +1,200 @@
+package fill
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/testutil"
+	"github.com/xar-network/xar-network/testutil/testflags"
+	"github.com/xar-network/xar-network/types"
+	"github.com/xar-network/xar-network/types/store"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestKeeper(t *testing.T) {
+	testflags.UnitTest(t)
+	k := NewKeeper(dbm.NewMemDB(), codec.New())
+	owner := testutil.RandAddr()
+	other := testutil.RandAddr()
+
+	events := []types.Fill{
+		testFill(owner, 1, 1, matcheng.Bid, 1, 10, 100, 1),
+		testFill(other, 2, 1, matcheng.Ask, 1, 10, 100, 10),
+		testFill(owner, 3, 1, matcheng.Bid, 2, 10, 200, 1),
+		testFill(owner, 4, 1, matcheng.Ask, 3, 5, 300, 15),
+		testFill(owner, 5, 2, matcheng.Ask, 3, 5, 10, 0),
+	}
+	for _, ev := range events {
+		require.NoError(t, k.OnEvent(ev))
+	}
+
+	t.Run("iterates over the fills of an owner", func(t *testing.T) {
+		var ids []string
+		k.IterOverOwner(owner, 0, math.MaxInt64, func(fill Fill) bool {
+			ids = append(ids, fill.OrderID.String())
+			return true
+		})
+		assert.Equal(t, []string{"1", "3", "4", "5"}, ids)
+
+		ids = nil
+		k.IterOverOwner(other, 0, math.MaxInt64, func(fill Fill) bool {
+			ids = append(ids, fill.OrderID.String())
+			return true
+		})
+		assert.Equal(t, []string{"2"}, ids)
+
+		ids = nil
+		k.IterOverOwner(owner, 2, 3, func(fill Fill) bool {
+			ids = append(ids, fill.OrderID.String())
+			return true
+		})
+		assert.Equal(t, []string{"3"}, ids)
+	})
+
+	t.Run("reports realized PnL against the average cost", func(t *testing.T) {
+		res := k.Report(owner, time.Unix(250, 0), time.Unix(300, 0))
+		require.Len(t, res.Markets, 2)
+
+		mkt := res.Markets[0]
+		assert.Equal(t, "1", mkt.MarketID.String())
+		assert.EqualValues(t, 1, mkt.Trades)
+		testutil.AssertEqualUints(t, sdk.ZeroUint(), mkt.BoughtQuantity)
+		testutil.AssertEqualUints(t, sdk.NewUint(5), mkt.SoldQuantity)
+		testutil.AssertEqualUints(t, sdk.NewUint(1500), mkt.QuoteVolume)
+		assert.Equal(t, "750", mkt.RealizedPnL.String())
+		testutil.AssertEqualUints(t, sdk.NewUint(15), mkt.Position)
+		testutil.AssertEqualUints(t, sdk.NewUint(150), mkt.AvgEntryPrice)
+		assert.Equal(t, "15quote", mkt.Fees.String())
+
+		// sales of holdings not bought on the exchange realize nothing
+		mkt = res.Markets[1]
+		assert.Equal(t, "2", mkt.MarketID.String())
+		testutil.AssertEqualUints(t, sdk.NewUint(5), mkt.SoldQuantity)
+		assert.Equal(t, "0", mkt.RealizedPnL.String())
+		testutil.AssertEqualUints(t, sdk.ZeroUint(), mkt.Position)
+	})
+
+	t.Run("only counts fills within the window", func(t *testing.T) {
+		res := k.Report(owner, time.Unix(0, 0), time.Unix(150, 0))
+		require.Len(t, res.Markets, 1)
+		mkt := res.Markets[0]
+		assert.EqualValues(t, 1, mkt.Trades)
+		testutil.AssertEqualUints(t, sdk.NewUint(10), mkt.BoughtQuantity)
+		testutil.AssertEqualUints(t, sdk.NewUint(10), mkt.Position)
+		testutil.AssertEqualUints(t, sdk.NewUint(100), mkt.AvgEntryPrice)
+		assert.Equal(t, "1base", mkt.Fees.String())
+
+		res = k.Report(owner, time.Unix(0, 0), time.Unix(1000, 0))
+		require.Len(t, res.Markets, 2)
+		assert.EqualValues(t, 3, res.Markets[0].Trades)
+		assert.Equal(t, "2base,15quote", res.Markets[0].Fees.String())
+	})
+
+	t.Run("renders reports as CSV", func(t *testing.T) {
+		out := ReportCSV(k.Report(owner, time.Unix(0, 0), time.Unix(1000, 0)))
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "market_id,pair,trades,bought_quantity,sold_quantity,quote_volume,realized_pnl,position,avg_entry_price,fees", lines[0])
+		assert.Equal(t, `1,BASE/QUOTE,3,20,5,4500,750,15,150,"2base,15quote"`, lines[1])
+	})
+}
+
+func TestKeeper_MultipleFillsPerBlock(t *testing.T) {
+	testflags.UnitTest(t)
+	k := NewKeeper(dbm.NewMemDB(), codec.New())
+	owner := testutil.RandAddr()
+
+	for _, qty := range []uint64{1, 2, 3} {
+		require.NoError(t, k.OnEvent(testFill(owner, 1, 1, matcheng.Bid, 5, qty, 100, 0)))
+	}
+
+	var qtys []string
+	k.IterOverBlockNumbers(5, 6, func(fill Fill) bool {
+		qtys = append(qtys, fill.QtyFilled.String())
+		return true
+	})
+	assert.Equal(t, []string{"1", "2", "3"}, qtys)
+
+	qtys = nil
+	k.IterOverOwner(owner, 5, 6, func(fill Fill) bool {
+		qtys = append(qtys, fill.QtyFilled.String())
+		return true
+	})
+	assert.Equal(t, []string{"1", "2", "3"}, qtys)
+}
+
+func TestKeeper_MigrateOwnerIndex(t *testing.T) {
+	testflags.UnitTest(t)
+	cdc := codec.New()
+	k := NewKeeper(dbm.NewMemDB(), cdc)
+	owner := testutil.RandAddr()
+	other := testutil.RandAddr()
+
+	// fills stored before the owner index existed were keyed by order ID
+	for _, ev := range []types.Fill{
+		testFill(owner, 1, 1, matcheng.Bid, 1, 10, 100, 0),
+		testFill(other, 2, 1, matcheng.Ask, 1, 10, 100, 0),
+		testFill(owner, 3, 1, matcheng.Bid, 2, 10, 100, 0),
+	} {
+		fill := Fill{
+			OrderID:     ev.OrderID,
+			MarketID:    ev.MarketID,
+			Owner:       ev.Owner,
+			BlockNumber: ev.BlockNumber,
+			QtyFilled:   ev.QtyFilled,
+			QtyUnfilled: ev.QtyUnfilled,
+			Price:       ev.Price,
+			QuoteQty:    ev.QuoteQty,
+			Fee:         ev.Fee,
+		}
+		k.as.Set(store.PrefixKeyBytes(fillIterKey(ev.BlockNumber), ev.OrderID.Bytes()), cdc.MustMarshalBinaryBare(fill))
+	}
+	ownerIDs := func() []string {
+		var ids []string
+		k.IterOverOwner(owner, 0, math.MaxInt64, func(fill Fill) bool {
+			ids = append(ids, fill.OrderID.String())
+			return true
+		})
+		return ids
+	}
+	assert.Empty(t, ownerIDs())
+
+	assert.Equal(t, 3, k.MigrateOwnerIndex())
+	assert.Equal(t, []string{"1", "3"}, ownerIDs())
+	assert.Equal(t, 0, k.MigrateOwnerIndex())
+
+	// fills stored after the migration are indexed as they arrive
+	require.NoError(t, k.OnEvent(testFill(owner, 4, 1, matcheng.Bid, 3, 10, 100, 0)))
+	assert.Equal(t, []string{"1", "3", "4"}, ownerIDs())
+}
+
+func testFill(owner sdk.AccAddress, orderID uint64, mktID uint64, dir matcheng.Direction, block int64, qty uint64, price uint64, fee uint64) types.Fill {
+	feeDenom := "base"
+	if dir == matcheng.Ask {
+		feeDenom = "quote"
+	}
+	return types.Fill{
+		OrderID:     store.NewEntityID(orderID),
+		MarketID:    store.NewEntityID(mktID),
+		Owner:       owner,
+		Pair:        "BASE/QUOTE",
+		Direction:   dir,
+		QtyFilled:   sdk.NewUint(qty),
+		QtyUnfilled: sdk.ZeroUint(),
+		BlockNumber: block,
+		BlockTime:   block * 100,
+		Price:       sdk.NewUint(price),
+		QuoteQty:    sdk.NewUint(qty * price),
+		Fee:         sdk.NewUint(fee),
+		FeeDenom:    feeDenom,
+	}
+}
@@ -12,7 +12,8 @@ import (
 )
 
 const (
-	QueryGet = "get"
+	QueryGet    = "get"
+	QueryReport = "report"
 )
 
 func NewQuerier(keeper Keeper) sdk.Querier {
@@ -20,6 +21,8 @@ func NewQuerier(keeper Keeper) sdk.Querier {
 		switch path[0] {
 		case QueryGet:
 			return queryGet(ctx, keeper, req.Data)
+		case QueryReport:
+			return queryReport(keeper, req.Data)
 		default:
 			return nil, sdk.ErrUnknownRequest("unknown fill query endpoint")
 		}
@@ -52,14 +55,15 @@ func queryGet(ctx sdk.Context, keeper Keeper, reqB []byte) ([]byte, sdk.Error) {
 	res := QueryResult{
 		Fills: make([]Fill, 0),
 	}
-	keeper.IterOverBlockNumbers(start, end, func(fill Fill) bool {
-		if !req.Owner.Empty() && !req.Owner.Equals(fill.Owner) {
-			return true
-		}
-
+	collect := func(fill Fill) bool {
 		res.Fills = append(res.Fills, fill)
 		return true
-	})
+	}
+	if req.Owner.Empty() {
+		keeper.IterOverBlockNumbers(start, end, collect)
+	} else {
+		keeper.IterOverOwner(req.Owner, start, end, collect)
+	}
 
 	b, err := codec.MarshalJSONIndent(keeper.cdc, res)
 	if err != nil {
@@ -67,3 +71,23 @@ func queryGet(ctx sdk.Context, keeper Keeper, reqB []byte) ([]byte, sdk.Error) {
 	}
 	return b, nil
 }
+
+func queryReport(keeper Keeper, reqB []byte) ([]byte, sdk.Error) {
+	var req ReportRequest
+	err := keeper.cdc.UnmarshalBinaryBare(reqB, &req)
+	if err != nil {
+		return nil, errs.ErrUnmarshalFailure("failed to unmarshal fill report request")
+	}
+	if req.Owner.Empty() {
+		return nil, errs.ErrInvalidArgument("owner must be defined")
+	}
+	if req.From.After(req.To) {
+		return nil, errs.ErrInvalidArgument("from cannot be after to")
+	}
+
+	b, err := codec.MarshalJSONIndent(keeper.cdc, keeper.Report(req.Owner, req.From, req.To))
+	if err != nil {
+		return nil, sdk.ErrInternal("could not marshal result")
+	}
+	return b, nil
+}
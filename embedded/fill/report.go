@@ -0,0 +1,174 @@
+package fill
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/xar-network/xar-network/pkg/conv"
+	"github.com/xar-network/xar-network/pkg/matcheng"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+// position tracks the holdings bought in a market and what they cost.
+type position struct {
+	report MarketReport
+	qty    sdk.Uint
+	// cost is the quote amount paid for qty, and priceQty the sum of the
+	// fill prices weighted by quantity
+	cost     sdk.Uint
+	priceQty sdk.Uint
+}
+
+// Report computes the trading report of an owner between from and to. Cost
+// basis is tracked with the average cost method over every fill of the owner,
+// so sales are matched against purchases made before the window. Sales of
+// holdings not bought on the exchange have no known cost basis and are left
+// out of the realized PnL.
+func (k Keeper) Report(owner sdk.AccAddress, from time.Time, to time.Time) ReportResult {
+	var order []string
+	positions := make(map[string]*position)
+
+	k.IterOverOwner(owner, 0, math.MaxInt64, func(fill Fill) bool {
+		if fill.BlockTime > to.Unix() {
+			return false
+		}
+
+		mktID := fill.MarketID.String()
+		pos, ok := positions[mktID]
+		if !ok {
+			pos = &position{
+				report: MarketReport{
+					MarketID:       fill.MarketID,
+					Pair:           fill.Pair,
+					BoughtQuantity: sdk.ZeroUint(),
+					SoldQuantity:   sdk.ZeroUint(),
+					QuoteVolume:    sdk.ZeroUint(),
+					RealizedPnL:    sdk.ZeroInt(),
+					Fees:           sdk.NewCoins(),
+				},
+				qty:      sdk.ZeroUint(),
+				cost:     sdk.ZeroUint(),
+				priceQty: sdk.ZeroUint(),
+			}
+			positions[mktID] = pos
+			order = append(order, mktID)
+		}
+		pos.apply(fill, fill.BlockTime >= from.Unix())
+		return true
+	})
+
+	res := ReportResult{
+		Owner:   owner,
+		From:    from,
+		To:      to,
+		Markets: make([]MarketReport, 0),
+	}
+	for _, mktID := range order {
+		pos := positions[mktID]
+		if pos.report.Trades == 0 && pos.qty.IsZero() {
+			continue
+		}
+		pos.report.Position = pos.qty
+		pos.report.AvgEntryPrice = sdk.ZeroUint()
+		if !pos.qty.IsZero() {
+			pos.report.AvgEntryPrice = pos.priceQty.Quo(pos.qty)
+		}
+		res.Markets = append(res.Markets, pos.report)
+	}
+	return res
+}
+
+// apply adds a fill to the position, and to the report if it was made
+// within the report window.
+func (p *position) apply(fill Fill, inWindow bool) {
+	quoteQty := orZero(fill.QuoteQty)
+	if fill.Direction == matcheng.Bid {
+		p.qty = p.qty.Add(fill.QtyFilled)
+		p.cost = p.cost.Add(quoteQty)
+		p.priceQty = p.priceQty.Add(fill.Price.Mul(fill.QtyFilled))
+		if inWindow {
+			p.report.BoughtQuantity = p.report.BoughtQuantity.Add(fill.QtyFilled)
+		}
+	} else {
+		sold := fill.QtyFilled
+		if sold.GT(p.qty) {
+			sold = p.qty
+		}
+		basis := sdk.ZeroUint()
+		proceeds := sdk.ZeroUint()
+		if !sold.IsZero() {
+			basis = p.cost.Mul(sold).Quo(p.qty)
+			proceeds = quoteQty.Mul(sold).Quo(fill.QtyFilled)
+			p.priceQty = p.priceQty.Sub(p.priceQty.Mul(sold).Quo(p.qty))
+			p.cost = p.cost.Sub(basis)
+			p.qty = p.qty.Sub(sold)
+		}
+		if inWindow {
+			p.report.SoldQuantity = p.report.SoldQuantity.Add(fill.QtyFilled)
+			p.report.RealizedPnL = p.report.RealizedPnL.
+				Add(sdk.NewIntFromBigInt(conv.SDKUint2Big(proceeds))).
+				Sub(sdk.NewIntFromBigInt(conv.SDKUint2Big(basis)))
+		}
+	}
+
+	if !inWindow {
+		return
+	}
+	p.report.Trades++
+	p.report.QuoteVolume = p.report.QuoteVolume.Add(quoteQty)
+	fee := orZero(fill.Fee)
+	if fill.FeeDenom != "" && !fee.IsZero() {
+		p.report.Fees = p.report.Fees.Add(sdk.NewCoins(sdk.NewCoin(fill.FeeDenom, sdk.NewIntFromBigInt(conv.SDKUint2Big(fee)))))
+	}
+}
+
+// ReportCSV renders a report as CSV, one row per market.
+func ReportCSV(res ReportResult) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{
+		"market_id",
+		"pair",
+		"trades",
+		"bought_quantity",
+		"sold_quantity",
+		"quote_volume",
+		"realized_pnl",
+		"position",
+		"avg_entry_price",
+		"fees",
+	})
+	for _, mkt := range res.Markets {
+		_ = w.Write([]string{
+			mkt.MarketID.String(),
+			mkt.Pair,
+			strconv.FormatUint(mkt.Trades, 10),
+			mkt.BoughtQuantity.String(),
+			mkt.SoldQuantity.String(),
+			mkt.QuoteVolume.String(),
+			mkt.RealizedPnL.String(),
+			mkt.Position.String(),
+			mkt.AvgEntryPrice.String(),
+			mkt.Fees.String(),
+		})
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// orZero treats amounts missing from fills stored by older versions as zero.
+func orZero(u sdk.Uint) sdk.Uint {
+	if u == (sdk.Uint{}) {
+		return sdk.ZeroUint()
+	}
+	return u
+}
@@ -1,8 +1,10 @@
 package fill
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/tendermint/tendermint/types"
@@ -18,6 +20,7 @@ import (
 
 func RegisterRoutes(ctx context.CLIContext, r *mux.Router, cdc *codec.Codec) {
 	r.Handle("/user/fills", auth.DefaultAuthMW(userFills(ctx, cdc))).Methods("GET")
+	r.Handle("/user/fills/report", auth.DefaultAuthMW(userReport(ctx, cdc))).Methods("GET")
 }
 
 func userFills(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
@@ -84,9 +87,13 @@ func userFills(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
 				QuantityUnfilled: fill.QtyUnfilled,
 				Direction:        fill.Direction,
 				OrderID:          fill.OrderID,
+				MarketID:         fill.MarketID,
 				Pair:             fill.Pair,
 				Price:            fill.Price,
+				QuoteQuantity:    orZero(fill.QuoteQty),
 				Owner:            fill.Owner,
+				Fee:              fill.Fee,
+				FeeDenom:         fill.FeeDenom,
 			})
 		}
 
@@ -95,6 +102,63 @@ func userFills(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
 	}
 }
 
+// userReport returns the trading report of the logged in user between the
+// start and end dates, as JSON or CSV depending on the format parameter. The
+// window defaults to the last 30 days.
+func userReport(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		owner := auth.MustGetKBFromSession(r).GetAddr()
+		q := r.URL.Query()
+
+		now := time.Now()
+		req := ReportRequest{
+			Owner: owner,
+			From:  now.AddDate(0, 0, -30),
+			To:    now,
+		}
+		if start, ok := q["start"]; ok {
+			startDate, err := conv.ParseISO8601(start[0])
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, "invalid start date")
+				return
+			}
+			req.From = startDate
+		}
+		if end, ok := q["end"]; ok {
+			endDate, err := conv.ParseISO8601(end[0])
+			if err != nil {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, "invalid end date")
+				return
+			}
+			req.To = endDate
+		}
+		format := q.Get("format")
+		if format == "" {
+			format = FormatJSON
+		}
+		if format != FormatJSON && format != FormatCSV {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, "invalid format")
+			return
+		}
+
+		resB, _, err := ctx.QueryWithData(fmt.Sprintf("custom/fill/%s", QueryReport), cdc.MustMarshalBinaryBare(req))
+		if err != nil {
+			rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if format == FormatJSON {
+			embedded.PostProcessResponse(w, ctx, resB)
+			return
+		}
+
+		var res ReportResult
+		cdc.MustUnmarshalJSON(resB, &res)
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"report-%s.csv\"", owner.String()))
+		_, _ = w.Write([]byte(ReportCSV(res)))
+	}
+}
+
 func getBlock(ctx context.CLIContext, height int64) (*types.Block, error) {
 	node, err := ctx.GetNode()
 	if err != nil {
@@ -1,6 +1,8 @@
 package fill
 
 import (
+	"time"
+
 	"github.com/xar-network/xar-network/embedded"
 	"github.com/xar-network/xar-network/pkg/matcheng"
 	"github.com/xar-network/xar-network/types/store"
@@ -10,13 +12,18 @@ import (
 
 type Fill struct {
 	OrderID     store.EntityID     `json:"order_id"`
+	MarketID    store.EntityID     `json:"market_id"`
 	Owner       sdk.AccAddress     `json:"owner"`
 	Pair        string             `json:"pair"`
 	Direction   matcheng.Direction `json:"direction"`
 	QtyFilled   sdk.Uint           `json:"qty_filled"`
 	QtyUnfilled sdk.Uint           `json:"qty_unfilled"`
 	BlockNumber int64              `json:"block_number"`
+	BlockTime   int64              `json:"block_time"`
 	Price       sdk.Uint           `json:"price"`
+	QuoteQty    sdk.Uint           `json:"quote_qty"`
+	Fee         sdk.Uint           `json:"fee"`
+	FeeDenom    string             `json:"fee_denom"`
 }
 
 type QueryRequest struct {
@@ -39,7 +46,43 @@ type RESTFill struct {
 	QuantityUnfilled sdk.Uint                `json:"quantity_unfilled"`
 	Direction        matcheng.Direction      `json:"direction"`
 	OrderID          store.EntityID          `json:"order_id"`
+	MarketID         store.EntityID          `json:"market_id"`
 	Pair             string                  `json:"pair"`
 	Price            sdk.Uint                `json:"price"`
+	QuoteQuantity    sdk.Uint                `json:"quote_quantity"`
 	Owner            sdk.AccAddress          `json:"owner"`
+	Fee              sdk.Uint                `json:"fee"`
+	FeeDenom         string                  `json:"fee_denom"`
+}
+
+// ReportRequest selects the fills of an owner to report on. Fills made
+// before From still count towards the position and its cost basis.
+type ReportRequest struct {
+	Owner sdk.AccAddress
+	From  time.Time
+	To    time.Time
+}
+
+type ReportResult struct {
+	Owner   sdk.AccAddress `json:"owner"`
+	From    time.Time      `json:"from"`
+	To      time.Time      `json:"to"`
+	Markets []MarketReport `json:"markets"`
+}
+
+// MarketReport summarizes the trading of an owner in a market. Volumes, fees
+// and realized PnL only cover the report window, while the position and its
+// average entry price are as of the end of the window. PnL is in units of
+// the quote asset and does not include fees.
+type MarketReport struct {
+	MarketID       store.EntityID `json:"market_id"`
+	Pair           string         `json:"pair"`
+	Trades         uint64         `json:"trades"`
+	BoughtQuantity sdk.Uint       `json:"bought_quantity"`
+	SoldQuantity   sdk.Uint       `json:"sold_quantity"`
+	QuoteVolume    sdk.Uint       `json:"quote_volume"`
+	RealizedPnL    sdk.Int        `json:"realized_pnl"`
+	Position       sdk.Uint       `json:"position"`
+	AvgEntryPrice  sdk.Uint       `json:"avg_entry_price"`
+	Fees           sdk.Coins      `json:"fees"`
 }
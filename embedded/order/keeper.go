@@ -76,7 +76,7 @@ func (k Keeper) OnOrderCreatedEvent(event types.OrderCreated) {
 		Price:          event.Price,
 		Quantity:       event.Quantity,
 		Status:         "OPEN",
-		Type:           "LIMIT",
+		Type:           event.Type.String(),
 		TimeInForce:    event.TimeInForceBlocks,
 		QuantityFilled: sdk.NewUint(0),
 		CreatedBlock:   event.CreatedBlock,
@@ -100,6 +100,20 @@ func (k Keeper) OnFillEvent(event types.Fill) sdk.Error {
 	return nil
 }
 
+func (k Keeper) OnOrderAmendedEvent(event types.OrderAmended) sdk.Error {
+	order, err := k.Get(event.OrderID)
+	if err != nil {
+		return err
+	}
+
+	// the event carries the unfilled quantity; the indexed order tracks
+	// the total quantity alongside what has been filled so far
+	order.Price = event.Price
+	order.Quantity = order.QuantityFilled.Add(event.Quantity)
+	k.Set(order)
+	return nil
+}
+
 func (k Keeper) OnOrderCancelledEvent(event types.OrderCancelled) sdk.Error {
 	order, err := k.Get(event.OrderID)
 	if err != nil {
@@ -174,6 +188,8 @@ func (k Keeper) OnEvent(event interface{}) error {
 	switch ev := event.(type) {
 	case types.OrderCreated:
 		k.OnOrderCreatedEvent(ev)
+	case types.OrderAmended:
+		return k.OnOrderAmendedEvent(ev)
 	case types.OrderCancelled:
 		return k.OnOrderCancelledEvent(ev)
 	case types.Fill:
@@ -190,6 +190,43 @@ func TestKeeper(t *testing.T) {
 			CreatedBlock:   ev5.CreatedBlock,
 		}, res)
 	})
+	t.Run("amended orders keep their fills", func(t *testing.T) {
+		created := types.OrderCreated{
+			ID:                store.NewEntityID(7),
+			Owner:             sdk.AccAddress{},
+			MarketID:          store.NewEntityID(3),
+			Direction:         matcheng.Bid,
+			Price:             sdk.NewUint(100),
+			Quantity:          sdk.NewUint(100),
+			TimeInForceBlocks: 19,
+			CreatedBlock:      10,
+		}
+		require.NoError(t, k.OnEvent(created))
+		require.NoError(t, k.OnEvent(types.Fill{
+			OrderID:   created.ID,
+			QtyFilled: sdk.NewUint(30),
+		}))
+		require.NoError(t, k.OnEvent(types.OrderAmended{
+			OrderID:  created.ID,
+			Price:    sdk.NewUint(95),
+			Quantity: sdk.NewUint(20),
+		}))
+		res, err := k.Get(created.ID)
+		require.NoError(t, err)
+		assertEqualOrders(t, cdc, Order{
+			ID:             created.ID,
+			Owner:          created.Owner,
+			MarketID:       created.MarketID,
+			Direction:      created.Direction,
+			Price:          sdk.NewUint(95),
+			Quantity:       sdk.NewUint(50),
+			Status:         "OPEN",
+			Type:           "LIMIT",
+			TimeInForce:    created.TimeInForceBlocks,
+			QuantityFilled: sdk.NewUint(30),
+			CreatedBlock:   created.CreatedBlock,
+		}, res)
+	})
 }
 
 func assertEqualOrders(t *testing.T, cdc *codec.Codec, exp Order, actual Order) {
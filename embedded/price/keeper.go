@@ -1,19 +1,33 @@
 package price
 
 import (
+	"encoding/binary"
 	"time"
 
 	dbm "github.com/tendermint/tm-db"
 
+	"github.com/xar-network/xar-network/pkg/conv"
+	"github.com/xar-network/xar-network/pkg/matcheng"
 	"github.com/xar-network/xar-network/types"
 	"github.com/xar-network/xar-network/types/store"
 
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/store/types"
+	sdktypes "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	candleVersionKey = "candlever"
+
+	// candleVersion is the version of the candle rollups. Ticks stored before
+	// candles were rolled up are rolled up by MigrateCandles.
+	candleVersion uint64 = 1
 )
 
 type IteratorCB func(tick Tick) bool
 
+type CandleIteratorCB func(candle Candle) bool
+
 type Keeper struct {
 	as  store.ArchiveStore
 	cdc *codec.Codec
@@ -28,38 +42,185 @@ func NewKeeper(db dbm.DB, cdc *codec.Codec) Keeper {
 
 func (k Keeper) ReverseIteratorByMarket(mktID store.EntityID, cb IteratorCB) {
 	k.as.PrefixIterator(tickIterKey(mktID), func(_ []byte, v []byte) bool {
-		var tick Tick
-		k.cdc.MustUnmarshalBinaryBare(v, &tick)
-		return cb(tick)
+		return cb(k.unmarshalTick(v))
 	})
 }
 
 func (k Keeper) ReverseIteratorByMarketFrom(mktID store.EntityID, from time.Time, cb IteratorCB) {
 	k.as.ReverseIterator(tickKey(mktID, 0), sdk.PrefixEndBytes(tickKey(mktID, 0)), func(_ []byte, v []byte) bool {
-		var tick Tick
-		k.cdc.MustUnmarshalBinaryBare(v, &tick)
-		return cb(tick)
+		return cb(k.unmarshalTick(v))
 	})
 }
 
 func (k Keeper) IteratorByMarketAndInterval(mktID store.EntityID, from time.Time, to time.Time, cb IteratorCB) {
 	k.as.Iterator(tickKey(mktID, from.Unix()), sdk.PrefixEndBytes(tickKey(mktID, to.Unix())), func(_ []byte, v []byte) bool {
-		var tick Tick
-		k.cdc.MustUnmarshalBinaryBare(v, &tick)
-		return cb(tick)
+		return cb(k.unmarshalTick(v))
+	})
+}
+
+// CandleIterator iterates over the candles of an interval that start between
+// from and to, inclusive. Pass a from rounded to the interval to include the
+// candle containing it.
+func (k Keeper) CandleIterator(mktID store.EntityID, interval CandleInterval, from time.Time, to time.Time, cb CandleIteratorCB) {
+	k.as.Iterator(candleKey(mktID, interval, from.Unix()), sdk.PrefixEndBytes(candleKey(mktID, interval, to.Unix())), func(_ []byte, v []byte) bool {
+		return cb(k.unmarshalCandle(v))
 	})
 }
 
 func (k Keeper) OnFillEvent(event types.Fill) {
+	fill := fillTick(event)
+	key := tickKey(event.MarketID, event.BlockTime)
+	tick := Tick{
+		Volume:      sdktypes.ZeroUint(),
+		QuoteVolume: sdktypes.ZeroUint(),
+		Fees:        sdktypes.NewCoins(),
+	}
+	if b := k.as.Get(key); b != nil {
+		tick = k.unmarshalTick(b)
+	}
+	if tick.BlockNumber != event.BlockNumber {
+		tick.Volume = sdktypes.ZeroUint()
+		tick.QuoteVolume = sdktypes.ZeroUint()
+		tick.Trades = 0
+		tick.Fees = sdktypes.NewCoins()
+	}
+
+	tick.MarketID = event.MarketID
+	tick.Pair = event.Pair
+	tick.BlockNumber = event.BlockNumber
+	tick.BlockTime = event.BlockTime
+	tick.Price = event.Price
+	tick.Volume = tick.Volume.Add(fill.Volume)
+	tick.QuoteVolume = tick.QuoteVolume.Add(fill.QuoteVolume)
+	tick.Trades += fill.Trades
+	tick.Fees = tick.Fees.Add(fill.Fees)
+
+	storedB := k.cdc.MustMarshalBinaryBare(tick)
+	k.as.Set(key, storedB)
+
+	for _, interval := range CandleIntervals {
+		k.updateCandle(fill, interval)
+	}
+}
+
+// MigrateCandles rolls every stored tick up into candles if the ticks were
+// stored before candles were rolled up. Candles of those blocks would
+// otherwise be missing. A tick only keeps the last price of its block, so
+// backfilled candles miss the highs and lows reached within a block. It
+// returns the number of ticks that were rolled up.
+func (k Keeper) MigrateCandles() int {
+	if ver := k.as.Get([]byte(candleVersionKey)); ver != nil && binary.BigEndian.Uint64(ver) >= candleVersion {
+		return 0
+	}
+
+	var ticks []Tick
+	k.as.PrefixIterator(store.PrefixKeyString("tick"), func(_ []byte, v []byte) bool {
+		ticks = append(ticks, k.unmarshalTick(v))
+		return true
+	})
+	for _, tick := range ticks {
+		for _, interval := range CandleIntervals {
+			k.updateCandle(tick, interval)
+		}
+	}
+
+	var ver [8]byte
+	binary.BigEndian.PutUint64(ver[:], candleVersion)
+	k.as.Set([]byte(candleVersionKey), ver[:])
+	return len(ticks)
+}
+
+// fillTick returns the tick of a single fill. Only bids count towards volume,
+// so that each trade is counted once, while the fees of both sides count.
+func fillTick(event types.Fill) Tick {
 	tick := Tick{
 		MarketID:    event.MarketID,
 		Pair:        event.Pair,
 		BlockNumber: event.BlockNumber,
 		BlockTime:   event.BlockTime,
 		Price:       event.Price,
+		Volume:      sdktypes.ZeroUint(),
+		QuoteVolume: sdktypes.ZeroUint(),
+		Fees:        sdktypes.NewCoins(),
 	}
-	storedB := k.cdc.MustMarshalBinaryBare(tick)
-	k.as.Set(tickKey(event.MarketID, tick.BlockTime), storedB)
+	if fee := orZero(event.Fee); event.FeeDenom != "" && !fee.IsZero() {
+		tick.Fees = sdktypes.NewCoins(sdktypes.NewCoin(event.FeeDenom, sdktypes.NewIntFromBigInt(conv.SDKUint2Big(fee))))
+	}
+	if event.Direction == matcheng.Bid {
+		tick.Volume = event.QtyFilled
+		tick.QuoteVolume = orZero(event.QuoteQty)
+		tick.Trades = 1
+	}
+	return tick
+}
+
+// updateCandle rolls a tick up into the candle of the given interval that
+// contains its block time.
+func (k Keeper) updateCandle(tick Tick, interval CandleInterval) {
+	start := roundTime(time.Unix(tick.BlockTime, 0), interval).Unix()
+	key := candleKey(tick.MarketID, interval, start)
+
+	var candle Candle
+	if b := k.as.Get(key); b != nil {
+		candle = k.unmarshalCandle(b)
+	} else {
+		candle = Candle{
+			MarketID:    tick.MarketID,
+			Pair:        tick.Pair,
+			Start:       start,
+			Open:        tick.Price,
+			High:        tick.Price,
+			Low:         tick.Price,
+			Volume:      sdktypes.ZeroUint(),
+			QuoteVolume: sdktypes.ZeroUint(),
+			Fees:        sdktypes.NewCoins(),
+		}
+	}
+
+	candle.Close = tick.Price
+	if tick.Price.GT(candle.High) {
+		candle.High = tick.Price
+	}
+	if tick.Price.LT(candle.Low) {
+		candle.Low = tick.Price
+	}
+	candle.Volume = candle.Volume.Add(tick.Volume)
+	candle.QuoteVolume = candle.QuoteVolume.Add(tick.QuoteVolume)
+	candle.Trades += tick.Trades
+	candle.Fees = candle.Fees.Add(tick.Fees)
+
+	k.as.Set(key, k.cdc.MustMarshalBinaryBare(candle))
+}
+
+// unmarshalCandle decodes a stored candle. Candles stored before fees were
+// tracked have none.
+func (k Keeper) unmarshalCandle(b []byte) Candle {
+	var candle Candle
+	k.cdc.MustUnmarshalBinaryBare(b, &candle)
+	if candle.Fees == nil {
+		candle.Fees = sdktypes.NewCoins()
+	}
+	return candle
+}
+
+// unmarshalTick decodes a stored tick. Ticks stored before volumes and fees
+// were tracked have none.
+func (k Keeper) unmarshalTick(b []byte) Tick {
+	var tick Tick
+	k.cdc.MustUnmarshalBinaryBare(b, &tick)
+	tick.Volume = orZero(tick.Volume)
+	tick.QuoteVolume = orZero(tick.QuoteVolume)
+	if tick.Fees == nil {
+		tick.Fees = sdktypes.NewCoins()
+	}
+	return tick
+}
+
+func orZero(u sdktypes.Uint) sdktypes.Uint {
+	if u == (sdktypes.Uint{}) {
+		return sdktypes.ZeroUint()
+	}
+	return u
 }
 
 func (k Keeper) OnEvent(event interface{}) error {
@@ -78,3 +239,11 @@ func tickKey(mktID store.EntityID, blockTime int64) []byte {
 func tickIterKey(mktID store.EntityID) []byte {
 	return store.PrefixKeyString("tick", mktID.Bytes())
 }
+
+func candleKey(mktID store.EntityID, interval CandleInterval, start int64) []byte {
+	// the first weekly candle starts before the epoch
+	if start < 0 {
+		start = 0
+	}
+	return store.PrefixKeyString("candle", mktID.Bytes(), []byte(interval), store.Int64Subkey(start))
+}
@@ -31,52 +31,52 @@ func TestQuerier_Candles(t *testing.T) {
 
 	fills := []types.Fill{
 		{
-			store.NewEntityID(1),
-			mktID,
-			testutil.RandAddr(),
-			"DEX/ETH",
-			matcheng.Bid,
-			sdk.NewUint(100),
-			sdk.NewUint(0),
-			1,
-			100,
-			sdk.NewUint(100),
+			OrderID:     store.NewEntityID(1),
+			MarketID:    mktID,
+			Owner:       testutil.RandAddr(),
+			Pair:        "DEX/ETH",
+			Direction:   matcheng.Bid,
+			QtyFilled:   sdk.NewUint(100),
+			QtyUnfilled: sdk.NewUint(0),
+			BlockNumber: 1,
+			BlockTime:   100,
+			Price:       sdk.NewUint(100),
 		},
 		{
-			store.NewEntityID(1),
-			mktID,
-			testutil.RandAddr(),
-			"DEX/ETH",
-			matcheng.Bid,
-			sdk.NewUint(100),
-			sdk.NewUint(0),
-			2,
-			130,
-			sdk.NewUint(90),
+			OrderID:     store.NewEntityID(1),
+			MarketID:    mktID,
+			Owner:       testutil.RandAddr(),
+			Pair:        "DEX/ETH",
+			Direction:   matcheng.Bid,
+			QtyFilled:   sdk.NewUint(100),
+			QtyUnfilled: sdk.NewUint(0),
+			BlockNumber: 2,
+			BlockTime:   130,
+			Price:       sdk.NewUint(90),
 		},
 		{
-			store.NewEntityID(1),
-			mktID,
-			testutil.RandAddr(),
-			"DEX/ETH",
-			matcheng.Bid,
-			sdk.NewUint(100),
-			sdk.NewUint(0),
-			3,
-			160,
-			sdk.NewUint(120),
+			OrderID:     store.NewEntityID(1),
+			MarketID:    mktID,
+			Owner:       testutil.RandAddr(),
+			Pair:        "DEX/ETH",
+			Direction:   matcheng.Bid,
+			QtyFilled:   sdk.NewUint(100),
+			QtyUnfilled: sdk.NewUint(0),
+			BlockNumber: 3,
+			BlockTime:   160,
+			Price:       sdk.NewUint(120),
 		},
 		{
-			store.NewEntityID(1),
-			mktID,
-			testutil.RandAddr(),
-			"DEX/ETH",
-			matcheng.Bid,
-			sdk.NewUint(100),
-			sdk.NewUint(0),
-			4,
-			190,
-			sdk.NewUint(140),
+			OrderID:     store.NewEntityID(1),
+			MarketID:    mktID,
+			Owner:       testutil.RandAddr(),
+			Pair:        "DEX/ETH",
+			Direction:   matcheng.Bid,
+			QtyFilled:   sdk.NewUint(100),
+			QtyUnfilled: sdk.NewUint(0),
+			BlockNumber: 4,
+			BlockTime:   190,
+			Price:       sdk.NewUint(140),
 		},
 	}
 
@@ -114,12 +114,12 @@ func TestQuerier_Candles(t *testing.T) {
 			Low:   sdk.NewUint(90),
 		}, res.Candles[0])
 	})
-	t.Run("should support inexact start and end dates", func(t *testing.T) {
+	t.Run("should return whole candles for inexact start and end dates", func(t *testing.T) {
 		res := fetchResult(t, app.Ctx, querier, app.Cdc, 101, 200, price.CandleInterval5M)
 		assert.Equal(t, 1, len(res.Candles))
 		assertEqualCandleEntries(t, price.CandleEntry{
 			Date:  time.Unix(0, 0),
-			Open:  sdk.NewUint(90),
+			Open:  sdk.NewUint(100),
 			Close: sdk.NewUint(140),
 			High:  sdk.NewUint(140),
 			Low:   sdk.NewUint(90),
@@ -128,11 +128,14 @@ func TestQuerier_Candles(t *testing.T) {
 }
 
 func fetchResult(t *testing.T, ctx sdk.Context, querier sdk.Querier, cdc *amino.Codec, from int64, to int64, interval price.CandleInterval) price.CandleQueryResult {
-	params := price.CandleQueryParams{
+	return queryCandles(t, ctx, querier, cdc, price.CandleQueryParams{
 		From:     time.Unix(from, 0),
 		To:       time.Unix(to, 0),
 		Interval: interval,
-	}
+	})
+}
+
+func queryCandles(t *testing.T, ctx sdk.Context, querier sdk.Querier, cdc *amino.Codec, params price.CandleQueryParams) price.CandleQueryResult {
 	paramsB := cdc.MustMarshalBinaryBare(params)
 	req := abci.RequestQuery{
 		Data: paramsB,
@@ -151,3 +154,236 @@ func assertEqualCandleEntries(t *testing.T, expected price.CandleEntry, actual p
 	testutil.AssertEqualUints(t, expected.High, actual.High)
 	testutil.AssertEqualUints(t, expected.Low, actual.Low)
 }
+
+func TestQuerier_CandleVolume(t *testing.T) {
+	testflags.UnitTest(t)
+	app := mockapp.New(t)
+	db := dbm.NewMemDB()
+	keeper := price.NewKeeper(db, app.Cdc)
+	mktID := store.NewEntityID(1)
+
+	fill := func(dir matcheng.Direction, block int64, blockTime int64, qty uint64, px uint64) types.Fill {
+		return types.Fill{
+			OrderID:     store.NewEntityID(1),
+			MarketID:    mktID,
+			Owner:       testutil.RandAddr(),
+			Pair:        "DEX/ETH",
+			Direction:   dir,
+			QtyFilled:   sdk.NewUint(qty),
+			QtyUnfilled: sdk.NewUint(0),
+			BlockNumber: block,
+			BlockTime:   blockTime,
+			Price:       sdk.NewUint(px),
+			QuoteQty:    sdk.NewUint(qty * px),
+		}
+	}
+	fills := []types.Fill{
+		fill(matcheng.Bid, 1, 100, 10, 5),
+		fill(matcheng.Bid, 1, 100, 20, 5),
+		fill(matcheng.Ask, 1, 100, 30, 5),
+		fill(matcheng.Bid, 2, 90000, 40, 6),
+		fill(matcheng.Ask, 2, 90000, 40, 6),
+	}
+	for _, f := range fills {
+		keeper.OnFillEvent(f)
+	}
+	querier := price.NewQuerier(keeper)
+
+	t.Run("should sum bid side volume per candle", func(t *testing.T) {
+		res := fetchResult(t, app.Ctx, querier, app.Cdc, 0, 100000, price.CandleInterval4H)
+		require.Equal(t, 2, len(res.Candles))
+		testutil.AssertEqualUints(t, sdk.NewUint(30), res.Candles[0].Volume)
+		testutil.AssertEqualUints(t, sdk.NewUint(150), res.Candles[0].QuoteVolume)
+		assert.EqualValues(t, 2, res.Candles[0].Trades)
+		assert.Equal(t, int64(86400), res.Candles[1].Date.Unix())
+		testutil.AssertEqualUints(t, sdk.NewUint(40), res.Candles[1].Volume)
+		assert.EqualValues(t, 1, res.Candles[1].Trades)
+	})
+	t.Run("should support daily candles", func(t *testing.T) {
+		res := fetchResult(t, app.Ctx, querier, app.Cdc, 0, 100000, price.CandleInterval1D)
+		require.Equal(t, 2, len(res.Candles))
+		assert.Equal(t, int64(0), res.Candles[0].Date.Unix())
+		assert.Equal(t, int64(86400), res.Candles[1].Date.Unix())
+	})
+	t.Run("should support weekly candles", func(t *testing.T) {
+		res := fetchResult(t, app.Ctx, querier, app.Cdc, 0, 100000, price.CandleInterval1W)
+		require.Equal(t, 1, len(res.Candles))
+		testutil.AssertEqualUints(t, sdk.NewUint(70), res.Candles[0].Volume)
+		testutil.AssertEqualUints(t, sdk.NewUint(390), res.Candles[0].QuoteVolume)
+		assert.EqualValues(t, 3, res.Candles[0].Trades)
+		testutil.AssertEqualUints(t, sdk.NewUint(5), res.Candles[0].Open)
+		testutil.AssertEqualUints(t, sdk.NewUint(6), res.Candles[0].Close)
+	})
+}
+
+func TestQuerier_CandleFees(t *testing.T) {
+	testflags.UnitTest(t)
+	app := mockapp.New(t)
+	keeper := price.NewKeeper(dbm.NewMemDB(), app.Cdc)
+	mktID := store.NewEntityID(1)
+
+	fill := func(dir matcheng.Direction, blockTime int64, fee uint64, feeDenom string) types.Fill {
+		return types.Fill{
+			OrderID:     store.NewEntityID(1),
+			MarketID:    mktID,
+			Owner:       testutil.RandAddr(),
+			Pair:        "DEX/ETH",
+			Direction:   dir,
+			QtyFilled:   sdk.NewUint(10),
+			QtyUnfilled: sdk.NewUint(0),
+			BlockNumber: blockTime,
+			BlockTime:   blockTime,
+			Price:       sdk.NewUint(5),
+			QuoteQty:    sdk.NewUint(50),
+			Fee:         sdk.NewUint(fee),
+			FeeDenom:    feeDenom,
+		}
+	}
+	for _, f := range []types.Fill{
+		fill(matcheng.Bid, 100, 1, "dex"),
+		fill(matcheng.Ask, 100, 5, "eth"),
+		fill(matcheng.Bid, 90000, 2, "dex"),
+		fill(matcheng.Ask, 90000, 0, "eth"),
+	} {
+		keeper.OnFillEvent(f)
+	}
+	querier := price.NewQuerier(keeper)
+
+	res := fetchResult(t, app.Ctx, querier, app.Cdc, 0, 100000, price.CandleInterval1D)
+	require.Equal(t, 2, len(res.Candles))
+	assert.Equal(t, "1dex,5eth", res.Candles[0].Fees.String())
+	assert.Equal(t, "2dex", res.Candles[1].Fees.String())
+
+	res = fetchResult(t, app.Ctx, querier, app.Cdc, 0, 100000, price.CandleInterval1W)
+	require.Equal(t, 1, len(res.Candles))
+	assert.Equal(t, "3dex,5eth", res.Candles[0].Fees.String())
+}
+
+func TestQuerier_MigratedCandles(t *testing.T) {
+	testflags.UnitTest(t)
+	app := mockapp.New(t)
+	db := dbm.NewMemDB()
+	mktID := store.NewEntityID(1)
+
+	// ticks stored before candles were rolled up
+	table := store.NewTable(db, price.EntityName)
+	ticks := []price.Tick{
+		{MarketID: mktID, Pair: "DEX/ETH", BlockNumber: 1, BlockTime: 100, Price: sdk.NewUint(5), Volume: sdk.NewUint(30), QuoteVolume: sdk.NewUint(150), Trades: 2},
+		{MarketID: mktID, Pair: "DEX/ETH", BlockNumber: 2, BlockTime: 200, Price: sdk.NewUint(7), Volume: sdk.NewUint(10), QuoteVolume: sdk.NewUint(70), Trades: 1},
+		{MarketID: mktID, Pair: "DEX/ETH", BlockNumber: 3, BlockTime: 90000, Price: sdk.NewUint(6), Volume: sdk.NewUint(40), QuoteVolume: sdk.NewUint(240), Trades: 1},
+	}
+	for _, tick := range ticks {
+		table.Set(store.PrefixKeyString("tick", mktID.Bytes(), store.Int64Subkey(tick.BlockTime)), app.Cdc.MustMarshalBinaryBare(tick))
+	}
+
+	keeper := price.NewKeeper(db, app.Cdc)
+	querier := price.NewQuerier(keeper)
+	res := fetchResult(t, app.Ctx, querier, app.Cdc, 0, 100000, price.CandleInterval1D)
+	assert.Empty(t, res.Candles)
+
+	assert.Equal(t, 3, keeper.MigrateCandles())
+	assert.Equal(t, 0, keeper.MigrateCandles())
+
+	res = fetchResult(t, app.Ctx, querier, app.Cdc, 0, 100000, price.CandleInterval1D)
+	require.Equal(t, 2, len(res.Candles))
+	testutil.AssertEqualUints(t, sdk.NewUint(5), res.Candles[0].Open)
+	testutil.AssertEqualUints(t, sdk.NewUint(7), res.Candles[0].High)
+	testutil.AssertEqualUints(t, sdk.NewUint(7), res.Candles[0].Close)
+	testutil.AssertEqualUints(t, sdk.NewUint(40), res.Candles[0].Volume)
+	testutil.AssertEqualUints(t, sdk.NewUint(220), res.Candles[0].QuoteVolume)
+	assert.EqualValues(t, 3, res.Candles[0].Trades)
+	testutil.AssertEqualUints(t, sdk.NewUint(40), res.Candles[1].Volume)
+
+	// fills after the migration are rolled up as they arrive
+	keeper.OnFillEvent(types.Fill{
+		OrderID:     store.NewEntityID(1),
+		MarketID:    mktID,
+		Owner:       testutil.RandAddr(),
+		Pair:        "DEX/ETH",
+		Direction:   matcheng.Bid,
+		QtyFilled:   sdk.NewUint(10),
+		QtyUnfilled: sdk.NewUint(0),
+		BlockNumber: 4,
+		BlockTime:   90100,
+		Price:       sdk.NewUint(4),
+		QuoteQty:    sdk.NewUint(40),
+	})
+	res = fetchResult(t, app.Ctx, querier, app.Cdc, 0, 100000, price.CandleInterval1D)
+	require.Equal(t, 2, len(res.Candles))
+	testutil.AssertEqualUints(t, sdk.NewUint(50), res.Candles[1].Volume)
+	testutil.AssertEqualUints(t, sdk.NewUint(4), res.Candles[1].Low)
+}
+
+func TestQuerier_CandlePagination(t *testing.T) {
+	testflags.UnitTest(t)
+	app := mockapp.New(t)
+	db := dbm.NewMemDB()
+	keeper := price.NewKeeper(db, app.Cdc)
+	mktID := store.NewEntityID(1)
+
+	// one fill a minute, more than fit in a single page
+	const count = price.MaxCandles + 10
+	for i := int64(0); i < count; i++ {
+		keeper.OnFillEvent(types.Fill{
+			OrderID:     store.NewEntityID(1),
+			MarketID:    mktID,
+			Owner:       testutil.RandAddr(),
+			Pair:        "DEX/ETH",
+			Direction:   matcheng.Bid,
+			QtyFilled:   sdk.NewUint(1),
+			QtyUnfilled: sdk.NewUint(0),
+			BlockNumber: i + 1,
+			BlockTime:   i * 60,
+			Price:       sdk.NewUint(uint64(i + 1)),
+		})
+	}
+	querier := price.NewQuerier(keeper)
+
+	t.Run("should page through candles", func(t *testing.T) {
+		params := price.CandleQueryParams{
+			From:     time.Unix(0, 0),
+			To:       time.Unix(count*60, 0),
+			Interval: price.CandleInterval1M,
+		}
+		res := queryCandles(t, app.Ctx, querier, app.Cdc, params)
+		require.Equal(t, price.MaxCandles, len(res.Candles))
+		require.NotNil(t, res.NextFrom)
+		assert.Equal(t, int64(price.MaxCandles*60), res.NextFrom.Unix())
+
+		params.From = *res.NextFrom
+		res = queryCandles(t, app.Ctx, querier, app.Cdc, params)
+		require.Equal(t, 10, len(res.Candles))
+		assert.Nil(t, res.NextFrom)
+		testutil.AssertEqualUints(t, sdk.NewUint(count), res.Candles[9].Close)
+	})
+	t.Run("should respect the limit", func(t *testing.T) {
+		res := queryCandles(t, app.Ctx, querier, app.Cdc, price.CandleQueryParams{
+			From:     time.Unix(0, 0),
+			To:       time.Unix(count*60, 0),
+			Interval: price.CandleInterval1M,
+			Limit:    5,
+		})
+		require.Equal(t, 5, len(res.Candles))
+		assert.Equal(t, int64(300), res.NextFrom.Unix())
+	})
+	t.Run("should roll up longer intervals", func(t *testing.T) {
+		res := fetchResult(t, app.Ctx, querier, app.Cdc, 0, count*60, price.CandleInterval1D)
+		require.Equal(t, 1, len(res.Candles))
+		assert.Nil(t, res.NextFrom)
+		testutil.AssertEqualUints(t, sdk.NewUint(1), res.Candles[0].Open)
+		testutil.AssertEqualUints(t, sdk.NewUint(count), res.Candles[0].High)
+		testutil.AssertEqualUints(t, sdk.NewUint(count), res.Candles[0].Volume)
+		assert.EqualValues(t, count, res.Candles[0].Trades)
+	})
+	t.Run("should reject invalid params", func(t *testing.T) {
+		for _, params := range []price.CandleQueryParams{
+			{From: time.Unix(0, 0), To: time.Unix(60, 0), Interval: "2m"},
+			{From: time.Unix(0, 0), To: time.Unix(60, 0), Interval: price.CandleInterval1M, Limit: -1},
+		} {
+			_, err := querier(app.Ctx, []string{"candles", "1"}, abci.RequestQuery{
+				Data: app.Cdc.MustMarshalBinaryBare(params),
+			})
+			assert.Error(t, err)
+		}
+	})
+}
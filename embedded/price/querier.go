@@ -19,6 +19,7 @@ const (
 	QueryCandles = "candles"
 	QueryDaily   = "daily"
 	MaxTicks     = 2000
+	MaxCandles   = 1000
 )
 
 func NewQuerier(keeper Keeper) sdk.Querier {
@@ -77,44 +78,34 @@ func queryCandles(path []string, data []byte, keeper Keeper) ([]byte, sdk.Error)
 		return nil, errs.ErrInvalidArgument("from cannot be after to")
 	}
 
+	if _, ok := validIntervals[string(params.Interval)]; !ok {
+		return nil, errs.ErrInvalidArgument("invalid interval")
+	}
+	if params.Limit < 0 {
+		return nil, errs.ErrInvalidArgument("limit cannot be negative")
+	}
+	limit := params.Limit
+	if limit == 0 || limit > MaxCandles {
+		limit = MaxCandles
+	}
+
 	res := CandleQueryResult{
 		MarketID: mktID,
+		Candles:  make([]CandleEntry, 0),
 	}
 
-	delta := params.Interval.Delta()
-	keeper.IteratorByMarketAndInterval(mktID, params.From, params.To, func(tick Tick) bool {
-		if res.Pair == "" {
-			res.Pair = tick.Pair
+	from := roundTime(params.From, params.Interval)
+	keeper.CandleIterator(mktID, params.Interval, from, params.To, func(candle Candle) bool {
+		if len(res.Candles) == limit {
+			next := time.Unix(candle.Start, 0).UTC()
+			res.NextFrom = &next
+			return false
 		}
-		if len(res.Candles) == 0 {
-			res.Candles = append(res.Candles, CandleEntry{
-				Date:  roundTime(time.Unix(tick.BlockTime, 0), params.Interval),
-				Open:  tick.Price,
-				High:  tick.Price,
-				Low:   tick.Price,
-				Close: tick.Price,
-			})
-		}
-		lastCandle := &res.Candles[len(res.Candles)-1]
-		if tick.BlockTime-lastCandle.Date.Unix() > delta {
-			res.Candles = append(res.Candles, CandleEntry{
-				Date:  roundTime(time.Unix(tick.BlockTime, 0), params.Interval),
-				Open:  tick.Price,
-				High:  tick.Price,
-				Low:   tick.Price,
-				Close: tick.Price,
-			})
-			lastCandle = &res.Candles[len(res.Candles)-1]
-		}
-		lastCandle.Close = tick.Price
-		if tick.Price.GT(lastCandle.High) {
-			lastCandle.High = tick.Price
-		}
-		if tick.Price.LT(lastCandle.Low) {
-			lastCandle.Low = tick.Price
+		if res.Pair == "" {
+			res.Pair = candle.Pair
 		}
-
-		return len(res.Candles) < MaxTicks
+		res.Candles = append(res.Candles, candle.Entry())
+		return true
 	})
 
 	b, err := codec.MarshalJSONIndent(keeper.cdc, res)
@@ -128,12 +119,14 @@ func queryDaily(path []string, keeper Keeper) ([]byte, sdk.Error) {
 	mktID := store.NewEntityIDFromString(path[0])
 
 	res := DailyQueryResult{
-		Pair:   "",
-		Volume: sdk.ZeroUint(),
-		Change: sdk.ZeroDec(),
-		Last:   sdk.ZeroUint(),
-		High:   sdk.ZeroUint(),
-		Low:    sdk.ZeroUint(),
+		Pair:        "",
+		Volume:      sdk.ZeroUint(),
+		QuoteVolume: sdk.ZeroUint(),
+		Fees:        sdk.NewCoins(),
+		Change:      sdk.ZeroDec(),
+		Last:        sdk.ZeroUint(),
+		High:        sdk.ZeroUint(),
+		Low:         sdk.ZeroUint(),
 	}
 
 	now := time.Now()
@@ -151,6 +144,10 @@ func queryDaily(path []string, keeper Keeper) ([]byte, sdk.Error) {
 		if res.Low.IsZero() || res.Low.GT(tick.Price) {
 			res.Low = tick.Price
 		}
+		res.Volume = res.Volume.Add(tick.Volume)
+		res.QuoteVolume = res.QuoteVolume.Add(tick.QuoteVolume)
+		res.Trades += tick.Trades
+		res.Fees = res.Fees.Add(tick.Fees)
 		return true
 	})
 	if res.Pair == "" {
@@ -192,6 +189,13 @@ func roundTime(t time.Time, interval CandleInterval) time.Time {
 		return t.Truncate(30 * time.Minute)
 	case CandleInterval60M:
 		return t.Truncate(60 * time.Minute)
+	case CandleInterval4H:
+		return t.Truncate(4 * time.Hour)
+	case CandleInterval1D:
+		return t.Truncate(24 * time.Hour)
+	case CandleInterval1W:
+		// weeks start on Monday, like the zero time
+		return t.Truncate(7 * 24 * time.Hour)
 	default:
 		panic("invalid time interval")
 	}
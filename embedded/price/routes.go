@@ -3,6 +3,7 @@ package price
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/xar-network/xar-network/embedded"
@@ -57,6 +58,14 @@ func candlesHandler(ctx context.CLIContext, cdc *codec.Codec) http.HandlerFunc {
 			}
 			params.Interval = cInterval
 		}
+		if limit, ok := q["limit"]; ok {
+			l, err := strconv.Atoi(limit[0])
+			if err != nil || l < 0 {
+				rest.WriteErrorResponse(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+			params.Limit = l
+		}
 
 		ctx, ok := rest.ParseQueryHeightOrReturnBadRequest(w, ctx, r)
 		if !ok {
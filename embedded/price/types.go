@@ -15,12 +15,20 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+// Tick is the last price of a market at a block, along with the volume
+// traded in that block. Volumes and trades count the bid side of each fill
+// only, so that every match is counted once. Fees are those charged to both
+// sides.
 type Tick struct {
 	MarketID    store.EntityID
 	Pair        string
 	BlockNumber int64
 	BlockTime   int64
 	Price       sdk.Uint
+	Volume      sdk.Uint
+	QuoteVolume sdk.Uint
+	Trades      uint64
+	Fees        sdk.Coins
 }
 
 type TickEntry struct {
@@ -63,14 +71,32 @@ const (
 	CandleInterval15M                = "15m"
 	CandleInterval30M                = "30m"
 	CandleInterval60M                = "60m"
+	CandleInterval4H                 = "4h"
+	CandleInterval1D                 = "1d"
+	CandleInterval1W                 = "1w"
 )
 
+// CandleIntervals lists every supported interval, shortest first.
+var CandleIntervals = []CandleInterval{
+	CandleInterval1M,
+	CandleInterval5M,
+	CandleInterval15M,
+	CandleInterval30M,
+	CandleInterval60M,
+	CandleInterval4H,
+	CandleInterval1D,
+	CandleInterval1W,
+}
+
 var validIntervals = map[string]CandleInterval{
 	"1m":  CandleInterval1M,
 	"5m":  CandleInterval5M,
 	"15m": CandleInterval15M,
 	"30m": CandleInterval30M,
 	"60m": CandleInterval60M,
+	"4h":  CandleInterval4H,
+	"1d":  CandleInterval1D,
+	"1w":  CandleInterval1W,
 }
 
 func NewCandleIntervalFromString(in string) (CandleInterval, error) {
@@ -94,6 +120,12 @@ func (c CandleInterval) Delta() int64 {
 		return 1800
 	case CandleInterval60M:
 		return 3600
+	case CandleInterval4H:
+		return 14400
+	case CandleInterval1D:
+		return 86400
+	case CandleInterval1W:
+		return 604800
 	default:
 		panic("invalid candle interval")
 	}
@@ -115,31 +147,77 @@ func (c *CandleInterval) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// CandleQueryParams selects the candles starting between From and To. At
+// most Limit candles are returned, or MaxCandles if Limit is zero.
 type CandleQueryParams struct {
 	From     time.Time
 	To       time.Time
 	Interval CandleInterval
+	Limit    int
 }
 
+// CandleQueryResult holds a page of candles. When more candles are in range,
+// NextFrom is the start of the next one and can be used as the From of the
+// following query.
 type CandleQueryResult struct {
 	MarketID store.EntityID `json:"market_id"`
 	Pair     string         `json:"pair"`
 	Candles  []CandleEntry  `json:"candles"`
+	NextFrom *time.Time     `json:"next_from,omitempty"`
+}
+
+// Candle is a candle as maintained by the keeper for each interval.
+type Candle struct {
+	MarketID    store.EntityID
+	Pair        string
+	Start       int64
+	Open        sdk.Uint
+	Close       sdk.Uint
+	High        sdk.Uint
+	Low         sdk.Uint
+	Volume      sdk.Uint
+	QuoteVolume sdk.Uint
+	Trades      uint64
+	Fees        sdk.Coins
+}
+
+func (c Candle) Entry() CandleEntry {
+	return CandleEntry{
+		Date:        time.Unix(c.Start, 0).UTC(),
+		Open:        c.Open,
+		Close:       c.Close,
+		High:        c.High,
+		Low:         c.Low,
+		Volume:      c.Volume,
+		QuoteVolume: c.QuoteVolume,
+		Trades:      c.Trades,
+		Fees:        c.Fees,
+	}
 }
 
+// CandleEntry holds the prices of a candle. Volume is in base asset units,
+// QuoteVolume in quote asset units. Fees are the trading fees charged within
+// the candle.
 type CandleEntry struct {
-	Date  time.Time `json:"date"`
-	Open  sdk.Uint  `json:"open"`
-	Close sdk.Uint  `json:"close"`
-	High  sdk.Uint  `json:"high"`
-	Low   sdk.Uint  `json:"low"`
+	Date        time.Time `json:"date"`
+	Open        sdk.Uint  `json:"open"`
+	Close       sdk.Uint  `json:"close"`
+	High        sdk.Uint  `json:"high"`
+	Low         sdk.Uint  `json:"low"`
+	Volume      sdk.Uint  `json:"volume"`
+	QuoteVolume sdk.Uint  `json:"quote_volume"`
+	Trades      uint64    `json:"trades"`
+	Fees        sdk.Coins `json:"fees"`
 }
 
 type DailyQueryResult struct {
-	Pair   string   `json:"pair"`
-	Volume sdk.Uint `json:"volume"`
-	Change sdk.Dec  `json:"change"`
-	Last   sdk.Uint `json:"last"`
-	High   sdk.Uint `json:"high"`
-	Low    sdk.Uint `json:"low"`
+	Pair        string    `json:"pair"`
+	Volume      sdk.Uint  `json:"volume"`
+	QuoteVolume sdk.Uint  `json:"quote_volume"`
+	Trades      uint64    `json:"trades"`
+	Fees        sdk.Coins `json:"fees"`
+	Change      sdk.Dec   `json:"change"`
+	Last        sdk.Uint  `json:"last"`
+	High        sdk.Uint  `json:"high"`
+	Low         sdk.Uint  `json:"low"`
 }
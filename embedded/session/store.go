@@ -1,15 +1,131 @@
 package session
 
 import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 )
 
-const sessionName = "uex_session"
+const (
+	sessionName = "uex_session"
+
+	hashKeyLen  = 64
+	blockKeyLen = 32
+)
+
+// SessionStore signs and encrypts session cookies. Until Init is called it
+// uses random keys, so sessions do not survive a restart.
+var SessionStore = sessions.NewCookieStore(generateKeyPair()...)
+
+// Config configures the session cookies.
+type Config struct {
+	// KeyFile holds the keys sessions are signed and encrypted with, one
+	// pair per line. The first pair signs new cookies and the others are
+	// only used to read cookies signed before a rotation.
+	KeyFile  string
+	MaxAge   int
+	Secure   bool
+	HTTPOnly bool
+	SameSite http.SameSite
+}
+
+// Init sets up the session store from a config, generating and persisting a
+// key file if none exists yet.
+func Init(cfg Config) error {
+	keys, err := LoadKeyFile(cfg.KeyFile)
+	if os.IsNotExist(err) {
+		keys, err = RotateKeyFile(cfg.KeyFile, 0)
+	}
+	if err != nil {
+		return err
+	}
 
-var SessionStore = sessions.NewCookieStore(generateSessionKey())
+	store := sessions.NewCookieStore(keys...)
+	store.Options = &sessions.Options{
+		Path:     "/",
+		MaxAge:   cfg.MaxAge,
+		Secure:   cfg.Secure,
+		HttpOnly: cfg.HTTPOnly,
+		SameSite: cfg.SameSite,
+	}
+	store.MaxAge(cfg.MaxAge)
+	SessionStore = store
+	return nil
+}
+
+// LoadKeyFile reads the key pairs in a key file, newest first.
+func LoadKeyFile(path string) ([][]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys [][]byte
+	for i, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		parts := strings.Fields(line)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid session key on line %d", i+1)
+		}
+		hashKey, err := hex.DecodeString(parts[0])
+		if err != nil || len(hashKey) != hashKeyLen {
+			return nil, fmt.Errorf("invalid session hash key on line %d", i+1)
+		}
+		blockKey, err := hex.DecodeString(parts[1])
+		if err != nil || len(blockKey) != blockKeyLen {
+			return nil, fmt.Errorf("invalid session block key on line %d", i+1)
+		}
+		keys = append(keys, hashKey, blockKey)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no session keys found in %s", path)
+	}
+	return keys, nil
+}
+
+// RotateKeyFile adds a new key pair to the front of a key file, creating it
+// if needed, and keeps at most keep of the previous pairs. It returns the
+// resulting key pairs.
+func RotateKeyFile(path string, keep int) ([][]byte, error) {
+	var old [][]byte
+	if _, err := os.Stat(path); err == nil {
+		old, err = LoadKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(old) > keep*2 {
+		old = old[:keep*2]
+	}
+	keys := append(generateKeyPair(), old...)
+
+	var buf bytes.Buffer
+	for i := 0; i < len(keys); i += 2 {
+		buf.WriteString(fmt.Sprintf("%s %s\n", hex.EncodeToString(keys[i]), hex.EncodeToString(keys[i+1])))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
 
-// TODO: pull from config
-func generateSessionKey() []byte {
-	var out [32]byte
-	return out[:]
+func generateKeyPair() [][]byte {
+	return [][]byte{
+		securecookie.GenerateRandomKey(hashKeyLen),
+		securecookie.GenerateRandomKey(blockKeyLen),
+	}
 }
@@ -0,0 +1,92 @@
+package session
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xar-network/xar-network/testutil/testflags"
+)
+
+func TestInit(t *testing.T) {
+	testflags.UnitTest(t)
+	dir, err := ioutil.TempDir("", "session")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "config", "session_keys")
+	cfg := Config{
+		KeyFile:  keyFile,
+		MaxAge:   3600,
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	}
+
+	// saves a value in a session and returns the cookie
+	save := func() *http.Cookie {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		require.NoError(t, SetStrings(w, r, "foo", "bar"))
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		return cookies[0]
+	}
+	load := func(cookie *http.Cookie) (string, error) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.AddCookie(cookie)
+		return GetStr(r, "foo")
+	}
+
+	t.Run("generates and persists keys on first start", func(t *testing.T) {
+		require.NoError(t, Init(cfg))
+		keys, err := LoadKeyFile(keyFile)
+		require.NoError(t, err)
+		assert.Len(t, keys, 2)
+
+		cookie := save()
+		assert.True(t, cookie.Secure)
+		assert.True(t, cookie.HttpOnly)
+		assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+		assert.Equal(t, 3600, cookie.MaxAge)
+
+		// a restart keeps accepting existing sessions
+		require.NoError(t, Init(cfg))
+		val, err := load(cookie)
+		require.NoError(t, err)
+		assert.Equal(t, "bar", val)
+	})
+
+	t.Run("accepts previous keys after a rotation", func(t *testing.T) {
+		cookie := save()
+		_, err := RotateKeyFile(keyFile, 1)
+		require.NoError(t, err)
+		require.NoError(t, Init(cfg))
+		val, err := load(cookie)
+		require.NoError(t, err)
+		assert.Equal(t, "bar", val)
+
+		// the key the cookie was signed with is dropped
+		_, err = RotateKeyFile(keyFile, 1)
+		require.NoError(t, err)
+		require.NoError(t, Init(cfg))
+		keys, err := LoadKeyFile(keyFile)
+		require.NoError(t, err)
+		assert.Len(t, keys, 4)
+		_, err = load(cookie)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects invalid key files", func(t *testing.T) {
+		badFile := filepath.Join(dir, "bad_keys")
+		require.NoError(t, ioutil.WriteFile(badFile, []byte("abcd efgh\n"), 0600))
+		assert.Error(t, Init(Config{KeyFile: badFile}))
+		_, err := LoadKeyFile(badFile)
+		assert.Error(t, err)
+	})
+}
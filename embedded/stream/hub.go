@@ -0,0 +1,333 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/xar-network/xar-network/pkg/log"
+	"github.com/xar-network/xar-network/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const sendBufferSize = 256
+
+// BookFetcher returns the current book of a market.
+type BookFetcher func(marketID string) (BookUpdate, error)
+
+// Hub fans queue events out to the clients subscribed to the channels they
+// affect. It is a types.EventHandler, so it can be fed by any consumer of
+// the event queue.
+type Hub struct {
+	cdc       *codec.Codec
+	fetchBook BookFetcher
+
+	mtx    sync.Mutex
+	topics map[string]map[*client]bool
+	dirty  map[string]bool
+	ticks  map[string]TickUpdate
+	lgr    tmlog.Logger
+}
+
+type client struct {
+	send   chan []byte
+	owner  sdk.AccAddress
+	topics map[string]bool
+	closed bool
+}
+
+func newClient(owner sdk.AccAddress) *client {
+	return &client{
+		send:   make(chan []byte, sendBufferSize),
+		owner:  owner,
+		topics: make(map[string]bool),
+	}
+}
+
+func NewHub(cdc *codec.Codec, fetchBook BookFetcher) *Hub {
+	return &Hub{
+		cdc:       cdc,
+		fetchBook: fetchBook,
+		topics:    make(map[string]map[*client]bool),
+		dirty:     make(map[string]bool),
+		ticks:     make(map[string]TickUpdate),
+		lgr:       log.WithModule("stream-hub"),
+	}
+}
+
+func (h *Hub) OnEvent(event interface{}) error {
+	switch ev := event.(type) {
+	case types.Batch:
+		h.publish(marketTopic(ChannelBatches, ev.MarketID.String()), BatchUpdate{
+			BlockNumber:   ev.BlockNumber,
+			BlockTime:     ev.BlockTime.Unix(),
+			ClearingPrice: ev.ClearingPrice,
+			AggSupply:     ev.AggSupply,
+			AggDemand:     ev.AggDemand,
+			ProRata:       ev.ProRata,
+			FillCount:     len(ev.Fills),
+		})
+	case types.Fill:
+		h.onFill(ev)
+	case types.OrderCreated:
+		h.markDirty(ev.MarketID.String())
+		h.publish(ownerTopic(ev.Owner), OrderUpdate{
+			OrderID:     ev.ID,
+			MarketID:    ev.MarketID,
+			Status:      "OPEN",
+			Price:       ev.Price,
+			Quantity:    ev.Quantity,
+			QtyFilled:   sdk.ZeroUint(),
+			BlockNumber: ev.CreatedBlock,
+		})
+	case types.OrderAmended:
+		h.markDirty(ev.MarketID.String())
+		h.publish(ownerTopic(ev.Owner), OrderUpdate{
+			OrderID:   ev.OrderID,
+			MarketID:  ev.MarketID,
+			Status:    "AMENDED",
+			Price:     ev.Price,
+			Quantity:  ev.Quantity,
+			QtyFilled: sdk.ZeroUint(),
+		})
+	case types.OrderCancelled:
+		h.markDirty(ev.MarketID.String())
+		h.publish(ownerTopic(ev.Owner), OrderUpdate{
+			OrderID:   ev.OrderID,
+			MarketID:  ev.MarketID,
+			Status:    "CANCELLED",
+			Price:     sdk.ZeroUint(),
+			Quantity:  sdk.ZeroUint(),
+			QtyFilled: sdk.ZeroUint(),
+		})
+	}
+
+	return nil
+}
+
+func (h *Hub) onFill(ev types.Fill) {
+	mktID := ev.MarketID.String()
+	h.markDirty(mktID)
+	h.publish(marketTopic(ChannelFills, mktID), FillUpdate{
+		OrderID:     ev.OrderID,
+		Owner:       ev.Owner,
+		Pair:        ev.Pair,
+		Direction:   ev.Direction,
+		QtyFilled:   ev.QtyFilled,
+		QtyUnfilled: ev.QtyUnfilled,
+		BlockNumber: ev.BlockNumber,
+		BlockTime:   ev.BlockTime,
+		Price:       ev.Price,
+		Maker:       ev.Maker,
+	})
+
+	status := "OPEN"
+	if ev.QtyUnfilled.IsZero() {
+		status = "FILLED"
+	}
+	h.publish(ownerTopic(ev.Owner), OrderUpdate{
+		OrderID:     ev.OrderID,
+		MarketID:    ev.MarketID,
+		Status:      status,
+		Price:       ev.Price,
+		Quantity:    ev.QtyUnfilled,
+		QtyFilled:   ev.QtyFilled,
+		BlockNumber: ev.BlockNumber,
+	})
+
+	// every fill of a batch shares its clearing price, so only publish a
+	// tick when the price or block changes
+	tick := TickUpdate{
+		Pair:        ev.Pair,
+		BlockNumber: ev.BlockNumber,
+		Timestamp:   ev.BlockTime,
+		Price:       ev.Price,
+	}
+	h.mtx.Lock()
+	last, ok := h.ticks[mktID]
+	h.ticks[mktID] = tick
+	h.mtx.Unlock()
+	if ok && last.BlockNumber == tick.BlockNumber && last.Price.Equal(tick.Price) {
+		return
+	}
+	h.publish(marketTopic(ChannelTicks, mktID), tick)
+}
+
+// Run publishes the book of every market that changed since the last
+// interval, until the quit channel is closed.
+func (h *Hub) Run(interval time.Duration, quitCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-quitCh:
+			return
+		case <-ticker.C:
+			h.publishBooks()
+		}
+	}
+}
+
+func (h *Hub) publishBooks() {
+	h.mtx.Lock()
+	var mktIDs []string
+	for mktID := range h.dirty {
+		if len(h.topics[marketTopic(ChannelBook, mktID)]) > 0 {
+			mktIDs = append(mktIDs, mktID)
+		}
+	}
+	h.dirty = make(map[string]bool)
+	h.mtx.Unlock()
+
+	for _, mktID := range mktIDs {
+		book, err := h.fetchBook(mktID)
+		if err != nil {
+			h.lgr.Error("error fetching book", "market_id", mktID, "err", err.Error())
+			continue
+		}
+		h.publish(marketTopic(ChannelBook, mktID), book)
+	}
+}
+
+func (h *Hub) markDirty(mktID string) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.dirty[mktID] = true
+}
+
+func (h *Hub) publish(topic string, data interface{}) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	clients := h.topics[topic]
+	if len(clients) == 0 {
+		return
+	}
+
+	dataB, err := h.cdc.MarshalJSON(data)
+	if err != nil {
+		h.lgr.Error("error marshalling update", "err", err.Error())
+		return
+	}
+	channel, mktID := splitTopic(topic)
+	if channel == ChannelOrders {
+		mktID = ""
+	}
+	msgB, err := json.Marshal(Message{
+		Channel:  channel,
+		MarketID: mktID,
+		Data:     dataB,
+	})
+	if err != nil {
+		h.lgr.Error("error marshalling message", "err", err.Error())
+		return
+	}
+
+	for c := range clients {
+		h.sendLocked(c, msgB)
+	}
+}
+
+// handle applies a subscription request from a client.
+func (h *Hub) handle(c *client, req Request) error {
+	var topic string
+	switch {
+	case req.Channel == ChannelOrders:
+		if c.owner.Empty() {
+			return errors.New("login required")
+		}
+		topic = ownerTopic(c.owner)
+	case marketChannels[req.Channel]:
+		if req.MarketID == "" {
+			return errors.New("market_id required")
+		}
+		topic = marketTopic(req.Channel, req.MarketID)
+	default:
+		return errors.New("unknown channel")
+	}
+
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if c.closed {
+		return nil
+	}
+	switch req.Type {
+	case RequestSubscribe:
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*client]bool)
+		}
+		h.topics[topic][c] = true
+		c.topics[topic] = true
+	case RequestUnsubscribe:
+		h.unsubscribeLocked(c, topic)
+	default:
+		return errors.New("unknown request type")
+	}
+	return nil
+}
+
+func (h *Hub) sendError(c *client, err error) {
+	msgB, _ := json.Marshal(Message{Error: err.Error()})
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.sendLocked(c, msgB)
+}
+
+// sendLocked queues a message for a client, dropping clients that cannot
+// keep up.
+func (h *Hub) sendLocked(c *client, msgB []byte) {
+	if c.closed {
+		return
+	}
+	select {
+	case c.send <- msgB:
+	default:
+		h.removeLocked(c)
+	}
+}
+
+func (h *Hub) remove(c *client) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.removeLocked(c)
+}
+
+func (h *Hub) removeLocked(c *client) {
+	if c.closed {
+		return
+	}
+	for topic := range c.topics {
+		h.unsubscribeLocked(c, topic)
+	}
+	c.closed = true
+	close(c.send)
+}
+
+func (h *Hub) unsubscribeLocked(c *client, topic string) {
+	delete(c.topics, topic)
+	delete(h.topics[topic], c)
+	if len(h.topics[topic]) == 0 {
+		delete(h.topics, topic)
+	}
+}
+
+func marketTopic(channel string, mktID string) string {
+	return channel + "/" + mktID
+}
+
+func ownerTopic(owner sdk.AccAddress) string {
+	return ChannelOrders + "/" + owner.String()
+}
+
+func splitTopic(topic string) (string, string) {
+	parts := strings.SplitN(topic, "/", 2)
+	return parts[0], parts[1]
+}
@@ -0,0 +1,178 @@
+package stream
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/testutil"
+	"github.com/xar-network/xar-network/types"
+	"github.com/xar-network/xar-network/types/store"
+	ordertypes "github.com/xar-network/xar-network/x/order/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func recv(t *testing.T, c *client) Message {
+	select {
+	case msgB := <-c.send:
+		var msg Message
+		require.NoError(t, json.Unmarshal(msgB, &msg))
+		return msg
+	default:
+		require.FailNow(t, "no message received")
+		return Message{}
+	}
+}
+
+func assertNoMessage(t *testing.T, c *client) {
+	select {
+	case msgB := <-c.send:
+		assert.FailNow(t, "unexpected message", string(msgB))
+	default:
+	}
+}
+
+func testFill(owner sdk.AccAddress, block int64, unfilled uint64) types.Fill {
+	return types.Fill{
+		OrderID:     store.NewEntityID(1),
+		MarketID:    store.NewEntityID(1),
+		Owner:       owner,
+		Pair:        "XAR/UCSDT",
+		Direction:   matcheng.Bid,
+		QtyFilled:   sdk.NewUint(10),
+		QtyUnfilled: sdk.NewUint(unfilled),
+		BlockNumber: block,
+		BlockTime:   block * 10,
+		Price:       sdk.NewUint(100),
+	}
+}
+
+func TestHub(t *testing.T) {
+	owner := testutil.RandAddr()
+	var fetched []string
+	h := NewHub(codec.New(), func(mktID string) (BookUpdate, error) {
+		fetched = append(fetched, mktID)
+		return BookUpdate{BlockNumber: 1}, nil
+	})
+
+	t.Run("should reject invalid subscriptions", func(t *testing.T) {
+		anon := newClient(nil)
+		assert.Error(t, h.handle(anon, Request{Type: RequestSubscribe, Channel: ChannelOrders}))
+		assert.Error(t, h.handle(anon, Request{Type: RequestSubscribe, Channel: ChannelFills}))
+		assert.Error(t, h.handle(anon, Request{Type: RequestSubscribe, Channel: "foo", MarketID: "1"}))
+		assert.Error(t, h.handle(anon, Request{Type: "foo", Channel: ChannelFills, MarketID: "1"}))
+	})
+
+	t.Run("should route fills to market and owner channels", func(t *testing.T) {
+		c := newClient(owner)
+		other := newClient(testutil.RandAddr())
+		require.NoError(t, h.handle(c, Request{Type: RequestSubscribe, Channel: ChannelFills, MarketID: "1"}))
+		require.NoError(t, h.handle(c, Request{Type: RequestSubscribe, Channel: ChannelOrders}))
+		require.NoError(t, h.handle(other, Request{Type: RequestSubscribe, Channel: ChannelOrders}))
+		require.NoError(t, h.handle(other, Request{Type: RequestSubscribe, Channel: ChannelFills, MarketID: "2"}))
+
+		require.NoError(t, h.OnEvent(testFill(owner, 1, 0)))
+		msg := recv(t, c)
+		assert.Equal(t, ChannelFills, msg.Channel)
+		assert.Equal(t, "1", msg.MarketID)
+		var fill FillUpdate
+		require.NoError(t, codec.New().UnmarshalJSON(msg.Data, &fill))
+		assert.Equal(t, int64(1), fill.BlockNumber)
+		testutil.AssertEqualUints(t, sdk.NewUint(10), fill.QtyFilled)
+
+		msg = recv(t, c)
+		assert.Equal(t, ChannelOrders, msg.Channel)
+		assert.Empty(t, msg.MarketID)
+		var upd OrderUpdate
+		require.NoError(t, codec.New().UnmarshalJSON(msg.Data, &upd))
+		assert.Equal(t, "FILLED", upd.Status)
+		assertNoMessage(t, c)
+		assertNoMessage(t, other)
+
+		h.remove(c)
+		h.remove(other)
+	})
+
+	t.Run("should only publish ticks when the price changes", func(t *testing.T) {
+		c := newClient(nil)
+		require.NoError(t, h.handle(c, Request{Type: RequestSubscribe, Channel: ChannelTicks, MarketID: "1"}))
+
+		require.NoError(t, h.OnEvent(testFill(owner, 2, 10)))
+		assert.Equal(t, ChannelTicks, recv(t, c).Channel)
+		require.NoError(t, h.OnEvent(testFill(owner, 2, 0)))
+		assertNoMessage(t, c)
+		require.NoError(t, h.OnEvent(testFill(owner, 3, 0)))
+		assert.Equal(t, ChannelTicks, recv(t, c).Channel)
+
+		require.NoError(t, h.handle(c, Request{Type: RequestUnsubscribe, Channel: ChannelTicks, MarketID: "1"}))
+		require.NoError(t, h.OnEvent(testFill(owner, 4, 0)))
+		assertNoMessage(t, c)
+	})
+
+	t.Run("should publish changed books to subscribers", func(t *testing.T) {
+		// markets that changed while nobody was subscribed are skipped
+		h.publishBooks()
+		assert.Empty(t, fetched)
+
+		c := newClient(nil)
+		require.NoError(t, h.handle(c, Request{Type: RequestSubscribe, Channel: ChannelBook, MarketID: "1"}))
+		h.publishBooks()
+		assert.Empty(t, fetched)
+
+		require.NoError(t, h.OnEvent(types.OrderCancelled{
+			OrderID:  store.NewEntityID(2),
+			Owner:    owner,
+			MarketID: store.NewEntityID(1),
+		}))
+		require.NoError(t, h.OnEvent(types.OrderCancelled{
+			OrderID:  store.NewEntityID(3),
+			Owner:    owner,
+			MarketID: store.NewEntityID(2),
+		}))
+		h.publishBooks()
+		assert.Equal(t, []string{"1"}, fetched)
+		assert.Equal(t, ChannelBook, recv(t, c).Channel)
+
+		h.publishBooks()
+		assert.Equal(t, []string{"1"}, fetched)
+	})
+
+	t.Run("should drop clients that fall behind", func(t *testing.T) {
+		c := newClient(owner)
+		require.NoError(t, h.handle(c, Request{Type: RequestSubscribe, Channel: ChannelOrders}))
+		for i := 0; i <= sendBufferSize; i++ {
+			require.NoError(t, h.OnEvent(types.OrderCancelled{
+				OrderID:  store.NewEntityID(uint64(i)),
+				Owner:    owner,
+				MarketID: store.NewEntityID(1),
+			}))
+		}
+		assert.True(t, c.closed)
+		assert.Empty(t, h.topics[ownerTopic(owner)])
+	})
+}
+
+func TestNewBookUpdate(t *testing.T) {
+	order := func(price uint64, qty uint64) ordertypes.Order {
+		return ordertypes.Order{Price: sdk.NewUint(price), Quantity: sdk.NewUint(qty)}
+	}
+	upd := NewBookUpdate(ordertypes.BookQueryResult{
+		BlockNumber: 10,
+		Bids:        []ordertypes.Order{order(90, 1), order(95, 2), order(95, 3)},
+		Asks:        []ordertypes.Order{order(100, 1), order(100, 1), order(110, 4)},
+	})
+	assert.Equal(t, int64(10), upd.BlockNumber)
+	require.Len(t, upd.Bids, 2)
+	testutil.AssertEqualUints(t, sdk.NewUint(95), upd.Bids[0].Price)
+	testutil.AssertEqualUints(t, sdk.NewUint(5), upd.Bids[0].Quantity)
+	testutil.AssertEqualUints(t, sdk.NewUint(90), upd.Bids[1].Price)
+	require.Len(t, upd.Asks, 2)
+	testutil.AssertEqualUints(t, sdk.NewUint(100), upd.Asks[0].Price)
+	testutil.AssertEqualUints(t, sdk.NewUint(2), upd.Asks[0].Quantity)
+	testutil.AssertEqualUints(t, sdk.NewUint(110), upd.Asks[1].Price)
+}
@@ -0,0 +1,56 @@
+package stream
+
+import (
+	"strconv"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+
+	"github.com/xar-network/xar-network/types"
+	"github.com/xar-network/xar-network/types/errs"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	QueryHead   = "head"
+	QueryEvents = "events"
+
+	maxEventsPerQuery = 500
+)
+
+func NewQuerier(queue *types.LogBackend) sdk.Querier {
+	return func(ctx sdk.Context, path []string, req abci.RequestQuery) ([]byte, sdk.Error) {
+		switch path[0] {
+		case QueryHead:
+			return queryHead(queue)
+		case QueryEvents:
+			return queryEvents(path[1:], queue)
+		default:
+			return nil, sdk.ErrUnknownRequest("unknown stream query endpoint")
+		}
+	}
+}
+
+func queryHead(queue *types.LogBackend) ([]byte, sdk.Error) {
+	return []byte(strconv.FormatUint(queue.Head(), 10)), nil
+}
+
+func queryEvents(path []string, queue *types.LogBackend) ([]byte, sdk.Error) {
+	if len(path) != 1 {
+		return nil, errs.ErrInvalidArgument("must specify an offset")
+	}
+
+	from, err := strconv.ParseUint(path[0], 10, 64)
+	if err != nil {
+		return nil, errs.ErrInvalidArgument("invalid offset")
+	}
+	events, next := queue.Range(from, maxEventsPerQuery)
+	b, err := types.EventCdc.MarshalBinaryBare(EventsQueryResult{
+		Events: events,
+		Next:   next,
+	})
+	if err != nil {
+		return nil, errs.ErrMarshalFailure("failed to marshal events")
+	}
+	return b, nil
+}
@@ -0,0 +1,201 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/xar-network/xar-network/embedded/auth"
+	"github.com/xar-network/xar-network/types"
+	ordertypes "github.com/xar-network/xar-network/x/order/types"
+)
+
+const (
+	pollInterval = 500 * time.Millisecond
+	writeWait    = 10 * time.Second
+	pongWait     = 60 * time.Second
+	pingPeriod   = pongWait * 9 / 10
+	maxReadSize  = 1024
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: checkOrigin,
+}
+
+// allowedOrigins are the origins, besides the server's own, whose pages may
+// open the WebSocket. It is only set at startup.
+var allowedOrigins = make(map[string]bool)
+
+// SetAllowedOrigins sets the origins other than the server's own that pages
+// may open the WebSocket from. The socket carries the session cookie, so a
+// page on any other origin could read the orders of a logged in user.
+func SetAllowedOrigins(origins []string) {
+	allowedOrigins = make(map[string]bool)
+	for _, origin := range origins {
+		allowedOrigins[normalizeOrigin(origin)] = true
+	}
+}
+
+// checkOrigin accepts requests without an Origin header, which browsers
+// always send, from the server's own origin and from the allowed origins.
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if allowedOrigins[normalizeOrigin(origin)] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(origin, "/"))
+}
+
+// RegisterRoutes registers the WebSocket endpoint and starts following the
+// node's event queue.
+func RegisterRoutes(ctx context.CLIContext, r *mux.Router, cdc *codec.Codec) {
+	hub := NewHub(cdc, bookFetcher(ctx, cdc))
+	consumer := types.NewLocalConsumer(NewRemoteSource(ctx, pollInterval), []types.EventHandler{hub})
+	consumer.Start()
+	go hub.Run(pollInterval, make(chan struct{}))
+
+	r.HandleFunc("/ws", wsHandler(hub)).Methods("GET")
+}
+
+func wsHandler(hub *Hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// the orders channel is only available to logged in users
+		var owner sdk.AccAddress
+		if kb, err := auth.GetKBFromSession(r); err == nil && kb != nil {
+			owner = kb.GetAddr()
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// the upgrader has already written an error response
+			return
+		}
+
+		c := newClient(owner)
+		go writePump(conn, c)
+		readPump(conn, hub, c)
+	}
+}
+
+func readPump(conn *websocket.Conn, hub *Hub, c *client) {
+	defer func() {
+		hub.remove(c)
+		conn.Close()
+	}()
+
+	conn.SetReadLimit(maxReadSize)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req Request
+		if err := json.Unmarshal(msg, &req); err != nil {
+			hub.sendError(c, err)
+			continue
+		}
+		if err := hub.handle(c, req); err != nil {
+			hub.sendError(c, err)
+		}
+	}
+}
+
+func writePump(conn *websocket.Conn, c *client) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				_ = conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func bookFetcher(ctx context.CLIContext, cdc *codec.Codec) BookFetcher {
+	return func(mktID string) (BookUpdate, error) {
+		var res BookUpdate
+		resJSON, _, err := ctx.QueryWithData(fmt.Sprintf("custom/%s/book/%s", ordertypes.RouterKey, mktID), nil)
+		if err != nil {
+			return res, err
+		}
+
+		var book ordertypes.BookQueryResult
+		if err := cdc.UnmarshalJSON(resJSON, &book); err != nil {
+			return res, err
+		}
+		return NewBookUpdate(book), nil
+	}
+}
+
+// NewBookUpdate sums the open orders of a book by price. Bids are sorted
+// highest price first and asks lowest price first.
+func NewBookUpdate(book ordertypes.BookQueryResult) BookUpdate {
+	bids := levels(book.Bids)
+	sort.Slice(bids, func(i, j int) bool {
+		return bids[i].Price.GT(bids[j].Price)
+	})
+	return BookUpdate{
+		BlockNumber: book.BlockNumber,
+		Bids:        bids,
+		Asks:        levels(book.Asks),
+	}
+}
+
+// levels sums orders sorted by price into price levels.
+func levels(orders []ordertypes.Order) []BookLevel {
+	out := make([]BookLevel, 0)
+	for _, o := range orders {
+		if n := len(out); n > 0 && out[n-1].Price.Equal(o.Price) {
+			out[n-1].Quantity = out[n-1].Quantity.Add(o.Quantity)
+			continue
+		}
+		out = append(out, BookLevel{
+			Price:    o.Price,
+			Quantity: o.Quantity,
+		})
+	}
+	return out
+}
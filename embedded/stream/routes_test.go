@@ -0,0 +1,32 @@
+package stream
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	SetAllowedOrigins([]string{"https://App.example.com/"})
+	defer SetAllowedOrigins(nil)
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"", true},
+		{"http://localhost:1317", true},
+		{"https://app.example.com", true},
+		{"https://evil.example.com", false},
+		{"http://localhost:8080", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "http://localhost:1317/api/v1/ws", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		assert.Equal(t, tt.allowed, checkOrigin(r), tt.origin)
+	}
+}
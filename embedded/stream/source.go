@@ -0,0 +1,91 @@
+package stream
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	"github.com/xar-network/xar-network/pkg/log"
+	"github.com/xar-network/xar-network/types"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+)
+
+// RemoteSource is a types.Source that follows the node's event queue over
+// queries. It starts at the head of the queue, so only events committed
+// after it is created are consumed.
+type RemoteSource struct {
+	ctx      context.CLIContext
+	interval time.Duration
+	next     uint64
+	started  bool
+	buf      []types.Event
+	quitCh   chan struct{}
+	lgr      tmlog.Logger
+}
+
+func NewRemoteSource(ctx context.CLIContext, interval time.Duration) *RemoteSource {
+	return &RemoteSource{
+		ctx:      ctx,
+		interval: interval,
+		quitCh:   make(chan struct{}),
+		lgr:      log.WithModule("stream-source"),
+	}
+}
+
+// Consume blocks until the node has committed an event and returns it. It
+// returns nil once the source is closed.
+func (s *RemoteSource) Consume() interface{} {
+	for len(s.buf) == 0 {
+		if err := s.fetch(); err != nil {
+			s.lgr.Error("error fetching events", "err", err.Error())
+		}
+		if len(s.buf) > 0 {
+			break
+		}
+
+		select {
+		case <-s.quitCh:
+			return nil
+		case <-time.After(s.interval):
+		}
+	}
+
+	event := s.buf[0]
+	s.buf = s.buf[1:]
+	return event
+}
+
+// Close stops the source.
+func (s *RemoteSource) Close() {
+	close(s.quitCh)
+}
+
+func (s *RemoteSource) fetch() error {
+	if !s.started {
+		res, _, err := s.ctx.QueryWithData(fmt.Sprintf("custom/stream/%s", QueryHead), nil)
+		if err != nil {
+			return err
+		}
+		head, err := strconv.ParseUint(string(res), 10, 64)
+		if err != nil {
+			return err
+		}
+		s.next = head
+		s.started = true
+	}
+
+	res, _, err := s.ctx.QueryWithData(fmt.Sprintf("custom/stream/%s/%d", QueryEvents, s.next), nil)
+	if err != nil {
+		return err
+	}
+	var qRes EventsQueryResult
+	if err := types.EventCdc.UnmarshalBinaryBare(res, &qRes); err != nil {
+		return err
+	}
+	s.buf = qRes.Events
+	s.next = qRes.Next
+	return nil
+}
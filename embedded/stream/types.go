@@ -0,0 +1,114 @@
+package stream
+
+import (
+	"encoding/json"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/types"
+	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	// ChannelBatches carries the clearing results of a market's batches.
+	ChannelBatches = "batches"
+	// ChannelBook carries the price levels of a market's book whenever it
+	// changes.
+	ChannelBook = "book"
+	// ChannelFills carries every fill in a market.
+	ChannelFills = "fills"
+	// ChannelTicks carries a market's last traded price.
+	ChannelTicks = "ticks"
+	// ChannelOrders carries status changes of the logged in user's orders
+	// across all markets.
+	ChannelOrders = "orders"
+
+	RequestSubscribe   = "subscribe"
+	RequestUnsubscribe = "unsubscribe"
+)
+
+var marketChannels = map[string]bool{
+	ChannelBatches: true,
+	ChannelBook:    true,
+	ChannelFills:   true,
+	ChannelTicks:   true,
+}
+
+// Request is sent by clients to manage their subscriptions. MarketID is
+// required for every channel but orders.
+type Request struct {
+	Type     string `json:"type"`
+	Channel  string `json:"channel"`
+	MarketID string `json:"market_id,omitempty"`
+}
+
+// Message is sent to clients for every update on a subscribed channel, and
+// with an Error in response to invalid requests.
+type Message struct {
+	Channel  string          `json:"channel,omitempty"`
+	MarketID string          `json:"market_id,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+type BatchUpdate struct {
+	BlockNumber   int64    `json:"block_number"`
+	BlockTime     int64    `json:"block_time"`
+	ClearingPrice sdk.Uint `json:"clearing_price"`
+	AggSupply     sdk.Uint `json:"agg_supply"`
+	AggDemand     sdk.Uint `json:"agg_demand"`
+	ProRata       sdk.Dec  `json:"pro_rata"`
+	FillCount     int      `json:"fill_count"`
+}
+
+type FillUpdate struct {
+	OrderID     store.EntityID     `json:"order_id"`
+	Owner       sdk.AccAddress     `json:"owner"`
+	Pair        string             `json:"pair"`
+	Direction   matcheng.Direction `json:"direction"`
+	QtyFilled   sdk.Uint           `json:"qty_filled"`
+	QtyUnfilled sdk.Uint           `json:"qty_unfilled"`
+	BlockNumber int64              `json:"block_number"`
+	BlockTime   int64              `json:"block_time"`
+	Price       sdk.Uint           `json:"price"`
+	Maker       bool               `json:"maker"`
+}
+
+type TickUpdate struct {
+	Pair        string   `json:"pair"`
+	BlockNumber int64    `json:"block_number"`
+	Timestamp   int64    `json:"timestamp"`
+	Price       sdk.Uint `json:"price"`
+}
+
+type BookLevel struct {
+	Price    sdk.Uint `json:"price"`
+	Quantity sdk.Uint `json:"quantity"`
+}
+
+type BookUpdate struct {
+	BlockNumber int64       `json:"block_number"`
+	Bids        []BookLevel `json:"bids"`
+	Asks        []BookLevel `json:"asks"`
+}
+
+// OrderUpdate describes a change to one of the user's orders. Quantity is
+// the unfilled quantity after the change, and QtyFilled the quantity filled
+// by it.
+type OrderUpdate struct {
+	OrderID     store.EntityID `json:"order_id"`
+	MarketID    store.EntityID `json:"market_id"`
+	Status      string         `json:"status"`
+	Price       sdk.Uint       `json:"price"`
+	Quantity    sdk.Uint       `json:"quantity"`
+	QtyFilled   sdk.Uint       `json:"qty_filled"`
+	BlockNumber int64          `json:"block_number"`
+}
+
+// EventsQueryResult holds committed queue events and the offset to resume
+// from.
+type EventsQueryResult struct {
+	Events []types.Event
+	Next   uint64
+}
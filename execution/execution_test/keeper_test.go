@@ -13,8 +13,10 @@ import (
 	uexstore "github.com/xar-network/xar-network/types/store"
 	"github.com/xar-network/xar-network/x/denominations"
 	types2 "github.com/xar-network/xar-network/x/market/types"
+	types4 "github.com/xar-network/xar-network/x/order/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth"
 	"github.com/cosmos/cosmos-sdk/x/supply"
 )
 
@@ -105,3 +107,432 @@ func TestKeeper_ExecuteAndCancelExpired(t *testing.T) {
 		testutil.AssertEqualUints(t, testutil.ToBaseUnits(80), buyerAsset2Bal)
 	})
 }
+
+func TestKeeper_ExecuteImmediateOrders(t *testing.T) {
+	testflags.UnitTest(t)
+	balance := func(app *mockapp.MockApp, ctx sdk.Context, addr sdk.AccAddress, denom string) sdk.Uint {
+		return sdk.NewUintFromBigInt(app.BankKeeper.GetCoins(ctx, addr).AmountOf(denom).BigInt())
+	}
+
+	t.Run("refunds the unfilled remainder of an IOC order", func(t *testing.T) {
+		app, mktID, buyer, seller := setupImmediateTest(t)
+		_, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		ioc, err := app.OrderKeeper.PostWithType(app.Ctx, buyer, mktID, matcheng.ImmediateOrCancel, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(20), sdk.ZeroUint(), 0)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, ioc.ID))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(110), balance(app, app.Ctx, buyer, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(80), balance(app, app.Ctx, buyer, "tst2"))
+	})
+	t.Run("kills a FOK order that cannot be filled in full", func(t *testing.T) {
+		app, mktID, buyer, seller := setupImmediateTest(t)
+		ask, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		fok, err := app.OrderKeeper.PostWithType(app.Ctx, buyer, mktID, matcheng.FillOrKill, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(20), sdk.ZeroUint(), 0)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, fok.ID))
+		assert.True(t, app.OrderKeeper.Has(app.Ctx, ask.ID))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), balance(app, app.Ctx, buyer, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), balance(app, app.Ctx, buyer, "tst2"))
+	})
+	t.Run("fills a FOK order that can be filled in full", func(t *testing.T) {
+		app, mktID, buyer, seller := setupImmediateTest(t)
+		_, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(20), 100)
+		require.NoError(t, err)
+		fok, err := app.OrderKeeper.PostWithType(app.Ctx, buyer, mktID, matcheng.FillOrKill, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), sdk.ZeroUint(), 0)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, fok.ID))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(110), balance(app, app.Ctx, buyer, "tst1"))
+	})
+	t.Run("fills a market bid without spending more than max spend", func(t *testing.T) {
+		app, mktID, buyer, seller := setupImmediateTest(t)
+		_, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		mkt, err := app.OrderKeeper.PostWithType(app.Ctx, buyer, mktID, matcheng.Market, matcheng.Bid, sdk.ZeroUint(), testutil.ToBaseUnits(10), testutil.ToBaseUnits(30), 0)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, mkt.ID))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(110), balance(app, app.Ctx, buyer, "tst1"))
+		assert.True(t, balance(app, app.Ctx, buyer, "tst2").GTE(testutil.ToBaseUnits(70)))
+	})
+	t.Run("fills a market ask at the best bid", func(t *testing.T) {
+		app, mktID, buyer, seller := setupImmediateTest(t)
+		_, err := app.OrderKeeper.Post(app.Ctx, buyer, mktID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		mkt, err := app.OrderKeeper.PostWithType(app.Ctx, seller, mktID, matcheng.Market, matcheng.Ask, sdk.ZeroUint(), testutil.ToBaseUnits(10), sdk.ZeroUint(), 0)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, mkt.ID))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(90), balance(app, app.Ctx, seller, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(120), balance(app, app.Ctx, seller, "tst2"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(110), balance(app, app.Ctx, buyer, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(80), balance(app, app.Ctx, buyer, "tst2"))
+	})
+	t.Run("does not fill a market ask without bids", func(t *testing.T) {
+		app, mktID, _, seller := setupImmediateTest(t)
+		_, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		mkt, err := app.OrderKeeper.PostWithType(app.Ctx, seller, mktID, matcheng.Market, matcheng.Ask, sdk.ZeroUint(), testutil.ToBaseUnits(10), sdk.ZeroUint(), 0)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, mkt.ID))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(90), balance(app, app.Ctx, seller, "tst1"))
+	})
+	t.Run("does not fill an ask whose fill is worth less than a quote unit", func(t *testing.T) {
+		app, mktID, buyer, seller := setupImmediateTest(t)
+		bid, err := app.OrderKeeper.Post(app.Ctx, buyer, mktID, matcheng.Bid, sdk.OneUint(), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		ask, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, sdk.OneUint(), sdk.OneUint(), 100)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		resting, err := app.OrderKeeper.Get(app.Ctx, ask.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, sdk.OneUint(), resting.Quantity)
+		resting, err = app.OrderKeeper.Get(app.Ctx, bid.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(10), resting.Quantity)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), balance(app, app.Ctx, buyer, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), balance(app, app.Ctx, seller, "tst2"))
+	})
+	t.Run("fills the other asks of a batch with a dust ask", func(t *testing.T) {
+		app, mktID, buyer, seller := setupImmediateTest(t)
+		_, err := app.OrderKeeper.Post(app.Ctx, buyer, mktID, matcheng.Bid, sdk.OneUint(), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		dust, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, sdk.OneUint(), sdk.OneUint(), 100)
+		require.NoError(t, err)
+		ask, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, sdk.OneUint(), testutil.ToBaseUnits(1), 100)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.True(t, app.OrderKeeper.Has(app.Ctx, dust.ID))
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, ask.ID))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(101), balance(app, app.Ctx, buyer, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100).AddUint64(1), balance(app, app.Ctx, seller, "tst2"))
+	})
+}
+
+func setupImmediateTest(t *testing.T) (*mockapp.MockApp, uexstore.EntityID, sdk.AccAddress, sdk.AccAddress) {
+	app := mockapp.New(t)
+	nominee := testutil.RandAddr()
+	buyer := testutil.RandAddr()
+	seller := testutil.RandAddr()
+
+	app.SupplyKeeper.SetSupply(app.Ctx, supply.NewSupply(sdk.Coins{}))
+	marketParams := app.MarketKeeper.GetParams(app.Ctx)
+	marketParams.Nominees = []string{nominee.String()}
+	app.MarketKeeper.SetParams(app.Ctx, marketParams)
+
+	err := app.SupplyKeeper.MintCoins(app.Ctx, denominations.ModuleName, sdk.NewCoins(sdk.NewCoin("tst1", sdk.NewInt(1000000000000)), sdk.NewCoin("tst2", sdk.NewInt(1000000000000))))
+	require.NoError(t, err)
+	for _, addr := range []sdk.AccAddress{buyer, seller} {
+		require.NoError(t, app.SupplyKeeper.SendCoinsFromModuleToAccount(app.Ctx, denominations.ModuleName, addr, sdk.NewCoins(sdk.NewCoin("tst1", sdk.NewInt(10000000000)), sdk.NewCoin("tst2", sdk.NewInt(10000000000)))))
+	}
+	mkt, err := app.MarketKeeper.CreateMarket(app.Ctx, nominee.String(), "tst1", "tst2")
+	require.NoError(t, err)
+	return app, mkt.ID, buyer, seller
+}
+
+func TestKeeper_ExecuteConditionalOrders(t *testing.T) {
+	testflags.UnitTest(t)
+	app, mktID, buyer, seller := setupImmediateTest(t)
+
+	cond, err := app.OrderKeeper.PostConditional(app.Ctx, seller, mktID, matcheng.Ask, types4.StopLoss, types4.LastPrice, testutil.ToBaseUnits(2), matcheng.ImmediateOrCancel, testutil.ToBaseUnits(1), testutil.ToBaseUnits(5), sdk.ZeroUint(), 0)
+	require.NoError(t, err)
+	_, err = app.OrderKeeper.Post(app.Ctx, buyer, mktID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(15), 100)
+	require.NoError(t, err)
+	_, err = app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+	require.NoError(t, err)
+
+	require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+	t.Run("does not trigger before a batch clears at the trigger price", func(t *testing.T) {
+		assert.True(t, app.OrderKeeper.HasConditional(app.Ctx, cond.ID))
+	})
+
+	ctx := app.Ctx.WithBlockHeight(app.Ctx.BlockHeight() + 1)
+	require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(ctx))
+	t.Run("triggers and fills once a batch clears at the trigger price", func(t *testing.T) {
+		assert.False(t, app.OrderKeeper.HasConditional(ctx, cond.ID))
+		sellerBal := sdk.NewUintFromBigInt(app.BankKeeper.GetCoins(ctx, seller).AmountOf("tst1").BigInt())
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(85), sellerBal)
+		buyerBal := sdk.NewUintFromBigInt(app.BankKeeper.GetCoins(ctx, buyer).AmountOf("tst1").BigInt())
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(115), buyerBal)
+	})
+}
+
+func TestKeeper_ExecuteFillFees(t *testing.T) {
+	testflags.UnitTest(t)
+	app, mktID, buyer, seller := setupImmediateTest(t)
+	params := app.MarketKeeper.GetParams(app.Ctx)
+	params.Markets[mktID.Dec().Uint64()].MakerFeeBps = 10
+	params.Markets[mktID.Dec().Uint64()].TakerFeeBps = 20
+	app.MarketKeeper.SetParams(app.Ctx, params)
+
+	_, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+	require.NoError(t, err)
+	ctx := app.Ctx.WithBlockHeight(app.Ctx.BlockHeight() + 1)
+	_, err = app.OrderKeeper.Post(ctx, buyer, mktID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+	require.NoError(t, err)
+	require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(ctx))
+
+	balance := func(addr sdk.AccAddress, denom string) sdk.Uint {
+		return sdk.NewUintFromBigInt(app.BankKeeper.GetCoins(ctx, addr).AmountOf(denom).BigInt())
+	}
+	feeCollector := supply.NewModuleAddress(auth.FeeCollectorName)
+	t.Run("charges the taker fee on the base asset a bid receives", func(t *testing.T) {
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(10).MulUint64(9980).QuoUint64(10000).Add(testutil.ToBaseUnits(100)), balance(buyer, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(10).MulUint64(20).QuoUint64(10000), balance(feeCollector, "tst1"))
+	})
+	t.Run("charges the maker fee on the quote asset an ask receives", func(t *testing.T) {
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(20).MulUint64(9990).QuoUint64(10000).Add(testutil.ToBaseUnits(100)), balance(seller, "tst2"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(20).MulUint64(10).QuoUint64(10000), balance(feeCollector, "tst2"))
+	})
+}
+
+func TestKeeper_ExecuteSelfTradePrevention(t *testing.T) {
+	testflags.UnitTest(t)
+	setup := func(t *testing.T, mode matcheng.SelfTradePrevention) (*mockapp.MockApp, uexstore.EntityID, sdk.AccAddress) {
+		app, mktID, _, seller := setupImmediateTest(t)
+		params := app.MarketKeeper.GetParams(app.Ctx)
+		params.Markets[mktID.Dec().Uint64()].SelfTradePrevention = mode
+		app.MarketKeeper.SetParams(app.Ctx, params)
+		return app, mktID, seller
+	}
+	balance := func(app *mockapp.MockApp, addr sdk.AccAddress, denom string) sdk.Uint {
+		return sdk.NewUintFromBigInt(app.BankKeeper.GetCoins(app.Ctx, addr).AmountOf(denom).BigInt())
+	}
+
+	t.Run("cancels the newest order of a crossing pair", func(t *testing.T) {
+		app, mktID, owner := setup(t, matcheng.STPCancelNewest)
+		ask, err := app.OrderKeeper.Post(app.Ctx, owner, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		bid, err := app.OrderKeeper.Post(app.Ctx, owner, mktID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, bid.ID))
+		remaining, err := app.OrderKeeper.Get(app.Ctx, ask.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(10), remaining.Quantity)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(90), balance(app, owner, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), balance(app, owner, "tst2"))
+	})
+	t.Run("decrements both orders by the smaller quantity", func(t *testing.T) {
+		app, mktID, owner := setup(t, matcheng.STPDecrementBoth)
+		ask, err := app.OrderKeeper.Post(app.Ctx, owner, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		bid, err := app.OrderKeeper.Post(app.Ctx, owner, mktID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(4), 100)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, bid.ID))
+		remaining, err := app.OrderKeeper.Get(app.Ctx, ask.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(6), remaining.Quantity)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(94), balance(app, owner, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), balance(app, owner, "tst2"))
+	})
+	t.Run("keeps a remainder too small to represent", func(t *testing.T) {
+		app, mktID, owner := setup(t, matcheng.STPDecrementBoth)
+		ask, err := app.OrderKeeper.Post(app.Ctx, owner, mktID, matcheng.Ask, sdk.NewUint(3), sdk.NewUint(100000000), 100)
+		require.NoError(t, err)
+		bid, err := app.OrderKeeper.Post(app.Ctx, owner, mktID, matcheng.Bid, sdk.NewUint(3), sdk.NewUint(100000001), 100)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, ask.ID))
+		remaining, err := app.OrderKeeper.Get(app.Ctx, bid.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, sdk.OneUint(), remaining.Quantity)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), balance(app, owner, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), balance(app, owner, "tst2"))
+	})
+	t.Run("keeps a reduced remainder that the batch still matches", func(t *testing.T) {
+		app, mktID, other, owner := setupImmediateTest(t)
+		params := app.MarketKeeper.GetParams(app.Ctx)
+		params.Markets[mktID.Dec().Uint64()].SelfTradePrevention = matcheng.STPDecrementBoth
+		app.MarketKeeper.SetParams(app.Ctx, params)
+		bid, err := app.OrderKeeper.Post(app.Ctx, owner, mktID, matcheng.Bid, sdk.NewUint(50000000), sdk.NewUint(4), 100)
+		require.NoError(t, err)
+		ask, err := app.OrderKeeper.Post(app.Ctx, owner, mktID, matcheng.Ask, sdk.NewUint(50000000), sdk.NewUint(3), 100)
+		require.NoError(t, err)
+		otherAsk, err := app.OrderKeeper.Post(app.Ctx, other, mktID, matcheng.Ask, sdk.NewUint(50000000), sdk.NewUint(1000000000), 100)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, ask.ID))
+		remaining, err := app.OrderKeeper.Get(app.Ctx, bid.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, sdk.OneUint(), remaining.Quantity)
+		remaining, err = app.OrderKeeper.Get(app.Ctx, otherAsk.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, sdk.NewUint(1000000000), remaining.Quantity)
+	})
+	t.Run("leaves crossing orders alone when prevention is off", func(t *testing.T) {
+		app, mktID, owner := setup(t, matcheng.STPNone)
+		_, err := app.OrderKeeper.Post(app.Ctx, owner, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		bid, err := app.OrderKeeper.Post(app.Ctx, owner, mktID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, bid.ID))
+	})
+}
+
+func TestKeeper_ExecuteFillDecimals(t *testing.T) {
+	testflags.UnitTest(t)
+	app, _, buyer, seller := setupImmediateTest(t)
+	nominee := app.MarketKeeper.GetParams(app.Ctx).Nominees[0]
+	mkt, err := app.MarketKeeper.CreateMarketWithDecimals(app.Ctx, nominee, "tst1", "tst2", 6, 2)
+	require.NoError(t, err)
+
+	balance := func(addr sdk.AccAddress, denom string) sdk.Uint {
+		return sdk.NewUintFromBigInt(app.BankKeeper.GetCoins(app.Ctx, addr).AmountOf(denom).BigInt())
+	}
+	start := testutil.ToBaseUnits(100)
+
+	// 10 whole units of a 6 decimal base asset at 2 units of a 2 decimal quote asset each
+	_, err = app.OrderKeeper.Post(app.Ctx, seller, mkt.ID, matcheng.Ask, testutil.ToBaseUnits(2), sdk.NewUint(10000000), 100)
+	require.NoError(t, err)
+	_, err = app.OrderKeeper.Post(app.Ctx, buyer, mkt.ID, matcheng.Bid, testutil.ToBaseUnits(3), sdk.NewUint(10000000), 100)
+	require.NoError(t, err)
+	t.Run("escrows the quote amount at the quote asset's precision", func(t *testing.T) {
+		testutil.AssertEqualUints(t, start.SubUint64(3000), balance(buyer, "tst2"))
+	})
+
+	require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+	t.Run("settles and refunds at each asset's precision", func(t *testing.T) {
+		testutil.AssertEqualUints(t, start.AddUint64(10000000), balance(buyer, "tst1"))
+		testutil.AssertEqualUints(t, start.SubUint64(2000), balance(buyer, "tst2"))
+		testutil.AssertEqualUints(t, start.SubUint64(10000000), balance(seller, "tst1"))
+		testutil.AssertEqualUints(t, start.AddUint64(2000), balance(seller, "tst2"))
+	})
+}
+
+func TestKeeper_ExecuteMarketStatus(t *testing.T) {
+	testflags.UnitTest(t)
+	setStatus := func(app *mockapp.MockApp, mktID uexstore.EntityID, status types2.Status) {
+		nominee := app.MarketKeeper.GetParams(app.Ctx).Nominees[0]
+		_, err := app.MarketKeeper.SetStatus(app.Ctx, nominee, mktID, status)
+		require.NoError(t, err)
+	}
+	balance := func(app *mockapp.MockApp, addr sdk.AccAddress, denom string) sdk.Uint {
+		return sdk.NewUintFromBigInt(app.BankKeeper.GetCoins(app.Ctx, addr).AmountOf(denom).BigInt())
+	}
+
+	t.Run("does not match a halted market", func(t *testing.T) {
+		app, mktID, buyer, seller := setupImmediateTest(t)
+		ask, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		bid, err := app.OrderKeeper.Post(app.Ctx, buyer, mktID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		setStatus(app, mktID, types2.StatusHalted)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.True(t, app.OrderKeeper.Has(app.Ctx, ask.ID))
+		assert.True(t, app.OrderKeeper.Has(app.Ctx, bid.ID))
+	})
+	t.Run("cancels and refunds every order in a delisted market", func(t *testing.T) {
+		app, mktID, buyer, seller := setupImmediateTest(t)
+		ask, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		bid, err := app.OrderKeeper.Post(app.Ctx, buyer, mktID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+		require.NoError(t, err)
+		cond, err := app.OrderKeeper.PostConditional(app.Ctx, seller, mktID, matcheng.Ask, types4.StopLoss, types4.LastPrice, testutil.ToBaseUnits(1), matcheng.Limit, testutil.ToBaseUnits(1), testutil.ToBaseUnits(5), sdk.ZeroUint(), 100)
+		require.NoError(t, err)
+		setStatus(app, mktID, types2.StatusDelisted)
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, ask.ID))
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, bid.ID))
+		assert.False(t, app.OrderKeeper.HasConditional(app.Ctx, cond.ID))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), balance(app, seller, "tst1"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), balance(app, buyer, "tst2"))
+	})
+}
+
+func TestKeeper_ExecuteContinuous(t *testing.T) {
+	testflags.UnitTest(t)
+	app, mktID, buyer, seller := setupImmediateTest(t)
+	params := app.MarketKeeper.GetParams(app.Ctx)
+	params.Markets[mktID.Dec().Uint64()].MatchingMode = matcheng.Continuous
+	params.Markets[mktID.Dec().Uint64()].MakerFeeBps = 10
+	params.Markets[mktID.Dec().Uint64()].TakerFeeBps = 20
+	app.MarketKeeper.SetParams(app.Ctx, params)
+
+	balance := func(addr sdk.AccAddress, denom string) sdk.Uint {
+		return sdk.NewUintFromBigInt(app.BankKeeper.GetCoins(app.Ctx, addr).AmountOf(denom).BigInt())
+	}
+
+	cheap, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 100)
+	require.NoError(t, err)
+	dear, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, testutil.ToBaseUnits(3), testutil.ToBaseUnits(10), 100)
+	require.NoError(t, err)
+	bid, err := app.OrderKeeper.Post(app.Ctx, buyer, mktID, matcheng.Bid, testutil.ToBaseUnits(3), testutil.ToBaseUnits(15), 100)
+	require.NoError(t, err)
+	require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+	t.Run("fills the bid against the asks in price priority", func(t *testing.T) {
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, bid.ID))
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, cheap.ID))
+		remaining, err := app.OrderKeeper.Get(app.Ctx, dear.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(5), remaining.Quantity)
+	})
+	t.Run("pays each resting order its own price", func(t *testing.T) {
+		// 10 @ 2 and 5 @ 3, less the maker fee
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(35).MulUint64(9990).QuoUint64(10000).Add(testutil.ToBaseUnits(100)), balance(seller, "tst2"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(80), balance(seller, "tst1"))
+	})
+	t.Run("refunds the bid the difference to its limit price", func(t *testing.T) {
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(65), balance(buyer, "tst2"))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(15).MulUint64(9980).QuoUint64(10000).Add(testutil.ToBaseUnits(100)), balance(buyer, "tst1"))
+	})
+	t.Run("records the last trade price", func(t *testing.T) {
+		price, ok := app.OrderKeeper.LastClearingPrice(app.Ctx, mktID)
+		require.True(t, ok)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(3), price)
+	})
+}
+
+func TestKeeper_ExecuteContinuousDust(t *testing.T) {
+	testflags.UnitTest(t)
+	app, mktID, buyer, seller := setupImmediateTest(t)
+	params := app.MarketKeeper.GetParams(app.Ctx)
+	params.Markets[mktID.Dec().Uint64()].MatchingMode = matcheng.Continuous
+	app.MarketKeeper.SetParams(app.Ctx, params)
+
+	ask, err := app.OrderKeeper.Post(app.Ctx, seller, mktID, matcheng.Ask, sdk.OneUint(), sdk.NewUint(1000000000), 100)
+	require.NoError(t, err)
+	dust, err := app.OrderKeeper.Post(app.Ctx, buyer, mktID, matcheng.Bid, sdk.NewUint(2), sdk.NewUint(50000000), 100)
+	require.NoError(t, err)
+	bid, err := app.OrderKeeper.Post(app.Ctx, buyer, mktID, matcheng.Bid, sdk.NewUint(2), sdk.NewUint(100000000), 100)
+	require.NoError(t, err)
+	require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx))
+
+	t.Run("leaves a bid too small to trade on the book", func(t *testing.T) {
+		remaining, err := app.OrderKeeper.Get(app.Ctx, dust.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, sdk.NewUint(50000000), remaining.Quantity)
+	})
+	t.Run("trades the next bid with the same ask", func(t *testing.T) {
+		assert.False(t, app.OrderKeeper.Has(app.Ctx, bid.ID))
+		remaining, err := app.OrderKeeper.Get(app.Ctx, ask.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, sdk.NewUint(900000000), remaining.Quantity)
+	})
+	t.Run("matches the orders left again in the next block", func(t *testing.T) {
+		require.NoError(t, app.ExecutionKeeper.ExecuteAndCancelExpired(app.Ctx.WithBlockHeight(app.Ctx.BlockHeight()+1)))
+		assert.True(t, app.OrderKeeper.Has(app.Ctx, dust.ID))
+	})
+}
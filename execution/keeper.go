@@ -1,6 +1,7 @@
 package execution
 
 import (
+	"sort"
 	"time"
 
 	"github.com/xar-network/xar-network/pkg/log"
@@ -8,36 +9,41 @@ import (
 	"github.com/xar-network/xar-network/types"
 	"github.com/xar-network/xar-network/types/store"
 	"github.com/xar-network/xar-network/x/market"
+	types3 "github.com/xar-network/xar-network/x/market/types"
 	"github.com/xar-network/xar-network/x/order"
 	types2 "github.com/xar-network/xar-network/x/order/types"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/bank"
+	"github.com/cosmos/cosmos-sdk/x/supply"
 )
 
 type Keeper struct {
-	queue     types.Backend
-	mk        market.Keeper
-	ordK      order.Keeper
-	bk        bank.Keeper
-	metrics   *Metrics
-	saveFills bool
+	queue        types.Backend
+	mk           market.Keeper
+	ordK         order.Keeper
+	bk           bank.Keeper
+	feeCollector sdk.AccAddress
+	metrics      *Metrics
+	saveFills    bool
 }
 
 type matcherByMarket struct {
 	matcher *matcheng.Matcher
-	mktID   store.EntityID
+	mkt     types3.Market
+	orders  []types2.Order
 }
 
 var logger = log.WithModule("execution")
 
-func NewKeeper(queue types.Backend, mk market.Keeper, ordK order.Keeper, bk bank.Keeper) Keeper {
+func NewKeeper(queue types.Backend, mk market.Keeper, ordK order.Keeper, bk bank.Keeper, feeCollectorName string) Keeper {
 	return Keeper{
-		queue:   queue,
-		mk:      mk,
-		ordK:    ordK,
-		bk:      bk,
-		metrics: PrometheusMetrics(),
+		queue:        queue,
+		mk:           mk,
+		ordK:         ordK,
+		bk:           bk,
+		feeCollector: supply.NewModuleAddress(feeCollectorName),
+		metrics:      PrometheusMetrics(),
 	}
 }
 
@@ -45,12 +51,13 @@ func (k Keeper) ExecuteAndCancelExpired(ctx sdk.Context) sdk.Error {
 	start := time.Now()
 	height := ctx.BlockHeight()
 
-	var toCancel []store.EntityID
-	k.ordK.Iterator(ctx, func(ord types2.Order) bool {
-		if height-ord.CreatedBlock > int64(ord.TimeInForceBlocks) {
-			toCancel = append(toCancel, ord.ID)
-		}
+	if err := k.unwindDelisted(ctx); err != nil {
+		return err
+	}
 
+	var toCancel []store.EntityID
+	k.ordK.ExpiringIterator(ctx, height, func(ord types2.Order) bool {
+		toCancel = append(toCancel, ord.ID)
 		return true
 	})
 	for _, ordID := range toCancel {
@@ -61,32 +68,90 @@ func (k Keeper) ExecuteAndCancelExpired(ctx sdk.Context) sdk.Error {
 
 	logger.Info("cancelled expired orders", "count", len(toCancel))
 
-	matchersByMarket := make(map[string]*matcherByMarket)
+	activated, err := k.ordK.ActivateConditionals(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("activated conditional orders", "count", len(activated))
+
+	// immediate orders never outlive the block they are posted in, so they
+	// are exactly the ones whose time in force ends with this block
+	var immediate []store.EntityID
+	k.ordK.ExpiringIterator(ctx, height+1, func(ord types2.Order) bool {
+		if !ord.Type.Rests() {
+			immediate = append(immediate, ord.ID)
+		}
+		return true
+	})
 
-	k.ordK.ReverseIterator(ctx, func(ord types2.Order) bool {
-		matcher := getMatcherByMarket(matchersByMarket, ord).matcher
-		matcher.EnqueueOrder(ord.Direction, ord.ID, ord.Price, ord.Quantity)
+	var markets []types3.Market
+	k.mk.Iterator(ctx, func(mkt types3.Market) bool {
+		if mkt.Status.Matches() {
+			markets = append(markets, mkt)
+		}
 		return true
 	})
 
 	var toFill []*matcheng.MatchResults
-	for _, m := range matchersByMarket {
-		res := m.matcher.Match()
+	var selfTrades []matcheng.SelfTrade
+	var continuous []*matcherByMarket
+	for _, mkt := range markets {
+		m := &matcherByMarket{
+			mkt: mkt,
+		}
+		for _, dir := range []matcheng.Direction{matcheng.Bid, matcheng.Ask} {
+			k.ordK.BookIterator(ctx, mkt.ID, dir, func(ord types2.Order) bool {
+				m.orders = append(m.orders, ord)
+				return true
+			})
+		}
+		if len(m.orders) == 0 {
+			continue
+		}
+		if mkt.MatchingMode == matcheng.Continuous {
+			continuous = append(continuous, m)
+			continue
+		}
+
+		m.matcher = matcheng.GetMatcher()
+		res, st := m.match()
+		selfTrades = append(selfTrades, st...)
 		if res == nil {
+			matcheng.ReturnMatcher(m.matcher)
 			continue
 		}
 
 		_ = k.queue.Publish(types.Batch{
 			BlockNumber:   height,
 			BlockTime:     ctx.BlockHeader().Time,
-			MarketID:      m.mktID,
+			MarketID:      m.mkt.ID,
 			ClearingPrice: res.ClearingPrice,
 			Bids:          res.BidAggregates,
 			Asks:          res.AskAggregates,
+			MatchTable:    res.MatchTable,
+			AggSupply:     res.AggSupply,
+			AggDemand:     res.AggDemand,
+			ProRata:       res.ProRata,
+			BidOrders:     res.Bids,
+			AskOrders:     res.Asks,
+			Fills:         res.Fills,
 		})
+		k.ordK.SetLastClearingPrice(ctx, m.mkt.ID, res.ClearingPrice)
 		toFill = append(toFill, res)
 		matcheng.ReturnMatcher(m.matcher)
 	}
+
+	// self-trades were taken out of the batch before matching, so the book
+	// has to agree with the matcher before any fill is executed
+	for _, st := range selfTrades {
+		if err := k.applySelfTrade(ctx, st); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("prevented self-trades", "count", len(selfTrades))
+
 	var fillCount int
 	for _, res := range toFill {
 		fillCount += len(res.Fills)
@@ -96,9 +161,29 @@ func (k Keeper) ExecuteAndCancelExpired(ctx sdk.Context) sdk.Error {
 			}
 		}
 	}
+	for _, m := range continuous {
+		count, err := k.executeContinuous(ctx, m)
+		if err != nil {
+			return err
+		}
+		fillCount += count
+	}
 
 	logger.Info("matched orders", "count", fillCount)
 
+	var unfilledCount int
+	for _, ordID := range immediate {
+		if !k.ordK.Has(ctx, ordID) {
+			continue
+		}
+		if err := k.ordK.Cancel(ctx, ordID); err != nil {
+			return err
+		}
+		unfilledCount++
+	}
+
+	logger.Info("cancelled unfilled immediate orders", "count", unfilledCount)
+
 	duration := time.Since(start).Nanoseconds()
 	k.metrics.ProcessingTime.Observe(float64(duration) / 1000000)
 	k.metrics.OrdersProcessed.Observe(float64(fillCount))
@@ -110,6 +195,30 @@ func (k Keeper) ExecuteFill(ctx sdk.Context, clearingPrice sdk.Uint, f matcheng.
 	if err != nil {
 		return err
 	}
+	// orders resting from an earlier block provided the liquidity
+	return k.executeFill(ctx, ord, clearingPrice, f, ord.CreatedBlock < ctx.BlockHeight())
+}
+
+// executeTrade executes both sides of a trade of a continuous market. The
+// resting order is always the maker.
+func (k Keeper) executeTrade(ctx sdk.Context, trade matcheng.Trade) sdk.Error {
+	maker, err := k.ordK.Get(ctx, trade.Maker.OrderID)
+	if err != nil {
+		return err
+	}
+	if err := k.executeFill(ctx, maker, trade.Price, trade.Maker, true); err != nil {
+		return err
+	}
+	taker, err := k.ordK.Get(ctx, trade.Taker.OrderID)
+	if err != nil {
+		return err
+	}
+	return k.executeFill(ctx, taker, trade.Price, trade.Taker, false)
+}
+
+// executeFill credits the owner of ord with a fill at price, net of fees, and
+// refunds bids for the difference to their limit price.
+func (k Keeper) executeFill(ctx sdk.Context, ord types2.Order, price sdk.Uint, f matcheng.Fill, maker bool) sdk.Error {
 	mkt, err := k.mk.Get(ctx, ord.MarketID)
 	if err != nil {
 		return err
@@ -119,25 +228,21 @@ func (k Keeper) ExecuteFill(ctx sdk.Context, clearingPrice sdk.Uint, f matcheng.
 		panic(err)
 	}
 
+	feeBps := mkt.FeeBps(maker)
+
+	var fee sdk.Uint
+	var feeDenom string
 	if ord.Direction == matcheng.Bid {
-		quoteAmount, ok := sdk.NewIntFromString(f.QtyFilled.String())
-		if !ok {
-			panic("invalid QtyFilled value")
-		}
-		_, err = k.bk.AddCoins(ctx, ord.Owner, sdk.NewCoins(sdk.NewCoin(mkt.BaseAssetDenom, quoteAmount)))
-		if err != nil {
+		feeDenom = mkt.BaseAssetDenom
+		fee = calculateFee(f.QtyFilled, feeBps)
+		if err := k.credit(ctx, ord.Owner, feeDenom, f.QtyFilled.Sub(fee)); err != nil {
 			return err
 		}
-		if clearingPrice.LT(ord.Price) {
-			diff := ord.Price.Sub(clearingPrice)
-			refund, qErr := matcheng.NormalizeQuoteQuantity(diff, f.QtyFilled)
-			refundInt, ok := sdk.NewIntFromString(refund.String())
-			if !ok {
-				panic("invalid refundInt value")
-			}
+		if price.LT(ord.Price) {
+			diff := ord.Price.Sub(price)
+			refund, qErr := mkt.NormalizeQuoteQuantity(diff, f.QtyFilled)
 			if qErr == nil {
-				_, err = k.bk.AddCoins(ctx, ord.Owner, sdk.NewCoins(sdk.NewCoin(mkt.QuoteAssetDenom, refundInt)))
-				if err != nil {
+				if err := k.credit(ctx, ord.Owner, mkt.QuoteAssetDenom, refund); err != nil {
 					return err
 				}
 			} else {
@@ -150,20 +255,18 @@ func (k Keeper) ExecuteFill(ctx sdk.Context, clearingPrice sdk.Uint, f matcheng.
 			}
 		}
 	} else {
-		baseAmount, qErr := matcheng.NormalizeQuoteQuantity(clearingPrice, f.QtyFilled)
-		baseAmountInt, ok := sdk.NewIntFromString(baseAmount.String())
-		if !ok {
-			panic("invalid baseAmountInt")
-		}
-		if qErr == nil {
-			_, err = k.bk.AddCoins(ctx, ord.Owner, sdk.NewCoins(sdk.NewCoin(mkt.QuoteAssetDenom, baseAmountInt)))
-			if err != nil {
-				return err
-			}
-		} else {
-			panic("clearing price too small to represent")
+		// asks whose fill would be worth less than a unit of the quote
+		// asset are left out of matching, so this is never zero
+		baseAmount, _ := mkt.NormalizeQuoteQuantity(price, f.QtyFilled)
+		feeDenom = mkt.QuoteAssetDenom
+		fee = calculateFee(baseAmount, feeBps)
+		if err := k.credit(ctx, ord.Owner, feeDenom, baseAmount.Sub(fee)); err != nil {
+			return err
 		}
 	}
+	if err := k.credit(ctx, k.feeCollector, feeDenom, fee); err != nil {
+		return err
+	}
 
 	ord.Quantity = f.QtyUnfilled
 	if ord.Quantity.Equal(sdk.ZeroUint()) {
@@ -178,6 +281,10 @@ func (k Keeper) ExecuteFill(ctx sdk.Context, clearingPrice sdk.Uint, f matcheng.
 		}
 	}
 
+	quoteQty, qErr := mkt.NormalizeQuoteQuantity(price, f.QtyFilled)
+	if qErr != nil {
+		quoteQty = sdk.ZeroUint()
+	}
 	_ = k.queue.Publish(types.Fill{
 		OrderID:     ord.ID,
 		MarketID:    mkt.ID,
@@ -188,20 +295,203 @@ func (k Keeper) ExecuteFill(ctx sdk.Context, clearingPrice sdk.Uint, f matcheng.
 		QtyUnfilled: f.QtyUnfilled,
 		BlockNumber: ctx.BlockHeight(),
 		BlockTime:   ctx.BlockHeader().Time.Unix(),
-		Price:       clearingPrice,
+		Price:       price,
+		QuoteQty:    quoteQty,
+		Fee:         fee,
+		FeeDenom:    feeDenom,
+		Maker:       maker,
 	})
 	return nil
 }
 
-func getMatcherByMarket(matchers map[string]*matcherByMarket, ord types2.Order) *matcherByMarket {
-	mkt := ord.MarketID.String()
-	matcher := matchers[mkt]
-	if matcher == nil {
-		matcher = &matcherByMarket{
-			matcher: matcheng.GetMatcher(),
-			mktID:   ord.MarketID,
+// credit adds a fill's proceeds to an account. Zero amounts are skipped.
+func (k Keeper) credit(ctx sdk.Context, addr sdk.AccAddress, denom string, amount sdk.Uint) sdk.Error {
+	amt, ok := sdk.NewIntFromString(amount.String())
+	if !ok {
+		panic("invalid credit amount")
+	}
+	if !amt.IsPositive() {
+		return nil
+	}
+	_, err := k.bk.AddCoins(ctx, addr, sdk.NewCoins(sdk.NewCoin(denom, amt)))
+	return err
+}
+
+// calculateFee returns feeBps basis points of amount, rounded down.
+func calculateFee(amount sdk.Uint, feeBps uint16) sdk.Uint {
+	return amount.MulUint64(uint64(feeBps)).QuoUint64(10000)
+}
+
+// unwindDelisted cancels and refunds every order and conditional order left in
+// a delisted market.
+func (k Keeper) unwindDelisted(ctx sdk.Context) sdk.Error {
+	delisted := make(map[string]bool)
+	var orders []store.EntityID
+	k.mk.Iterator(ctx, func(mkt types3.Market) bool {
+		if mkt.Status != types3.StatusDelisted {
+			return true
+		}
+		delisted[mkt.ID.String()] = true
+		for _, dir := range []matcheng.Direction{matcheng.Bid, matcheng.Ask} {
+			k.ordK.BookIterator(ctx, mkt.ID, dir, func(ord types2.Order) bool {
+				orders = append(orders, ord.ID)
+				return true
+			})
+		}
+		return true
+	})
+	if len(delisted) == 0 {
+		return nil
+	}
+
+	for _, ordID := range orders {
+		if err := k.ordK.Cancel(ctx, ordID); err != nil {
+			return err
+		}
+	}
+
+	var conds []store.EntityID
+	k.ordK.ConditionalIterator(ctx, func(cond types2.ConditionalOrder) bool {
+		if delisted[cond.MarketID.String()] {
+			conds = append(conds, cond.ID)
+		}
+		return true
+	})
+	for _, condID := range conds {
+		if err := k.ordK.CancelConditional(ctx, condID); err != nil {
+			return err
+		}
+	}
+
+	logger.Info("cancelled orders in delisted markets", "count", len(orders), "conditional_count", len(conds))
+	return nil
+}
+
+// applySelfTrade cancels an order that self-trade prevention removed from the
+// batch, or reduces it to the quantity that was left in it.
+func (k Keeper) applySelfTrade(ctx sdk.Context, st matcheng.SelfTrade) sdk.Error {
+	if st.QtyRemaining.IsZero() {
+		logger.Info("cancelled self-trading order", "id", st.OrderID.String())
+		return k.ordK.Cancel(ctx, st.OrderID)
+	}
+
+	logger.Info(
+		"reduced self-trading order",
+		"id", st.OrderID.String(),
+		"qty_cancelled", st.QtyCancelled.String(),
+	)
+	return k.ordK.Reduce(ctx, st.OrderID, st.QtyRemaining)
+}
+
+// executeContinuous matches the orders of a continuous market in price-time
+// priority, oldest first, executing every trade and self-trade as it happens.
+// Trades execute at the resting order's price, and the last one becomes the
+// market's last clearing price. It returns the number of fills executed.
+func (k Keeper) executeContinuous(ctx sdk.Context, m *matcherByMarket) (int, sdk.Error) {
+	sort.Slice(m.orders, func(i, j int) bool {
+		return m.orders[i].ID.Cmp(m.orders[j].ID) < 0
+	})
+
+	matcher := matcheng.NewContinuousMatcher(m.mkt.SelfTradePrevention, m.mkt.BaseAssetDecimals, m.mkt.QuoteAssetDecimals)
+	var lastPrice sdk.Uint
+	var fillCount int
+	for _, ord := range m.orders {
+		events := matcher.AddOrder(ord.Direction, ord.ID, ord.Owner, ord.Price, ord.Quantity, ord.Type)
+		for _, ev := range events {
+			if ev.SelfTrade != nil {
+				if err := k.applySelfTrade(ctx, *ev.SelfTrade); err != nil {
+					return fillCount, err
+				}
+				continue
+			}
+			if err := k.executeTrade(ctx, *ev.Trade); err != nil {
+				return fillCount, err
+			}
+			lastPrice = ev.Trade.Price
+			fillCount += 2
+		}
+	}
+
+	if fillCount > 0 {
+		k.ordK.SetLastClearingPrice(ctx, m.mkt.ID, lastPrice)
+	}
+	return fillCount, nil
+}
+
+// match runs the batch auction for the market. Self-trades are resolved
+// before each run according to the market's prevention mode. Fill-or-kill
+// orders that would not be filled completely, and asks whose fill would be
+// worth less than a unit of the quote asset, are pulled from the batch and the
+// auction is run again, until every remaining fill-or-kill order is filled in
+// full and every ask fill can be paid for. The self-trades returned are those
+// of the final run.
+func (m *matcherByMarket) match() (*matcheng.MatchResults, []matcheng.SelfTrade) {
+	excluded := make(map[string]bool)
+	for {
+		m.matcher.Reset()
+		bestBid, hasBids := m.bestBid(excluded)
+		for _, ord := range m.orders {
+			if excluded[ord.ID.String()] {
+				continue
+			}
+			price := ord.Price
+			if ord.Type == matcheng.Market && ord.Direction == matcheng.Ask {
+				// market asks take the best bid's price rather than
+				// their placeholder, so that they cannot drag the
+				// clearing price down; without bids they cannot fill
+				if !hasBids {
+					continue
+				}
+				price = bestBid
+			}
+			m.matcher.EnqueueOwnedOrder(ord.Direction, ord.ID, ord.Owner, price, ord.Quantity)
+		}
+		selfTrades := m.matcher.PreventSelfTrades(m.mkt.SelfTradePrevention)
+		res := m.matcher.Match()
+
+		filled := make(map[string]bool)
+		dust := make(map[string]bool)
+		if res != nil {
+			for _, f := range res.Fills {
+				filled[f.OrderID.String()] = f.QtyUnfilled.IsZero()
+				if _, err := m.mkt.NormalizeQuoteQuantity(res.ClearingPrice, f.QtyFilled); err != nil && !f.QtyFilled.IsZero() {
+					dust[f.OrderID.String()] = true
+				}
+			}
+		}
+
+		var rerun bool
+		for _, ord := range m.orders {
+			id := ord.ID.String()
+			if excluded[id] {
+				continue
+			}
+			killed := ord.Type == matcheng.FillOrKill && !filled[id]
+			if !killed && !(ord.Direction == matcheng.Ask && dust[id]) {
+				continue
+			}
+			excluded[id] = true
+			rerun = true
+		}
+		if !rerun {
+			return res, selfTrades
+		}
+	}
+}
+
+// bestBid returns the highest price of the bids in the batch that have not
+// been excluded, and whether there are any.
+func (m *matcherByMarket) bestBid(excluded map[string]bool) (sdk.Uint, bool) {
+	var best sdk.Uint
+	var found bool
+	for _, ord := range m.orders {
+		if ord.Direction != matcheng.Bid || excluded[ord.ID.String()] {
+			continue
+		}
+		if !found || ord.Price.GT(best) {
+			best = ord.Price
+			found = true
 		}
-		matchers[mkt] = matcher
 	}
-	return matcher
+	return best, found
 }
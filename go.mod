@@ -12,7 +12,9 @@ require (
 	github.com/go-kit/kit v0.9.0
 	github.com/gobuffalo/packr v1.30.1
 	github.com/gorilla/mux v1.7.3
+	github.com/gorilla/securecookie v1.1.1
 	github.com/gorilla/sessions v1.1.3
+	github.com/gorilla/websocket v1.4.1
 	github.com/olekukonko/tablewriter v0.0.2
 	github.com/otiai10/copy v1.0.2
 	github.com/pkg/errors v0.8.1
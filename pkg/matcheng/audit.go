@@ -0,0 +1,81 @@
+package matcheng
+
+import (
+	"fmt"
+)
+
+// Replay matches a batch again from the orders it was matched with, as
+// recorded in MatchResults.Bids and MatchResults.Asks.
+func Replay(bids []Order, asks []Order) *MatchResults {
+	m := NewMatcher()
+	for _, bid := range bids {
+		m.EnqueueOwnedOrder(Bid, bid.ID, bid.Owner, bid.Price, bid.Quantity)
+	}
+	for _, ask := range asks {
+		m.EnqueueOwnedOrder(Ask, ask.ID, ask.Owner, ask.Price, ask.Quantity)
+	}
+	return m.Match()
+}
+
+// DiffResults describes every way in which actual differs from expected in
+// clearing price, match table, pro-rata factor or fills. It returns nil when
+// the two agree.
+func DiffResults(expected *MatchResults, actual *MatchResults) []string {
+	if expected == nil || actual == nil {
+		if expected == actual {
+			return nil
+		}
+		return []string{fmt.Sprintf("expected results %v, got %v", expected != nil, actual != nil)}
+	}
+
+	var out []string
+	diff := func(format string, args ...interface{}) {
+		out = append(out, fmt.Sprintf(format, args...))
+	}
+	if !expected.ClearingPrice.Equal(actual.ClearingPrice) {
+		diff("clearing price: expected %s, got %s", expected.ClearingPrice, actual.ClearingPrice)
+	}
+	if !expected.AggSupply.Equal(actual.AggSupply) || !expected.AggDemand.Equal(actual.AggDemand) {
+		diff("supply/demand at clearing price: expected %s/%s, got %s/%s", expected.AggSupply, expected.AggDemand, actual.AggSupply, actual.AggDemand)
+	}
+	if !expected.ProRata.Equal(actual.ProRata) {
+		diff("pro-rata factor: expected %s, got %s", expected.ProRata, actual.ProRata)
+	}
+
+	if len(expected.MatchTable) != len(actual.MatchTable) {
+		diff("match table: expected %d entries, got %d", len(expected.MatchTable), len(actual.MatchTable))
+	} else {
+		for i, entry := range expected.MatchTable {
+			other := actual.MatchTable[i]
+			if !entry[0].Equal(other[0]) || !entry[1].Equal(other[1]) || !entry[2].Equal(other[2]) {
+				diff("match table entry %d: expected %s, got %s", i, formatEntry(entry), formatEntry(other))
+			}
+		}
+	}
+
+	fills := make(map[string]Fill)
+	for _, f := range actual.Fills {
+		fills[f.OrderID.String()] = f
+	}
+	for _, f := range expected.Fills {
+		other, ok := fills[f.OrderID.String()]
+		if !ok {
+			diff("order %s: expected a fill of %s, got none", f.OrderID, f.QtyFilled)
+			continue
+		}
+		delete(fills, f.OrderID.String())
+		if !f.QtyFilled.Equal(other.QtyFilled) || !f.QtyUnfilled.Equal(other.QtyUnfilled) {
+			diff("order %s: expected %s filled and %s unfilled, got %s and %s", f.OrderID, f.QtyFilled, f.QtyUnfilled, other.QtyFilled, other.QtyUnfilled)
+		}
+	}
+	for _, f := range actual.Fills {
+		if _, ok := fills[f.OrderID.String()]; ok {
+			diff("order %s: expected no fill, got %s", f.OrderID, f.QtyFilled)
+		}
+	}
+	return out
+}
+
+func formatEntry(e MatchEntry) string {
+	return fmt.Sprintf("[%s %s %s]", e[0], e[1], e[2])
+}
@@ -0,0 +1,39 @@
+package matcheng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xar-network/xar-network/testutil/testflags"
+	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestReplay(t *testing.T) {
+	testflags.UnitTest(t)
+	m := NewMatcher()
+	m.EnqueueOrder(Bid, store.NewEntityID(1), sdk.NewUint(10), sdk.NewUint(30))
+	m.EnqueueOrder(Bid, store.NewEntityID(2), sdk.NewUint(11), sdk.NewUint(30))
+	m.EnqueueOrder(Ask, store.NewEntityID(3), sdk.NewUint(9), sdk.NewUint(40))
+	res := m.Match()
+	require.NotNil(t, res)
+	assert.Len(t, res.Bids, 2)
+	assert.Len(t, res.Asks, 1)
+
+	t.Run("reproduces the results from the recorded orders", func(t *testing.T) {
+		replayed := Replay(res.Bids, res.Asks)
+		assert.Empty(t, DiffResults(res, replayed))
+	})
+	t.Run("reports fills that differ", func(t *testing.T) {
+		replayed := Replay(res.Bids, res.Asks)
+		replayed.Fills[0].QtyFilled = replayed.Fills[0].QtyFilled.AddUint64(1)
+		replayed.ProRata = sdk.OneDec()
+		assert.Len(t, DiffResults(res, replayed), 2)
+	})
+	t.Run("reports a batch that no longer clears", func(t *testing.T) {
+		assert.Len(t, DiffResults(res, Replay(res.Bids, nil)), 1)
+	})
+}
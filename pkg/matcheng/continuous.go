@@ -0,0 +1,197 @@
+package matcheng
+
+import (
+	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Trade is a single execution between an incoming order and an order resting
+// on the book. It always executes at the resting order's price.
+type Trade struct {
+	Price    sdk.Uint
+	Quantity sdk.Uint
+	Maker    Fill
+	Taker    Fill
+}
+
+// Event is something that happened while an order was added to a
+// ContinuousMatcher. Exactly one of its fields is set.
+type Event struct {
+	Trade     *Trade
+	SelfTrade *SelfTrade
+}
+
+// ContinuousMatcher is a limit order book matched in price-time priority.
+// Unlike Matcher, which clears a whole batch at once, every order added to it
+// is matched immediately against the orders already resting on the book.
+// Orders must be added in the order in which they were placed.
+type ContinuousMatcher struct {
+	// highest bid first, oldest first within a price
+	bids []Order
+	// lowest ask first, oldest first within a price
+	asks []Order
+	stp  SelfTradePrevention
+	// precision of the market's assets, which decides whether a trade is
+	// worth at least a unit of the quote asset
+	baseDecimals  uint8
+	quoteDecimals uint8
+}
+
+func NewContinuousMatcher(stp SelfTradePrevention, baseDecimals uint8, quoteDecimals uint8) *ContinuousMatcher {
+	return &ContinuousMatcher{
+		bids:          make([]Order, 0),
+		asks:          make([]Order, 0),
+		stp:           stp,
+		baseDecimals:  baseDecimals,
+		quoteDecimals: quoteDecimals,
+	}
+}
+
+// AddOrder matches an order against the opposite side of the book and returns
+// what happened, in order. Unfilled quantity of limit orders rests on the
+// book; that of every other type is dropped. Fill-or-kill orders that cannot
+// be filled in full against the book do not trade at all. A resting order
+// whose trade with the incoming order would be worth less than a unit of the
+// quote asset is passed over and stays on the book.
+func (m *ContinuousMatcher) AddOrder(dir Direction, id store.EntityID, owner sdk.AccAddress, price sdk.Uint, quantity sdk.Uint, orderType OrderType) []Event {
+	incoming := Order{
+		ID:       id,
+		Owner:    owner,
+		Price:    price,
+		Quantity: quantity,
+	}
+	book := &m.asks
+	if dir == Ask {
+		book = &m.bids
+	}
+
+	if orderType == FillOrKill && m.available(*book, dir, incoming).LT(quantity) {
+		return nil
+	}
+
+	var events []Event
+	for i := 0; i < len(*book) && !incoming.Quantity.IsZero(); {
+		resting := &(*book)[i]
+		if !crosses(dir, incoming.Price, resting.Price) {
+			break
+		}
+
+		if m.selfTrades(incoming, *resting) {
+			switch m.stp {
+			case STPCancelNewest:
+				st := reduceOrder(&incoming, incoming.Quantity)
+				events = append(events, Event{SelfTrade: &st})
+			case STPCancelOldest:
+				st := reduceOrder(resting, resting.Quantity)
+				events = append(events, Event{SelfTrade: &st})
+			case STPDecrementBoth:
+				qty := minUint(incoming.Quantity, resting.Quantity)
+				restingST := reduceOrder(resting, qty)
+				incomingST := reduceOrder(&incoming, qty)
+				events = append(events, Event{SelfTrade: &restingST}, Event{SelfTrade: &incomingST})
+			}
+		} else {
+			qty := minUint(incoming.Quantity, resting.Quantity)
+			if !m.payable(resting.Price, qty) {
+				i++
+				continue
+			}
+			resting.Quantity = resting.Quantity.Sub(qty)
+			incoming.Quantity = incoming.Quantity.Sub(qty)
+			events = append(events, Event{Trade: &Trade{
+				Price:    resting.Price,
+				Quantity: qty,
+				Maker: Fill{
+					OrderID:     resting.ID,
+					QtyFilled:   qty,
+					QtyUnfilled: resting.Quantity,
+				},
+				Taker: Fill{
+					OrderID:     incoming.ID,
+					QtyFilled:   qty,
+					QtyUnfilled: incoming.Quantity,
+				},
+			}})
+		}
+
+		if resting.Quantity.IsZero() {
+			i++
+		}
+	}
+	*book = withoutEmpty(*book)
+
+	if !incoming.Quantity.IsZero() && orderType.Rests() {
+		m.rest(dir, incoming)
+	}
+	return events
+}
+
+// available returns the quantity on the book that order could trade with,
+// up to its own quantity.
+func (m *ContinuousMatcher) available(book []Order, dir Direction, order Order) sdk.Uint {
+	out := sdk.ZeroUint()
+	for _, resting := range book {
+		if !crosses(dir, order.Price, resting.Price) || out.Equal(order.Quantity) {
+			break
+		}
+		if m.selfTrades(order, resting) {
+			continue
+		}
+		qty := minUint(order.Quantity.Sub(out), resting.Quantity)
+		if !m.payable(resting.Price, qty) {
+			continue
+		}
+		out = out.Add(qty)
+	}
+	return out
+}
+
+// payable reports whether a trade of quantity at price is worth at least a
+// unit of the quote asset.
+func (m *ContinuousMatcher) payable(price sdk.Uint, quantity sdk.Uint) bool {
+	_, err := NormalizeQuoteQuantityWithDecimals(price, quantity, m.baseDecimals, m.quoteDecimals)
+	return err == nil
+}
+
+func (m *ContinuousMatcher) selfTrades(incoming Order, resting Order) bool {
+	return m.stp != STPNone && len(incoming.Owner) > 0 && incoming.Owner.Equals(resting.Owner)
+}
+
+func (m *ContinuousMatcher) rest(dir Direction, order Order) {
+	book := &m.bids
+	better := func(a, b sdk.Uint) bool { return a.GT(b) }
+	if dir == Ask {
+		book = &m.asks
+		better = func(a, b sdk.Uint) bool { return a.LT(b) }
+	}
+
+	// orders arrive in time order, so a new order goes behind every order
+	// at the same price
+	i := len(*book)
+	for j, resting := range *book {
+		if better(order.Price, resting.Price) {
+			i = j
+			break
+		}
+	}
+	*book = append(*book, Order{})
+	copy((*book)[i+1:], (*book)[i:])
+	(*book)[i] = order
+}
+
+// crosses reports whether an incoming order in direction dir at price can
+// trade with a resting order at restingPrice.
+func crosses(dir Direction, price sdk.Uint, restingPrice sdk.Uint) bool {
+	if dir == Bid {
+		return price.GTE(restingPrice)
+	}
+	return price.LTE(restingPrice)
+}
+
+func minUint(a, b sdk.Uint) sdk.Uint {
+	if a.LT(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,126 @@
+package matcheng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xar-network/xar-network/testutil/testflags"
+	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestContinuousMatcher_AddOrder(t *testing.T) {
+	testflags.UnitTest(t)
+	alice := sdk.AccAddress([]byte("alice"))
+	bob := sdk.AccAddress([]byte("bob"))
+	carol := sdk.AccAddress([]byte("carol"))
+
+	// asks 1 (bob, 10 @ 9), 2 (alice, 20 @ 10) and 3 (bob, 30 @ 10), of a
+	// base asset without decimals so that every trade is worth paying for
+	setup := func(stp SelfTradePrevention) *ContinuousMatcher {
+		m := NewContinuousMatcher(stp, 0, PriceDecimals)
+		m.AddOrder(Ask, store.NewEntityID(1), bob, sdk.NewUint(9), sdk.NewUint(10), Limit)
+		m.AddOrder(Ask, store.NewEntityID(2), alice, sdk.NewUint(10), sdk.NewUint(20), Limit)
+		m.AddOrder(Ask, store.NewEntityID(3), bob, sdk.NewUint(10), sdk.NewUint(30), Limit)
+		return m
+	}
+	assertTrade := func(t *testing.T, exp Trade, actual *Trade) {
+		require.NotNil(t, actual)
+		assert.Equal(t, exp.Price.String(), actual.Price.String(), "price")
+		assert.Equal(t, exp.Quantity.String(), actual.Quantity.String(), "quantity")
+		assert.True(t, exp.Maker.OrderID.Equals(actual.Maker.OrderID), "maker")
+		assert.Equal(t, exp.Maker.QtyUnfilled.String(), actual.Maker.QtyUnfilled.String(), "maker unfilled")
+		assert.True(t, exp.Taker.OrderID.Equals(actual.Taker.OrderID), "taker")
+		assert.Equal(t, exp.Taker.QtyUnfilled.String(), actual.Taker.QtyUnfilled.String(), "taker unfilled")
+	}
+	trade := func(price, qty uint64, maker uint64, makerLeft uint64, taker uint64, takerLeft uint64) Trade {
+		return Trade{
+			Price:    sdk.NewUint(price),
+			Quantity: sdk.NewUint(qty),
+			Maker:    Fill{OrderID: store.NewEntityID(maker), QtyUnfilled: sdk.NewUint(makerLeft)},
+			Taker:    Fill{OrderID: store.NewEntityID(taker), QtyUnfilled: sdk.NewUint(takerLeft)},
+		}
+	}
+
+	t.Run("non-crossing orders rest", func(t *testing.T) {
+		m := setup(STPNone)
+		assert.Empty(t, m.AddOrder(Bid, store.NewEntityID(4), carol, sdk.NewUint(8), sdk.NewUint(10), Limit))
+		assert.Len(t, m.bids, 1)
+		assert.Len(t, m.asks, 3)
+	})
+	t.Run("trades in price-time priority at the resting price", func(t *testing.T) {
+		m := setup(STPNone)
+		events := m.AddOrder(Bid, store.NewEntityID(4), carol, sdk.NewUint(11), sdk.NewUint(40), Limit)
+		require.Len(t, events, 3)
+		assertTrade(t, trade(9, 10, 1, 0, 4, 30), events[0].Trade)
+		assertTrade(t, trade(10, 20, 2, 0, 4, 10), events[1].Trade)
+		assertTrade(t, trade(10, 10, 3, 20, 4, 0), events[2].Trade)
+		assert.Empty(t, m.bids)
+		require.Len(t, m.asks, 1)
+		assert.Equal(t, "20", m.asks[0].Quantity.String())
+	})
+	t.Run("limit remainder rests at its own price", func(t *testing.T) {
+		m := setup(STPNone)
+		events := m.AddOrder(Bid, store.NewEntityID(4), carol, sdk.NewUint(9), sdk.NewUint(15), Limit)
+		require.Len(t, events, 1)
+		assertTrade(t, trade(9, 10, 1, 0, 4, 5), events[0].Trade)
+		require.Len(t, m.bids, 1)
+		assert.Equal(t, "5", m.bids[0].Quantity.String())
+		assert.Equal(t, "9", m.bids[0].Price.String())
+	})
+	t.Run("immediate-or-cancel remainder does not rest", func(t *testing.T) {
+		m := setup(STPNone)
+		events := m.AddOrder(Bid, store.NewEntityID(4), carol, sdk.NewUint(9), sdk.NewUint(15), ImmediateOrCancel)
+		require.Len(t, events, 1)
+		assert.Empty(t, m.bids)
+	})
+	t.Run("fill-or-kill only trades in full", func(t *testing.T) {
+		m := setup(STPNone)
+		assert.Empty(t, m.AddOrder(Bid, store.NewEntityID(4), carol, sdk.NewUint(9), sdk.NewUint(15), FillOrKill))
+		assert.Len(t, m.asks, 3)
+		events := m.AddOrder(Bid, store.NewEntityID(5), carol, sdk.NewUint(10), sdk.NewUint(15), FillOrKill)
+		require.Len(t, events, 2)
+		assertTrade(t, trade(10, 5, 2, 15, 5, 0), events[1].Trade)
+	})
+	t.Run("cancel newest drops the incoming order", func(t *testing.T) {
+		m := setup(STPCancelNewest)
+		events := m.AddOrder(Bid, store.NewEntityID(4), bob, sdk.NewUint(10), sdk.NewUint(5), Limit)
+		require.Len(t, events, 1)
+		require.NotNil(t, events[0].SelfTrade)
+		assert.True(t, events[0].SelfTrade.OrderID.Equals(store.NewEntityID(4)))
+		assert.True(t, events[0].SelfTrade.QtyRemaining.IsZero())
+		assert.Empty(t, m.bids)
+		assert.Len(t, m.asks, 3)
+	})
+	t.Run("cancel oldest drops the resting order and keeps matching", func(t *testing.T) {
+		m := setup(STPCancelOldest)
+		events := m.AddOrder(Bid, store.NewEntityID(4), bob, sdk.NewUint(10), sdk.NewUint(5), Limit)
+		require.Len(t, events, 2)
+		require.NotNil(t, events[0].SelfTrade)
+		assert.True(t, events[0].SelfTrade.OrderID.Equals(store.NewEntityID(1)))
+		assertTrade(t, trade(10, 5, 2, 15, 4, 0), events[1].Trade)
+	})
+	t.Run("decrement both reduces each order", func(t *testing.T) {
+		m := setup(STPDecrementBoth)
+		events := m.AddOrder(Bid, store.NewEntityID(4), bob, sdk.NewUint(10), sdk.NewUint(15), Limit)
+		require.Len(t, events, 3)
+		assert.Equal(t, "0", events[0].SelfTrade.QtyRemaining.String())
+		assert.Equal(t, "5", events[1].SelfTrade.QtyRemaining.String())
+		assertTrade(t, trade(10, 5, 2, 15, 4, 0), events[2].Trade)
+	})
+	t.Run("passes over trades worth less than a quote unit", func(t *testing.T) {
+		m := NewContinuousMatcher(STPNone, AssetDecimals, AssetDecimals)
+		m.AddOrder(Ask, store.NewEntityID(1), bob, sdk.NewUint(1), sdk.NewUint(1000000000), Limit)
+		assert.Empty(t, m.AddOrder(Bid, store.NewEntityID(2), carol, sdk.NewUint(2), sdk.NewUint(50000000), Limit))
+		require.Len(t, m.bids, 1)
+		require.Len(t, m.asks, 1)
+
+		events := m.AddOrder(Bid, store.NewEntityID(3), carol, sdk.NewUint(2), sdk.NewUint(100000000), Limit)
+		require.Len(t, events, 1)
+		assertTrade(t, trade(1, 100000000, 1, 900000000, 3, 0), events[0].Trade)
+		assert.Len(t, m.bids, 1)
+	})
+}
@@ -13,16 +13,26 @@ import (
 
 type Order struct {
 	ID       store.EntityID
+	Owner    sdk.AccAddress
 	Price    sdk.Uint
 	Quantity sdk.Uint
 }
 
+// MatchResults is the outcome of a batch. AggSupply and AggDemand are the
+// cumulative supply and demand at the clearing price, and ProRata is their
+// ratio, which the fills of the larger side are scaled by. Bids and Asks are
+// the orders the batch was matched with, lowest price first.
 type MatchResults struct {
 	ClearingPrice sdk.Uint
 	Fills         []Fill
 	MatchTable    []MatchEntry
 	BidAggregates []AggregatePrice
 	AskAggregates []AggregatePrice
+	AggSupply     sdk.Uint
+	AggDemand     sdk.Uint
+	ProRata       sdk.Dec
+	Bids          []Order
+	Asks          []Order
 }
 
 type Matcher struct {
@@ -54,11 +64,18 @@ func NewMatcher() *Matcher {
 // other degen case: no overlap.
 
 func (m *Matcher) EnqueueOrder(oType Direction, id store.EntityID, price sdk.Uint, quantity sdk.Uint) {
+	m.EnqueueOwnedOrder(oType, id, nil, price, quantity)
+}
+
+// EnqueueOwnedOrder enqueues an order along with its owner, which is what
+// PreventSelfTrades uses to find orders that would trade with each other.
+func (m *Matcher) EnqueueOwnedOrder(oType Direction, id store.EntityID, owner sdk.AccAddress, price sdk.Uint, quantity sdk.Uint) {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
 	order := &Order{
 		ID:       id,
+		Owner:    owner,
 		Price:    price,
 		Quantity: quantity,
 	}
@@ -319,6 +336,11 @@ func (m *Matcher) Match() *MatchResults {
 		MatchTable:    matchTable,
 		BidAggregates: bidAggs,
 		AskAggregates: askAggs,
+		AggSupply:     aggSupply,
+		AggDemand:     aggDemand,
+		ProRata:       proRataDec,
+		Bids:          append([]Order(nil), m.bids...),
+		Asks:          append([]Order(nil), m.asks...),
 	}
 }
 
@@ -0,0 +1,64 @@
+package matcheng
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	BatchAuction MatchingMode = iota
+	Continuous
+)
+
+// MatchingMode selects how a market's orders are matched: in a frequent batch
+// auction that clears every order of a block at a single price, or
+// continuously in price-time priority, each trade executing at the price of
+// the resting order.
+type MatchingMode uint8
+
+var matchingModeNames = map[MatchingMode]string{
+	BatchAuction: "BATCH_AUCTION",
+	Continuous:   "CONTINUOUS",
+}
+
+func NewMatchingModeFromString(str string) (MatchingMode, error) {
+	for mode, name := range matchingModeNames {
+		if name == str {
+			return mode, nil
+		}
+	}
+	return BatchAuction, errors.New("invalid matching mode")
+}
+
+func (m MatchingMode) IsValid() bool {
+	_, ok := matchingModeNames[m]
+	return ok
+}
+
+func (m MatchingMode) String() string {
+	name, ok := matchingModeNames[m]
+	if !ok {
+		return "UNKNOWN"
+	}
+	return name
+}
+
+func (m *MatchingMode) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+
+	out, err := NewMatchingModeFromString(str)
+	if err != nil {
+		return err
+	}
+
+	*m = out
+	return nil
+}
+
+func (m MatchingMode) MarshalJSON() ([]byte, error) {
+	return []byte("\"" + m.String() + "\""), nil
+}
@@ -2,7 +2,7 @@ package matcheng
 
 import (
 	"errors"
-	"math"
+	"math/big"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
@@ -10,19 +10,56 @@ import (
 )
 
 const (
+	// AssetDecimals is the precision assumed for assets of markets that do
+	// not specify their own.
 	AssetDecimals = 8
+	// MaxAssetDecimals is the highest precision a market's asset may have.
+	MaxAssetDecimals = 18
+	// PriceDecimals is the fixed-point precision of prices: a price is the
+	// number of whole quote asset units per whole base asset unit, times 10^8.
+	PriceDecimals = 8
 )
 
-var divisor = sdk.NewDec(int64(math.Pow(float64(10), float64(AssetDecimals))))
-
 func NormalizeQuoteQuantity(quotePrice sdk.Uint, baseQuantity sdk.Uint) (sdk.Uint, error) {
-	quotePDec := sdk.NewDecFromBigInt(conv.SDKUint2Big(quotePrice))
-	baseQDec := sdk.NewDecFromBigInt(conv.SDKUint2Big(baseQuantity))
-	baseMult := baseQDec.Quo(divisor)
-	res := sdk.NewUintFromBigInt(quotePDec.Mul(baseMult).TruncateInt().BigInt())
+	return NormalizeQuoteQuantityWithDecimals(quotePrice, baseQuantity, AssetDecimals, AssetDecimals)
+}
+
+// NormalizeQuoteQuantityWithDecimals returns the quote asset amount, in base
+// units, that baseQuantity base units are worth at quotePrice, for a market
+// whose assets have the given number of decimals. The result is rounded down.
+func NormalizeQuoteQuantityWithDecimals(quotePrice sdk.Uint, baseQuantity sdk.Uint, baseDecimals uint8, quoteDecimals uint8) (sdk.Uint, error) {
+	num := new(big.Int).Mul(conv.SDKUint2Big(quotePrice), conv.SDKUint2Big(baseQuantity))
+	num.Mul(num, pow10(quoteDecimals))
+	res := sdk.NewUintFromBigInt(num.Quo(num, pow10(PriceDecimals+baseDecimals)))
 	var err error
 	if res.IsZero() {
 		err = errors.New("quantity too small to represent")
 	}
 	return res, err
 }
+
+// PriceFromQuoteQuantity is the inverse of NormalizeQuoteQuantity: it returns
+// the highest price at which baseQuantity costs no more than quoteAmount.
+func PriceFromQuoteQuantity(quoteAmount sdk.Uint, baseQuantity sdk.Uint) (sdk.Uint, error) {
+	return PriceFromQuoteQuantityWithDecimals(quoteAmount, baseQuantity, AssetDecimals, AssetDecimals)
+}
+
+// PriceFromQuoteQuantityWithDecimals is the inverse of
+// NormalizeQuoteQuantityWithDecimals.
+func PriceFromQuoteQuantityWithDecimals(quoteAmount sdk.Uint, baseQuantity sdk.Uint, baseDecimals uint8, quoteDecimals uint8) (sdk.Uint, error) {
+	if baseQuantity.IsZero() {
+		return sdk.ZeroUint(), errors.New("quantity cannot be zero")
+	}
+	num := new(big.Int).Mul(conv.SDKUint2Big(quoteAmount), pow10(PriceDecimals+baseDecimals))
+	den := new(big.Int).Mul(conv.SDKUint2Big(baseQuantity), pow10(quoteDecimals))
+	res := sdk.NewUintFromBigInt(num.Quo(num, den))
+	var err error
+	if res.IsZero() {
+		err = errors.New("price too small to represent")
+	}
+	return res, err
+}
+
+func pow10(exp uint8) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
@@ -33,3 +33,42 @@ func TestNormalizeQuoteQuantity(t *testing.T) {
 	}
 
 }
+
+func TestPriceFromQuoteQuantity(t *testing.T) {
+	res, err := PriceFromQuoteQuantity(testutil.ToBaseUnits(20), testutil.ToBaseUnits(10))
+	require.NoError(t, err)
+	testutil.AssertEqualUints(t, testutil.ToBaseUnits(2), res)
+
+	quote, err := NormalizeQuoteQuantity(res, testutil.ToBaseUnits(10))
+	require.NoError(t, err)
+	testutil.AssertEqualUints(t, testutil.ToBaseUnits(20), quote)
+
+	_, err = PriceFromQuoteQuantity(sdk.NewUint(1), testutil.ToBaseUnits(1000000000))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "price too small to represent")
+
+	_, err = PriceFromQuoteQuantity(sdk.NewUint(1), sdk.ZeroUint())
+	assert.Error(t, err)
+}
+
+func TestNormalizeQuoteQuantityWithDecimals(t *testing.T) {
+	// 2.5 whole quote units per whole base unit
+	price := testutil.ToBaseUnitsDecimals(25, 1)
+
+	// 3 units of a 6 decimal base asset cost 7.5 units of an 18 decimal quote asset
+	res, err := NormalizeQuoteQuantityWithDecimals(price, sdk.NewUint(3000000), 6, 18)
+	require.NoError(t, err)
+	testutil.AssertEqualUints(t, sdk.NewUintFromString("7500000000000000000"), res)
+
+	// 3 units of an 18 decimal base asset cost 7.5 units of a 2 decimal quote asset
+	res, err = NormalizeQuoteQuantityWithDecimals(price, sdk.NewUintFromString("3000000000000000000"), 18, 2)
+	require.NoError(t, err)
+	testutil.AssertEqualUints(t, sdk.NewUint(750), res)
+
+	p, err := PriceFromQuoteQuantityWithDecimals(sdk.NewUint(750), sdk.NewUintFromString("3000000000000000000"), 18, 2)
+	require.NoError(t, err)
+	testutil.AssertEqualUints(t, price, p)
+
+	_, err = NormalizeQuoteQuantityWithDecimals(price, sdk.NewUint(1), 18, 2)
+	assert.Error(t, err)
+}
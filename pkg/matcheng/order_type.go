@@ -0,0 +1,71 @@
+package matcheng
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	Limit OrderType = iota
+	Market
+	ImmediateOrCancel
+	FillOrKill
+)
+
+type OrderType uint8
+
+var orderTypeNames = map[OrderType]string{
+	Limit:             "LIMIT",
+	Market:            "MARKET",
+	ImmediateOrCancel: "IOC",
+	FillOrKill:        "FOK",
+}
+
+func NewOrderTypeFromString(str string) (OrderType, error) {
+	for t, name := range orderTypeNames {
+		if name == str {
+			return t, nil
+		}
+	}
+	return Limit, errors.New("invalid order type")
+}
+
+func (t OrderType) IsValid() bool {
+	_, ok := orderTypeNames[t]
+	return ok
+}
+
+// Rests reports whether unfilled quantity of an order of this type stays on
+// the book after the batch it was posted in. Every other type has its
+// remainder refunded once that batch is executed.
+func (t OrderType) Rests() bool {
+	return t == Limit
+}
+
+func (t OrderType) String() string {
+	name, ok := orderTypeNames[t]
+	if !ok {
+		return "UNKNOWN"
+	}
+	return name
+}
+
+func (t *OrderType) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+
+	out, err := NewOrderTypeFromString(str)
+	if err != nil {
+		return err
+	}
+
+	*t = out
+	return nil
+}
+
+func (t OrderType) MarshalJSON() ([]byte, error) {
+	return []byte("\"" + t.String() + "\""), nil
+}
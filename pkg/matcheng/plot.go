@@ -3,39 +3,183 @@ package matcheng
 import (
 	"bytes"
 	"fmt"
+	"sort"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	svgWidth  = 800
+	svgHeight = 400
+	svgMargin = 40
 )
 
+// AggregateBook returns the cumulative aggregates of a book in the form Match
+// reports them: each bid aggregate is the quantity bid at or above its price,
+// and each ask aggregate the quantity offered at or below it. Both are sorted
+// lowest price first.
+func AggregateBook(bids []Order, asks []Order) ([]AggregatePrice, []AggregatePrice) {
+	return aggregate(bids, true), aggregate(asks, false)
+}
+
+func aggregate(orders []Order, fromTop bool) []AggregatePrice {
+	sorted := append([]Order(nil), orders...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if fromTop {
+			return sorted[i].Price.GT(sorted[j].Price)
+		}
+		return sorted[i].Price.LT(sorted[j].Price)
+	})
+
+	out := make([]AggregatePrice, 0)
+	total := zero
+	for _, ord := range sorted {
+		total = total.Add(ord.Quantity)
+		if len(out) > 0 && out[len(out)-1][0].Equal(ord.Price) {
+			out[len(out)-1][1] = total
+			continue
+		}
+		out = append(out, AggregatePrice{ord.Price, total})
+	}
+
+	if fromTop {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+	return out
+}
+
+// PlotCurves renders the supply and demand curves of a batch as gnuplot data.
 func PlotCurves(bids []AggregatePrice, asks []AggregatePrice) string {
 	var buf bytes.Buffer
 	buf.WriteString("\"Ask\"\n")
+	for _, p := range askCurve(asks) {
+		buf.WriteString(fmt.Sprintf("%s %s\n", p[0], p[1]))
+	}
+
+	buf.WriteString("\n\n")
+	buf.WriteString("\"Bid\"\n")
+	for _, p := range bidCurve(bids) {
+		buf.WriteString(fmt.Sprintf("%s %s\n", p[0], p[1]))
+	}
+
+	out := buf.Bytes()
+	return string(out)
+}
+
+// PlotCSV renders the aggregates of a batch as CSV, one row per price level.
+func PlotCSV(bids []AggregatePrice, asks []AggregatePrice) string {
+	var buf bytes.Buffer
+	buf.WriteString("side,price,quantity\n")
+	for _, entry := range bids {
+		buf.WriteString(fmt.Sprintf("bid,%s,%s\n", entry[0], entry[1]))
+	}
+	for _, entry := range asks {
+		buf.WriteString(fmt.Sprintf("ask,%s,%s\n", entry[0], entry[1]))
+	}
+	return buf.String()
+}
+
+// PlotSVG renders the supply and demand curves of a batch as an SVG image,
+// with price on the x axis and cumulative quantity on the y axis.
+func PlotSVG(bids []AggregatePrice, asks []AggregatePrice) string {
+	askPoints := askCurve(asks)
+	bidPoints := bidCurve(bids)
+
+	maxPrice, maxQty := 0.0, 0.0
+	for _, p := range append(askPoints, bidPoints...) {
+		if x := uintFloat(p[0]); x > maxPrice {
+			maxPrice = x
+		}
+		if y := uintFloat(p[1]); y > maxQty {
+			maxQty = y
+		}
+	}
+	scale := func(p [2]sdk.Uint) string {
+		x, y := float64(svgMargin), float64(svgHeight-svgMargin)
+		if maxPrice > 0 {
+			x += uintFloat(p[0]) / maxPrice * (svgWidth - 2*svgMargin)
+		}
+		if maxQty > 0 {
+			y -= uintFloat(p[1]) / maxQty * (svgHeight - 2*svgMargin)
+		}
+		return fmt.Sprintf("%.2f,%.2f", x, y)
+	}
+	polyline := func(points [][2]sdk.Uint, color string) string {
+		var buf bytes.Buffer
+		for i, p := range points {
+			if i > 0 {
+				buf.WriteString(" ")
+			}
+			buf.WriteString(scale(p))
+		}
+		return fmt.Sprintf("<polyline fill=\"none\" stroke=\"%s\" stroke-width=\"2\" points=\"%s\"/>\n", color, buf.String())
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", svgWidth, svgHeight, svgWidth, svgHeight))
+	buf.WriteString(fmt.Sprintf("<rect width=\"%d\" height=\"%d\" fill=\"white\"/>\n", svgWidth, svgHeight))
+	buf.WriteString(fmt.Sprintf(
+		"<path fill=\"none\" stroke=\"black\" d=\"M%d,%d V%d H%d\"/>\n",
+		svgMargin, svgMargin, svgHeight-svgMargin, svgWidth-svgMargin,
+	))
+	buf.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" font-size=\"12\" text-anchor=\"end\">%s</text>\n", svgWidth-svgMargin, svgHeight-svgMargin/2, formatFloat(maxPrice)))
+	buf.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" font-size=\"12\">%s</text>\n", svgMargin/4, svgMargin-8, formatFloat(maxQty)))
+	buf.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" font-size=\"12\" fill=\"red\">Ask</text>\n", svgWidth-svgMargin, svgMargin))
+	buf.WriteString(fmt.Sprintf("<text x=\"%d\" y=\"%d\" font-size=\"12\" fill=\"green\">Bid</text>\n", svgWidth-svgMargin, svgMargin+16))
+	if len(askPoints) > 0 {
+		buf.WriteString(polyline(askPoints, "red"))
+	}
+	if len(bidPoints) > 0 {
+		buf.WriteString(polyline(bidPoints, "green"))
+	}
+	buf.WriteString("</svg>\n")
+	return buf.String()
+}
 
+// askCurve returns the points of the supply curve, a step up at every ask
+// price level.
+func askCurve(asks []AggregatePrice) [][2]sdk.Uint {
+	var out [][2]sdk.Uint
 	for i, entry := range asks {
 		if i == 0 {
-			buf.WriteString(fmt.Sprintf("%s 0\n", entry[0]))
+			out = append(out, [2]sdk.Uint{entry[0], zero})
 		}
 		if i > 0 {
-			buf.WriteString(fmt.Sprintf("%s %s\n", entry[0], asks[i-1][1]))
+			out = append(out, [2]sdk.Uint{entry[0], asks[i-1][1]})
 		}
-		buf.WriteString(fmt.Sprintf("%s %s\n", entry[0], entry[1]))
+		out = append(out, [2]sdk.Uint{entry[0], entry[1]})
 	}
+	return out
+}
 
-	buf.WriteString("\n\n")
-	buf.WriteString("\"Bid\"\n")
-
+// bidCurve returns the points of the demand curve, from the highest bid
+// price level down to a price of zero.
+func bidCurve(bids []AggregatePrice) [][2]sdk.Uint {
+	var out [][2]sdk.Uint
 	for i := len(bids) - 1; i >= 0; i-- {
 		entry := bids[i]
 		if i == len(bids)-1 {
-			buf.WriteString(fmt.Sprintf("%s 0\n", entry[0]))
+			out = append(out, [2]sdk.Uint{entry[0], zero})
 		}
 		if i != len(bids)-1 {
-			buf.WriteString(fmt.Sprintf("%s %s\n", entry[0], bids[i+1][1]))
+			out = append(out, [2]sdk.Uint{entry[0], bids[i+1][1]})
 		}
-		buf.WriteString(fmt.Sprintf("%s %s\n", entry[0], entry[1]))
+		out = append(out, [2]sdk.Uint{entry[0], entry[1]})
 		if i == 0 {
-			buf.WriteString(fmt.Sprintf("0 %s\n", entry[1]))
+			out = append(out, [2]sdk.Uint{zero, entry[1]})
 		}
 	}
+	return out
+}
 
-	out := buf.Bytes()
-	return string(out)
+func uintFloat(u sdk.Uint) float64 {
+	f, _ := strconv.ParseFloat(u.String(), 64)
+	return f
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
 }
@@ -1,10 +1,12 @@
 package matcheng
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/xar-network/xar-network/testutil/testflags"
+	"github.com/xar-network/xar-network/types/store"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
@@ -47,3 +49,50 @@ func TestPlotCurves(t *testing.T) {
 	assert.Equal(t, expected, actual)
 
 }
+
+func TestAggregateBook(t *testing.T) {
+	testflags.UnitTest(t)
+	bids := []Order{
+		{ID: store.NewEntityID(1), Price: sdk.NewUint(2), Quantity: sdk.NewUint(10)},
+		{ID: store.NewEntityID(2), Price: sdk.NewUint(3), Quantity: sdk.NewUint(10)},
+		{ID: store.NewEntityID(3), Price: sdk.NewUint(1), Quantity: sdk.NewUint(10)},
+		{ID: store.NewEntityID(4), Price: sdk.NewUint(2), Quantity: sdk.NewUint(5)},
+	}
+	asks := []Order{
+		{ID: store.NewEntityID(5), Price: sdk.NewUint(5), Quantity: sdk.NewUint(10)},
+		{ID: store.NewEntityID(6), Price: sdk.NewUint(4), Quantity: sdk.NewUint(10)},
+	}
+
+	bidAggs, askAggs := AggregateBook(bids, asks)
+	assert.Equal(t, []AggregatePrice{
+		{sdk.NewUint(1), sdk.NewUint(35)},
+		{sdk.NewUint(2), sdk.NewUint(25)},
+		{sdk.NewUint(3), sdk.NewUint(10)},
+	}, bidAggs)
+	assert.Equal(t, []AggregatePrice{
+		{sdk.NewUint(4), sdk.NewUint(10)},
+		{sdk.NewUint(5), sdk.NewUint(20)},
+	}, askAggs)
+
+	t.Run("renders CSV", func(t *testing.T) {
+		expected := `side,price,quantity
+bid,1,35
+bid,2,25
+bid,3,10
+ask,4,10
+ask,5,20
+`
+		assert.Equal(t, expected, PlotCSV(bidAggs, askAggs))
+	})
+	t.Run("renders SVG", func(t *testing.T) {
+		svg := PlotSVG(bidAggs, askAggs)
+		assert.True(t, strings.HasPrefix(svg, "<svg"))
+		assert.Equal(t, 2, strings.Count(svg, "<polyline"))
+	})
+	t.Run("renders an empty book", func(t *testing.T) {
+		bidAggs, askAggs := AggregateBook(nil, nil)
+		assert.Empty(t, bidAggs)
+		assert.Empty(t, askAggs)
+		assert.NotContains(t, PlotSVG(bidAggs, askAggs), "<polyline")
+	})
+}
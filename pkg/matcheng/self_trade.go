@@ -0,0 +1,179 @@
+package matcheng
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	STPNone SelfTradePrevention = iota
+	STPCancelNewest
+	STPCancelOldest
+	STPDecrementBoth
+)
+
+// SelfTradePrevention selects what happens when a bid and an ask from the
+// same owner cross each other in a batch.
+type SelfTradePrevention uint8
+
+var stpNames = map[SelfTradePrevention]string{
+	STPNone:          "NONE",
+	STPCancelNewest:  "CANCEL_NEWEST",
+	STPCancelOldest:  "CANCEL_OLDEST",
+	STPDecrementBoth: "DECREMENT_BOTH",
+}
+
+func NewSelfTradePreventionFromString(str string) (SelfTradePrevention, error) {
+	for mode, name := range stpNames {
+		if name == str {
+			return mode, nil
+		}
+	}
+	return STPNone, errors.New("invalid self-trade prevention mode")
+}
+
+func (s SelfTradePrevention) IsValid() bool {
+	_, ok := stpNames[s]
+	return ok
+}
+
+func (s SelfTradePrevention) String() string {
+	name, ok := stpNames[s]
+	if !ok {
+		return "UNKNOWN"
+	}
+	return name
+}
+
+func (s *SelfTradePrevention) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+
+	out, err := NewSelfTradePreventionFromString(str)
+	if err != nil {
+		return err
+	}
+
+	*s = out
+	return nil
+}
+
+func (s SelfTradePrevention) MarshalJSON() ([]byte, error) {
+	return []byte("\"" + s.String() + "\""), nil
+}
+
+// SelfTrade records an order that was reduced to prevent a self-trade.
+// QtyRemaining is zero when the order was removed from the batch entirely.
+type SelfTrade struct {
+	OrderID      store.EntityID
+	QtyCancelled sdk.Uint
+	QtyRemaining sdk.Uint
+}
+
+// PreventSelfTrades resolves every bid and ask of the same owner that cross
+// each other, before the batch is matched. Owners are visited from the highest
+// bid down and each owner's highest bid is paired with their lowest ask until
+// their orders no longer cross. Orders enqueued without an owner are ignored.
+func (m *Matcher) PreventSelfTrades(mode SelfTradePrevention) []SelfTrade {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if mode == STPNone || len(m.bids) == 0 || len(m.asks) == 0 {
+		return nil
+	}
+
+	// indices into m.bids (highest first) and m.asks (lowest first) by owner
+	var owners []string
+	bidsByOwner := make(map[string][]int)
+	asksByOwner := make(map[string][]int)
+	for i := len(m.bids) - 1; i >= 0; i-- {
+		owner := string(m.bids[i].Owner)
+		if owner == "" {
+			continue
+		}
+		if _, ok := bidsByOwner[owner]; !ok {
+			owners = append(owners, owner)
+		}
+		bidsByOwner[owner] = append(bidsByOwner[owner], i)
+	}
+	for i := range m.asks {
+		owner := string(m.asks[i].Owner)
+		if _, ok := bidsByOwner[owner]; ok {
+			asksByOwner[owner] = append(asksByOwner[owner], i)
+		}
+	}
+
+	var out []SelfTrade
+	reduce := func(o *Order, qty sdk.Uint) {
+		out = append(out, reduceOrder(o, qty))
+	}
+	for _, owner := range owners {
+		bids, asks := bidsByOwner[owner], asksByOwner[owner]
+		for len(bids) > 0 && len(asks) > 0 {
+			bid, ask := &m.bids[bids[0]], &m.asks[asks[0]]
+			if bid.Price.LT(ask.Price) {
+				break
+			}
+
+			switch mode {
+			case STPCancelNewest, STPCancelOldest:
+				newest, oldest := bid, ask
+				if bid.ID.Cmp(ask.ID) < 0 {
+					newest, oldest = ask, bid
+				}
+				if mode == STPCancelNewest {
+					reduce(newest, newest.Quantity)
+				} else {
+					reduce(oldest, oldest.Quantity)
+				}
+			case STPDecrementBoth:
+				qty := bid.Quantity
+				if ask.Quantity.LT(qty) {
+					qty = ask.Quantity
+				}
+				reduce(bid, qty)
+				reduce(ask, qty)
+			}
+
+			if bid.Quantity.IsZero() {
+				bids = bids[1:]
+			}
+			if ask.Quantity.IsZero() {
+				asks = asks[1:]
+			}
+		}
+	}
+
+	if len(out) > 0 {
+		m.bids = withoutEmpty(m.bids)
+		m.asks = withoutEmpty(m.asks)
+	}
+	return out
+}
+
+// reduceOrder cancels qty of o and records it as a self-trade.
+func reduceOrder(o *Order, qty sdk.Uint) SelfTrade {
+	o.Quantity = o.Quantity.Sub(qty)
+	return SelfTrade{
+		OrderID:      o.ID,
+		QtyCancelled: qty,
+		QtyRemaining: o.Quantity,
+	}
+}
+
+func withoutEmpty(orders []Order) []Order {
+	out := orders[:0]
+	for _, o := range orders {
+		if !o.Quantity.IsZero() {
+			out = append(out, o)
+		}
+	}
+	return out
+}
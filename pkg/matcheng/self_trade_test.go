@@ -0,0 +1,75 @@
+package matcheng
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/xar-network/xar-network/testutil/testflags"
+	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestMatcher_PreventSelfTrades(t *testing.T) {
+	testflags.UnitTest(t)
+	alice := sdk.AccAddress([]byte("alice"))
+	bob := sdk.AccAddress([]byte("bob"))
+
+	// alice's bid (1) and ask (2) cross; bob only trades with alice
+	setup := func() *Matcher {
+		m := NewMatcher()
+		m.EnqueueOwnedOrder(Bid, store.NewEntityID(1), alice, sdk.NewUint(10), sdk.NewUint(100))
+		m.EnqueueOwnedOrder(Ask, store.NewEntityID(2), alice, sdk.NewUint(9), sdk.NewUint(40))
+		m.EnqueueOwnedOrder(Ask, store.NewEntityID(3), bob, sdk.NewUint(9), sdk.NewUint(50))
+		m.EnqueueOwnedOrder(Bid, store.NewEntityID(4), alice, sdk.NewUint(8), sdk.NewUint(10))
+		return m
+	}
+	assertSelfTrade := func(t *testing.T, exp SelfTrade, actual SelfTrade) {
+		assert.True(t, exp.OrderID.Equals(actual.OrderID), "order ID")
+		assert.Equal(t, exp.QtyCancelled.String(), actual.QtyCancelled.String())
+		assert.Equal(t, exp.QtyRemaining.String(), actual.QtyRemaining.String())
+	}
+
+	t.Run("none leaves the book untouched", func(t *testing.T) {
+		m := setup()
+		assert.Empty(t, m.PreventSelfTrades(STPNone))
+		assert.Len(t, m.bids, 2)
+		assert.Len(t, m.asks, 2)
+	})
+	t.Run("cancel newest removes the later order", func(t *testing.T) {
+		m := setup()
+		res := m.PreventSelfTrades(STPCancelNewest)
+		assert.Len(t, res, 1)
+		assertSelfTrade(t, SelfTrade{store.NewEntityID(2), sdk.NewUint(40), sdk.ZeroUint()}, res[0])
+		assert.Len(t, m.asks, 1)
+		assert.True(t, m.asks[0].ID.Equals(store.NewEntityID(3)))
+	})
+	t.Run("cancel oldest removes the earlier order", func(t *testing.T) {
+		m := setup()
+		res := m.PreventSelfTrades(STPCancelOldest)
+		assert.Len(t, res, 1)
+		assertSelfTrade(t, SelfTrade{store.NewEntityID(1), sdk.NewUint(100), sdk.ZeroUint()}, res[0])
+		assert.Len(t, m.bids, 1)
+		assert.Len(t, m.asks, 2)
+	})
+	t.Run("decrement both reduces each order by the crossing quantity", func(t *testing.T) {
+		m := setup()
+		res := m.PreventSelfTrades(STPDecrementBoth)
+		assert.Len(t, res, 2)
+		assertSelfTrade(t, SelfTrade{store.NewEntityID(1), sdk.NewUint(40), sdk.NewUint(60)}, res[0])
+		assertSelfTrade(t, SelfTrade{store.NewEntityID(2), sdk.NewUint(40), sdk.ZeroUint()}, res[1])
+
+		match := m.Match()
+		assert.NotNil(t, match)
+		for _, f := range match.Fills {
+			assert.False(t, f.OrderID.Equals(store.NewEntityID(2)))
+		}
+	})
+	t.Run("ignores orders without an owner", func(t *testing.T) {
+		m := NewMatcher()
+		m.EnqueueOrder(Bid, store.NewEntityID(1), sdk.NewUint(10), sdk.NewUint(100))
+		m.EnqueueOrder(Ask, store.NewEntityID(2), sdk.NewUint(9), sdk.NewUint(100))
+		assert.Empty(t, m.PreventSelfTrades(STPCancelNewest))
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/xar-network/xar-network/execution"
 	"github.com/xar-network/xar-network/types"
 	"github.com/xar-network/xar-network/x/market"
+	"github.com/xar-network/xar-network/x/oracle"
 	"github.com/xar-network/xar-network/x/order"
 
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -33,6 +34,7 @@ type MockApp struct {
 	SupplyKeeper    supply.Keeper
 	MarketKeeper    market.Keeper
 	OrderKeeper     order.Keeper
+	OracleKeeper    oracle.Keeper
 	BankKeeper      bank.Keeper
 	ExecutionKeeper execution.Keeper
 }
@@ -61,6 +63,7 @@ func New(t *testing.T, options ...Option) *MockApp {
 		SupplyKeeper:    dex.SupplyKeeper(),
 		MarketKeeper:    dex.MarketKeeper(),
 		OrderKeeper:     dex.OrderKeeper(),
+		OracleKeeper:    dex.OracleKeeper(),
 		BankKeeper:      dex.BankKeeper(),
 		ExecutionKeeper: dex.ExecKeeper(),
 	}
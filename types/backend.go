@@ -1,6 +1,18 @@
 package types
 
+// Backend is the publishing side of the event queue.
 type Backend interface {
 	Publish(interface{}) error
+}
+
+// Source is the consuming side of the event queue. Consume blocks until an
+// item is available and returns nil once the source is closed.
+type Source interface {
 	Consume() interface{}
 }
+
+// Acker is implemented by sources that track consumer progress. Ack marks
+// every item returned by Consume so far as handled.
+type Acker interface {
+	Ack() error
+}
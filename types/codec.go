@@ -0,0 +1,25 @@
+package types
+
+import "github.com/cosmos/cosmos-sdk/codec"
+
+// Event is any item published to the event queue.
+type Event interface{}
+
+var EventCdc = codec.New()
+
+func init() {
+	RegisterEvents(EventCdc)
+	EventCdc.Seal()
+}
+
+// RegisterEvents registers the event types with the given codec so that they
+// can be persisted by the event log.
+func RegisterEvents(cdc *codec.Codec) {
+	cdc.RegisterInterface((*Event)(nil), nil)
+	cdc.RegisterConcrete(Batch{}, "events/Batch", nil)
+	cdc.RegisterConcrete(Fill{}, "events/Fill", nil)
+	cdc.RegisterConcrete(OrderCreated{}, "events/OrderCreated", nil)
+	cdc.RegisterConcrete(OrderAmended{}, "events/OrderAmended", nil)
+	cdc.RegisterConcrete(OrderCancelled{}, "events/OrderCancelled", nil)
+	cdc.RegisterConcrete(BurnCreated{}, "events/BurnCreated", nil)
+}
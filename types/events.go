@@ -20,6 +20,13 @@ type Batch struct {
 	ClearingPrice sdk.Uint
 	Bids          []matcheng.AggregatePrice
 	Asks          []matcheng.AggregatePrice
+	MatchTable    []matcheng.MatchEntry
+	AggSupply     sdk.Uint
+	AggDemand     sdk.Uint
+	ProRata       sdk.Dec
+	BidOrders     []matcheng.Order
+	AskOrders     []matcheng.Order
+	Fills         []matcheng.Fill
 }
 
 type Fill struct {
@@ -33,6 +40,10 @@ type Fill struct {
 	BlockNumber int64
 	BlockTime   int64
 	Price       sdk.Uint
+	QuoteQty    sdk.Uint
+	Fee         sdk.Uint
+	FeeDenom    string
+	Maker       bool
 }
 
 type OrderCreated struct {
@@ -44,10 +55,23 @@ type OrderCreated struct {
 	Quantity          sdk.Uint
 	TimeInForceBlocks uint16
 	CreatedBlock      int64
+	Type              matcheng.OrderType
+}
+
+// OrderAmended is published when a resting order is repriced or reduced.
+// Quantity is the new unfilled quantity.
+type OrderAmended struct {
+	OrderID  store.EntityID
+	Price    sdk.Uint
+	Quantity sdk.Uint
+	Owner    sdk.AccAddress
+	MarketID store.EntityID
 }
 
 type OrderCancelled struct {
-	OrderID store.EntityID
+	OrderID  store.EntityID
+	Owner    sdk.AccAddress
+	MarketID store.EntityID
 }
 
 type BurnCreated struct {
@@ -0,0 +1,293 @@
+package types
+
+import (
+	"encoding/binary"
+	"sync"
+
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/xar-network/xar-network/types/store"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	logPrefix = store.TablePrefix + "/queue"
+
+	logEntryKey   = "e"
+	logHeightKey  = "h"
+	logOffsetKey  = "o"
+	logHeadKey    = "head"
+	logPendingKey = "pending"
+)
+
+// LogBackend is a Backend that persists events to an append-only log in a
+// key-value database.
+//
+// Events published while a transaction or block step runs are buffered until
+// CommitTx is called, and dropped by DiscardTx. WriteBlock persists the
+// buffered events of a block, but they only become visible to consumers once
+// Commit is called after the application state has been committed. Each
+// consumer reads the log through a named Subscription whose offset survives
+// restarts, so events are delivered at least once.
+type LogBackend struct {
+	db  dbm.DB
+	cdc *codec.Codec
+
+	mtx      sync.Mutex
+	cond     *sync.Cond
+	txBuf    [][]byte
+	blockBuf [][]byte
+	pending  *pendingBlock
+	head     uint64
+	closed   bool
+}
+
+type pendingBlock struct {
+	Height int64
+	Head   uint64
+}
+
+func NewLogBackend(db dbm.DB) *LogBackend {
+	b := &LogBackend{
+		db:  db,
+		cdc: EventCdc,
+	}
+	b.cond = sync.NewCond(&b.mtx)
+	if bz := db.Get(logKey(logHeadKey)); bz != nil {
+		b.head = binary.BigEndian.Uint64(bz)
+	}
+	return b
+}
+
+// Publish buffers an event until the current transaction is committed.
+func (b *LogBackend) Publish(item interface{}) error {
+	bz, err := b.cdc.MarshalBinaryBare(item)
+	if err != nil {
+		return err
+	}
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.txBuf = append(b.txBuf, bz)
+	return nil
+}
+
+// CommitTx adds the events published by the current transaction to the
+// current block.
+func (b *LogBackend) CommitTx() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.blockBuf = append(b.blockBuf, b.txBuf...)
+	b.txBuf = nil
+}
+
+// DiscardTx drops the events published by the current transaction.
+func (b *LogBackend) DiscardTx() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.txBuf = nil
+}
+
+// WriteBlock persists the events of the block at the given height. They are
+// not delivered to consumers until Commit is called.
+func (b *LogBackend) WriteBlock(height int64) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if len(b.blockBuf) == 0 {
+		return nil
+	}
+
+	batch := b.db.NewBatch()
+	defer batch.Close()
+	for i, bz := range b.blockBuf {
+		batch.Set(entryKey(b.head+uint64(i)), bz)
+	}
+	batch.Set(heightKey(height), store.Uint64Subkey(b.head))
+
+	pending := &pendingBlock{
+		Height: height,
+		Head:   b.head + uint64(len(b.blockBuf)),
+	}
+	pbz, err := b.cdc.MarshalBinaryBare(pending)
+	if err != nil {
+		return err
+	}
+	batch.Set(logKey(logPendingKey), pbz)
+	batch.WriteSync()
+
+	b.pending = pending
+	b.blockBuf = nil
+	return nil
+}
+
+// Commit makes the events of the last written block visible to consumers.
+func (b *LogBackend) Commit() error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if b.pending == nil {
+		return nil
+	}
+
+	batch := b.db.NewBatch()
+	defer batch.Close()
+	batch.Set(logKey(logHeadKey), store.Uint64Subkey(b.pending.Head))
+	batch.Delete(logKey(logPendingKey))
+	batch.WriteSync()
+
+	b.head = b.pending.Head
+	b.pending = nil
+	b.cond.Broadcast()
+	return nil
+}
+
+// Recover resolves a block that was written but not committed before the
+// process stopped. If the application committed that block, its events are
+// made visible; otherwise they are dropped and will be written again when the
+// block is replayed.
+func (b *LogBackend) Recover(lastHeight int64) error {
+	b.mtx.Lock()
+	b.txBuf = nil
+	b.blockBuf = nil
+	b.pending = nil
+
+	bz := b.db.Get(logKey(logPendingKey))
+	if bz == nil {
+		b.mtx.Unlock()
+		return nil
+	}
+	var pending pendingBlock
+	if err := b.cdc.UnmarshalBinaryBare(bz, &pending); err != nil {
+		b.mtx.Unlock()
+		return err
+	}
+	if pending.Height > lastHeight {
+		b.db.DeleteSync(heightKey(pending.Height))
+		b.db.DeleteSync(logKey(logPendingKey))
+		b.mtx.Unlock()
+		return nil
+	}
+	b.pending = &pending
+	b.mtx.Unlock()
+
+	return b.Commit()
+}
+
+// Head returns the offset the next committed event will be written at.
+func (b *LogBackend) Head() uint64 {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.head
+}
+
+// Range returns up to limit committed events starting at the given offset,
+// along with the offset following the last one returned.
+func (b *LogBackend) Range(from uint64, limit int) ([]Event, uint64) {
+	head := b.Head()
+	events := make([]Event, 0)
+	for ; from < head && len(events) < limit; from++ {
+		var event Event
+		b.cdc.MustUnmarshalBinaryBare(b.db.Get(entryKey(from)), &event)
+		events = append(events, event)
+	}
+	return events, from
+}
+
+// Acked returns the last offset acknowledged by the named consumer.
+func (b *LogBackend) Acked(name string) uint64 {
+	bz := b.db.Get(offsetKey(name))
+	if bz == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(bz)
+}
+
+// ReplayFrom moves the offset of the named consumer to the first event
+// committed at or after the given height. It must be called before the
+// consumer subscribes.
+func (b *LogBackend) ReplayFrom(name string, height int64) {
+	offset := b.Head()
+	prefix := append(logKey(logHeightKey), '/')
+	iter := b.db.Iterator(heightKey(height), sdk.PrefixEndBytes(prefix))
+	if iter.Valid() {
+		offset = binary.BigEndian.Uint64(iter.Value())
+	}
+	iter.Close()
+
+	b.db.SetSync(offsetKey(name), store.Uint64Subkey(offset))
+}
+
+// Subscribe returns a subscription that resumes from the last offset
+// acknowledged under the given name.
+func (b *LogBackend) Subscribe(name string) *Subscription {
+	return &Subscription{
+		b:      b,
+		name:   name,
+		offset: b.Acked(name),
+	}
+}
+
+// Close unblocks every subscription waiting for events.
+func (b *LogBackend) Close() {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	b.closed = true
+	b.cond.Broadcast()
+}
+
+// Subscription is a named reader of a LogBackend.
+type Subscription struct {
+	b      *LogBackend
+	name   string
+	offset uint64
+}
+
+// Consume blocks until a committed event is available and returns it. It
+// returns nil once the backend is closed.
+func (s *Subscription) Consume() interface{} {
+	b := s.b
+	b.mtx.Lock()
+	for s.offset >= b.head && !b.closed {
+		b.cond.Wait()
+	}
+	if b.closed {
+		b.mtx.Unlock()
+		return nil
+	}
+	bz := b.db.Get(entryKey(s.offset))
+	s.offset++
+	b.mtx.Unlock()
+
+	var event Event
+	b.cdc.MustUnmarshalBinaryBare(bz, &event)
+	return event
+}
+
+// Ack persists the offset of the subscription.
+func (s *Subscription) Ack() error {
+	s.b.db.Set(offsetKey(s.name), store.Uint64Subkey(s.offset))
+	return nil
+}
+
+func logKey(subkeys ...string) []byte {
+	buf := make([][]byte, len(subkeys))
+	for i, sk := range subkeys {
+		buf[i] = []byte(sk)
+	}
+	return store.PrefixKeyString(logPrefix, buf...)
+}
+
+func entryKey(offset uint64) []byte {
+	return store.PrefixKeyString(logPrefix, []byte(logEntryKey), store.Uint64Subkey(offset))
+}
+
+func heightKey(height int64) []byte {
+	return store.PrefixKeyString(logPrefix, []byte(logHeightKey), store.Int64Subkey(height))
+}
+
+func offsetKey(name string) []byte {
+	return logKey(logOffsetKey, name)
+}
@@ -0,0 +1,147 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func cancelled(id uint64) OrderCancelled {
+	return OrderCancelled{OrderID: store.NewEntityID(id), MarketID: store.NewEntityID(1)}
+}
+
+func commitBlock(t *testing.T, b *LogBackend, height int64, ids ...uint64) {
+	for _, id := range ids {
+		require.NoError(t, b.Publish(cancelled(id)))
+		b.CommitTx()
+	}
+	require.NoError(t, b.WriteBlock(height))
+	require.NoError(t, b.Commit())
+}
+
+func TestLogBackend(t *testing.T) {
+	t.Run("should only deliver committed transactions", func(t *testing.T) {
+		b := NewLogBackend(dbm.NewMemDB())
+		require.NoError(t, b.Publish(cancelled(1)))
+		b.CommitTx()
+		require.NoError(t, b.Publish(cancelled(2)))
+		b.DiscardTx()
+		require.NoError(t, b.WriteBlock(1))
+		assert.EqualValues(t, 0, b.Head())
+		require.NoError(t, b.Commit())
+		assert.EqualValues(t, 1, b.Head())
+
+		sub := b.Subscribe("test")
+		assert.Equal(t, cancelled(1), sub.Consume())
+	})
+
+	t.Run("should resume from the acknowledged offset", func(t *testing.T) {
+		db := dbm.NewMemDB()
+		b := NewLogBackend(db)
+		commitBlock(t, b, 1, 1, 2)
+
+		sub := b.Subscribe("test")
+		assert.Equal(t, cancelled(1), sub.Consume())
+		require.NoError(t, sub.Ack())
+		assert.Equal(t, cancelled(2), sub.Consume())
+
+		b = NewLogBackend(db)
+		assert.EqualValues(t, 2, b.Head())
+		sub = b.Subscribe("test")
+		assert.Equal(t, cancelled(2), sub.Consume())
+	})
+
+	t.Run("should replay from a height", func(t *testing.T) {
+		b := NewLogBackend(dbm.NewMemDB())
+		commitBlock(t, b, 1, 1)
+		commitBlock(t, b, 3, 2, 3)
+		commitBlock(t, b, 4, 4)
+
+		b.ReplayFrom("test", 2)
+		sub := b.Subscribe("test")
+		assert.Equal(t, cancelled(2), sub.Consume())
+
+		b.ReplayFrom("test", 5)
+		sub = b.Subscribe("test")
+		b.Close()
+		assert.Nil(t, sub.Consume())
+	})
+
+	t.Run("should recover a written block", func(t *testing.T) {
+		db := dbm.NewMemDB()
+		b := NewLogBackend(db)
+		require.NoError(t, b.Publish(cancelled(1)))
+		b.CommitTx()
+		require.NoError(t, b.WriteBlock(1))
+
+		b = NewLogBackend(db)
+		require.NoError(t, b.Recover(0))
+		assert.EqualValues(t, 0, b.Head())
+
+		require.NoError(t, b.Publish(cancelled(2)))
+		b.CommitTx()
+		require.NoError(t, b.WriteBlock(1))
+
+		b = NewLogBackend(db)
+		require.NoError(t, b.Recover(1))
+		assert.EqualValues(t, 1, b.Head())
+		assert.Equal(t, cancelled(2), b.Subscribe("test").Consume())
+	})
+}
+
+func TestEventCdc(t *testing.T) {
+	events := []interface{}{
+		Batch{
+			BlockNumber:   1,
+			MarketID:      store.NewEntityID(1),
+			ClearingPrice: sdk.NewUint(100),
+			AggSupply:     sdk.NewUint(10),
+			AggDemand:     sdk.NewUint(10),
+			ProRata:       sdk.OneDec(),
+		},
+		Fill{
+			OrderID:     store.NewEntityID(1),
+			MarketID:    store.NewEntityID(1),
+			QtyFilled:   sdk.NewUint(10),
+			QtyUnfilled: sdk.ZeroUint(),
+			Price:       sdk.NewUint(100),
+			QuoteQty:    sdk.NewUint(10),
+			Fee:         sdk.ZeroUint(),
+			Maker:       true,
+		},
+		OrderAmended{OrderID: store.NewEntityID(1), Price: sdk.NewUint(1), Quantity: sdk.NewUint(1), MarketID: store.NewEntityID(1)},
+		cancelled(1),
+	}
+	for _, ev := range events {
+		bz, err := EventCdc.MarshalBinaryBare(ev)
+		require.NoError(t, err)
+		var res Event
+		require.NoError(t, EventCdc.UnmarshalBinaryBare(bz, &res))
+		assert.Equal(t, ev, res)
+	}
+}
+
+func TestLogBackend_Range(t *testing.T) {
+	b := NewLogBackend(dbm.NewMemDB())
+	commitBlock(t, b, 1, 1, 2, 3)
+	require.NoError(t, b.Publish(cancelled(4)))
+	b.CommitTx()
+
+	events, next := b.Range(1, 10)
+	assert.Equal(t, []Event{cancelled(2), cancelled(3)}, events)
+	assert.EqualValues(t, 3, next)
+
+	events, next = b.Range(0, 1)
+	assert.Equal(t, []Event{cancelled(1)}, events)
+	assert.EqualValues(t, 1, next)
+
+	events, next = b.Range(3, 10)
+	assert.Empty(t, events)
+	assert.EqualValues(t, 3, next)
+}
@@ -7,20 +7,23 @@ import (
 )
 
 type LocalConsumer struct {
-	queue    Backend
-	quitCh   chan bool
+	queue    Source
+	quitCh   chan struct{}
 	handlers []EventHandler
 	lgr      tmlog.Logger
 }
 
-func NewLocalConsumer(queue Backend, hdlrs []EventHandler) *LocalConsumer {
+func NewLocalConsumer(queue Source, hdlrs []EventHandler) *LocalConsumer {
 	return &LocalConsumer{
 		queue:    queue,
+		quitCh:   make(chan struct{}),
 		handlers: hdlrs,
 		lgr:      log.WithModule("local-consumer"),
 	}
 }
 
+// Start consumes items until the consumer is stopped or the queue is
+// closed. Items are acknowledged after every handler has seen them.
 func (s *LocalConsumer) Start() {
 	go func() {
 		for {
@@ -29,14 +32,20 @@ func (s *LocalConsumer) Start() {
 				return
 			default:
 				item := s.queue.Consume()
+				if item == nil {
+					return
+				}
 				s.handleItem(item)
+				s.ack()
 			}
 		}
 	}()
 }
 
+// Stop signals the consumer to exit once the item it is handling is done.
+// Closing the queue unblocks a consumer waiting for items.
 func (s *LocalConsumer) Stop() {
-	s.quitCh <- true
+	close(s.quitCh)
 }
 
 func (s *LocalConsumer) handleItem(item interface{}) {
@@ -46,3 +55,13 @@ func (s *LocalConsumer) handleItem(item interface{}) {
 		}
 	}
 }
+
+func (s *LocalConsumer) ack() {
+	acker, ok := s.queue.(Acker)
+	if !ok {
+		return
+	}
+	if err := acker.Ack(); err != nil {
+		s.lgr.Error("error acknowledging queue item", "err", err.Error())
+	}
+}
@@ -77,6 +77,13 @@ export type Market = {
   BaseAssetDenom: string
   QuoteAssetDenom: string
   Name: string
+  TickSize: string
+  LotSize: string
+  MinNotional: string
+  BaseDecimals: number
+  QuoteDecimals: number
+  Status: string
+  MatchingMode: string
 }
 
 export type MarketResonse = {
@@ -7,6 +7,7 @@ import (
 const (
 	ModuleName        = types.ModuleName
 	StoreKey          = types.StoreKey
+	RouterKey         = types.RouterKey
 	DefaultParamspace = types.DefaultParamspace
 	DefaultCodespace  = types.DefaultCodespace
 )
@@ -18,10 +18,14 @@ func GetQueryCmd(sk string, cdc *codec.Codec) *cobra.Command {
 	return marketQueryCmd
 }
 
-func GetTxCmd() *cobra.Command {
+func GetTxCmd(cdc *codec.Codec) *cobra.Command {
 	marketTxCmd := &cobra.Command{
 		Use:   "market",
 		Short: "manages available markets",
 	}
+	marketTxCmd.AddCommand(client.PostCommands(
+		GetCmdUpdateMarket(cdc),
+		GetCmdSetMarketStatus(cdc),
+	)...)
 	return marketTxCmd
 }
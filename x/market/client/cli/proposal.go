@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"bufio"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/auth/client/utils"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/cosmos/cosmos-sdk/x/gov"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/types/store"
+	"github.com/xar-network/xar-network/x/market/types"
+)
+
+// CreateMarketProposalJSON is a CreateMarketProposal with its initial deposit.
+type CreateMarketProposalJSON struct {
+	Title         string    `json:"title" yaml:"title"`
+	Description   string    `json:"description" yaml:"description"`
+	BaseAsset     string    `json:"base_asset" yaml:"base_asset"`
+	QuoteAsset    string    `json:"quote_asset" yaml:"quote_asset"`
+	BaseDecimals  uint8     `json:"base_decimals" yaml:"base_decimals"`
+	QuoteDecimals uint8     `json:"quote_decimals" yaml:"quote_decimals"`
+	Deposit       sdk.Coins `json:"deposit" yaml:"deposit"`
+}
+
+// UpdateMarketProposalJSON is an UpdateMarketProposal with its initial deposit.
+type UpdateMarketProposalJSON struct {
+	Title               string                        `json:"title" yaml:"title"`
+	Description         string                        `json:"description" yaml:"description"`
+	MarketID            store.EntityID                `json:"market_id" yaml:"market_id"`
+	MakerFeeBps         *uint16                       `json:"maker_fee_bps" yaml:"maker_fee_bps"`
+	TakerFeeBps         *uint16                       `json:"taker_fee_bps" yaml:"taker_fee_bps"`
+	SelfTradePrevention *matcheng.SelfTradePrevention `json:"self_trade_prevention" yaml:"self_trade_prevention"`
+	TickSize            *uint64                       `json:"tick_size" yaml:"tick_size"`
+	LotSize             *uint64                       `json:"lot_size" yaml:"lot_size"`
+	MinNotional         *uint64                       `json:"min_notional" yaml:"min_notional"`
+	Status              *types.Status                 `json:"status" yaml:"status"`
+	MatchingMode        *matcheng.MatchingMode        `json:"matching_mode" yaml:"matching_mode"`
+	Deposit             sdk.Coins                     `json:"deposit" yaml:"deposit"`
+}
+
+func GetCmdSubmitCreateMarketProposal(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "create-market [proposal-file]",
+		Short: "submits a proposal to create a market",
+		Long: `Submits a proposal to create a market along with an initial deposit. The
+proposal is read from a JSON file, for example:
+
+{
+  "title": "List uftm/uzar",
+  "description": "Lists a uftm/uzar market",
+  "base_asset": "uftm",
+  "quote_asset": "uzar",
+  "base_decimals": 8,
+  "quote_decimals": 8,
+  "deposit": [{"denom": "ftm", "amount": "10000"}]
+}`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var proposal CreateMarketProposalJSON
+			if err := readProposal(cdc, args[0], &proposal); err != nil {
+				return err
+			}
+			content := types.NewCreateMarketProposal(
+				proposal.Title,
+				proposal.Description,
+				proposal.BaseAsset,
+				proposal.QuoteAsset,
+				proposal.BaseDecimals,
+				proposal.QuoteDecimals,
+			)
+			return submitProposal(cmd, cdc, content, proposal.Deposit)
+		},
+	}
+}
+
+func GetCmdSubmitUpdateMarketProposal(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update-market [proposal-file]",
+		Short: "submits a proposal to change a market's settings",
+		Long: `Submits a proposal to change the fees, self-trade prevention mode, trading
+rules, status or matching mode of a market along with an initial deposit. Only
+the settings given in the proposal are changed. The proposal is read from a
+JSON file, for example:
+
+{
+  "title": "Halt market 1",
+  "description": "Halts market 1 while its oracle is investigated",
+  "market_id": "1",
+  "status": "HALTED",
+  "deposit": [{"denom": "ftm", "amount": "10000"}]
+}
+
+The other settings are maker_fee_bps, taker_fee_bps, self_trade_prevention,
+tick_size, lot_size, min_notional and matching_mode.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var proposal UpdateMarketProposalJSON
+			if err := readProposal(cdc, args[0], &proposal); err != nil {
+				return err
+			}
+			content := types.UpdateMarketProposal{
+				Title:               proposal.Title,
+				Description:         proposal.Description,
+				MarketID:            proposal.MarketID,
+				MakerFeeBps:         proposal.MakerFeeBps,
+				TakerFeeBps:         proposal.TakerFeeBps,
+				SelfTradePrevention: proposal.SelfTradePrevention,
+				TickSize:            proposal.TickSize,
+				LotSize:             proposal.LotSize,
+				MinNotional:         proposal.MinNotional,
+				Status:              proposal.Status,
+				MatchingMode:        proposal.MatchingMode,
+			}
+			return submitProposal(cmd, cdc, content, proposal.Deposit)
+		},
+	}
+}
+
+func readProposal(cdc *codec.Codec, file string, ptr interface{}) error {
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return cdc.UnmarshalJSON(contents, ptr)
+}
+
+func submitProposal(cmd *cobra.Command, cdc *codec.Codec, content gov.Content, deposit sdk.Coins) error {
+	inBuf := bufio.NewReader(cmd.InOrStdin())
+	bldr := authtypes.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+	cliCtx := context.NewCLIContextWithInput(inBuf).WithCodec(cdc)
+
+	msg := gov.NewMsgSubmitProposal(content, deposit, cliCtx.GetFromAddress())
+	if err := msg.ValidateBasic(); err != nil {
+		return err
+	}
+	return utils.GenerateOrBroadcastMsgs(cliCtx, bldr, []sdk.Msg{msg})
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/xar-network/xar-network/pkg/cliutil"
+	"github.com/xar-network/xar-network/types/store"
+	"github.com/xar-network/xar-network/x/market/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+
+	"github.com/cosmos/cosmos-sdk/x/auth/client/utils"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+)
+
+func GetCmdUpdateMarket(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "update [market-id] [tick-size] [lot-size] [min-notional]",
+		Short: "sets the trading rules of a market",
+		Long: `Sets the tick size and lot size that order prices and quantities must be
+multiples of, and the minimum quote amount an order must be worth. All three
+are in base units; 0 lifts the rule. Only nominees may update markets.`,
+		Args: cobra.ExactArgs(4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := client.NewCLIContext().WithCodec(cdc)
+			accGetter := authtypes.NewAccountRetriever(cliCtx)
+			if err := accGetter.EnsureExists(cliCtx.GetFromAddress()); err != nil {
+				return err
+			}
+			bldr := authtypes.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			marketID := store.NewEntityIDFromString(args[0])
+			tickSize, err := strconv.ParseUint(args[1], 10, 64)
+			if err != nil {
+				return err
+			}
+			lotSize, err := strconv.ParseUint(args[2], 10, 64)
+			if err != nil {
+				return err
+			}
+			minNotional, err := strconv.ParseUint(args[3], 10, 64)
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgUpdateMarket(cliCtx.GetFromAddress(), marketID, tickSize, lotSize, minNotional)
+			return cliutil.ValidateAndBroadcast(cliCtx, bldr, msg)
+		},
+	}
+}
+
+func GetCmdSetMarketStatus(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set-status [market-id] [status]",
+		Short: "halts, resumes or delists a market",
+		Long: `Sets the status of a market to one of active, halted, post_only or delisted.
+Halted markets only accept cancellations, post-only markets accept limit orders
+without matching them, and delisting cancels and refunds every order in the
+market. Delisting cannot be undone. Only nominees may change a market's status.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := client.NewCLIContext().WithCodec(cdc)
+			accGetter := authtypes.NewAccountRetriever(cliCtx)
+			if err := accGetter.EnsureExists(cliCtx.GetFromAddress()); err != nil {
+				return err
+			}
+			bldr := authtypes.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			marketID := store.NewEntityIDFromString(args[0])
+			status, err := types.NewStatusFromString(strings.ToUpper(args[1]))
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgSetMarketStatus(cliCtx.GetFromAddress(), marketID, status)
+			return cliutil.ValidateAndBroadcast(cliCtx, bldr, msg)
+		},
+	}
+}
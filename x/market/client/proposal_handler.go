@@ -0,0 +1,14 @@
+package client
+
+import (
+	govclient "github.com/cosmos/cosmos-sdk/x/gov/client"
+
+	"github.com/xar-network/xar-network/x/market/client/cli"
+	"github.com/xar-network/xar-network/x/market/client/rest"
+)
+
+// market proposal handlers
+var (
+	CreateMarketProposalHandler = govclient.NewProposalHandler(cli.GetCmdSubmitCreateMarketProposal, rest.CreateMarketProposalRESTHandler)
+	UpdateMarketProposalHandler = govclient.NewProposalHandler(cli.GetCmdSubmitUpdateMarketProposal, rest.UpdateMarketProposalRESTHandler)
+)
@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/cosmos/cosmos-sdk/client/context"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/rest"
+	"github.com/cosmos/cosmos-sdk/x/auth/client/utils"
+	"github.com/cosmos/cosmos-sdk/x/gov"
+	govrest "github.com/cosmos/cosmos-sdk/x/gov/client/rest"
+
+	"github.com/xar-network/xar-network/x/market/types"
+)
+
+// CreateMarketProposalReq is the request body for a create market proposal.
+type CreateMarketProposalReq struct {
+	BaseReq  rest.BaseReq               `json:"base_req" yaml:"base_req"`
+	Proposal types.CreateMarketProposal `json:"proposal" yaml:"proposal"`
+	Proposer sdk.AccAddress             `json:"proposer" yaml:"proposer"`
+	Deposit  sdk.Coins                  `json:"deposit" yaml:"deposit"`
+}
+
+// UpdateMarketProposalReq is the request body for an update market proposal.
+type UpdateMarketProposalReq struct {
+	BaseReq  rest.BaseReq               `json:"base_req" yaml:"base_req"`
+	Proposal types.UpdateMarketProposal `json:"proposal" yaml:"proposal"`
+	Proposer sdk.AccAddress             `json:"proposer" yaml:"proposer"`
+	Deposit  sdk.Coins                  `json:"deposit" yaml:"deposit"`
+}
+
+func CreateMarketProposalRESTHandler(cliCtx context.CLIContext) govrest.ProposalRESTHandler {
+	return govrest.ProposalRESTHandler{
+		SubRoute: "create_market",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			var req CreateMarketProposalReq
+			if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+				return
+			}
+			writeProposal(w, cliCtx, req.BaseReq, req.Proposal, req.Deposit, req.Proposer)
+		},
+	}
+}
+
+func UpdateMarketProposalRESTHandler(cliCtx context.CLIContext) govrest.ProposalRESTHandler {
+	return govrest.ProposalRESTHandler{
+		SubRoute: "update_market",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			var req UpdateMarketProposalReq
+			if !rest.ReadRESTReq(w, r, cliCtx.Codec, &req) {
+				return
+			}
+			writeProposal(w, cliCtx, req.BaseReq, req.Proposal, req.Deposit, req.Proposer)
+		},
+	}
+}
+
+func writeProposal(w http.ResponseWriter, cliCtx context.CLIContext, baseReq rest.BaseReq, content gov.Content, deposit sdk.Coins, proposer sdk.AccAddress) {
+	baseReq = baseReq.Sanitize()
+	if !baseReq.ValidateBasic(w) {
+		return
+	}
+
+	msg := gov.NewMsgSubmitProposal(content, deposit, proposer)
+	if err := msg.ValidateBasic(); err != nil {
+		rest.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteGenerateStdTxResponse(w, cliCtx, baseReq, []sdk.Msg{msg})
+}
@@ -5,6 +5,7 @@ import (
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
+	"github.com/xar-network/xar-network/pkg/matcheng"
 	"github.com/xar-network/xar-network/types/store"
 	"github.com/xar-network/xar-network/x/market/types"
 )
@@ -31,6 +32,21 @@ func ValidateGenesis(data GenesisState) error {
 		if market.QuoteAssetDenom == "" {
 			return errors.New("Invalid Market: Must specify a non-zero quote asset denom.")
 		}
+		if market.MakerFeeBps > types.MaxFeeBps || market.TakerFeeBps > types.MaxFeeBps {
+			return errors.New("Invalid Market: Fees cannot exceed 10000 bps.")
+		}
+		if !market.SelfTradePrevention.IsValid() {
+			return errors.New("Invalid Market: Unknown self-trade prevention mode.")
+		}
+		if market.BaseAssetDecimals > matcheng.MaxAssetDecimals || market.QuoteAssetDecimals > matcheng.MaxAssetDecimals {
+			return errors.New("Invalid Market: Assets cannot have more than 18 decimals.")
+		}
+		if !market.Status.IsValid() {
+			return errors.New("Invalid Market: Unknown status.")
+		}
+		if !market.MatchingMode.IsValid() {
+			return errors.New("Invalid Market: Unknown matching mode.")
+		}
 	}
 
 	return nil
@@ -40,24 +56,32 @@ func DefaultGenesisState() GenesisState {
 	return GenesisState{
 		Markets: types.Markets{
 			{
-				ID:              store.NewEntityID(1),
-				BaseAssetDenom:  "uftm",
-				QuoteAssetDenom: "uzar",
+				ID:                 store.NewEntityID(1),
+				BaseAssetDenom:     "uftm",
+				QuoteAssetDenom:    "uzar",
+				BaseAssetDecimals:  matcheng.AssetDecimals,
+				QuoteAssetDecimals: matcheng.AssetDecimals,
 			},
 			{
-				ID:              store.NewEntityID(2),
-				BaseAssetDenom:  "ueur",
-				QuoteAssetDenom: "uzar",
+				ID:                 store.NewEntityID(2),
+				BaseAssetDenom:     "ueur",
+				QuoteAssetDenom:    "uzar",
+				BaseAssetDecimals:  matcheng.AssetDecimals,
+				QuoteAssetDecimals: matcheng.AssetDecimals,
 			},
 			{
-				ID:              store.NewEntityID(3),
-				BaseAssetDenom:  "uusd",
-				QuoteAssetDenom: "uzar",
+				ID:                 store.NewEntityID(3),
+				BaseAssetDenom:     "uusd",
+				QuoteAssetDenom:    "uzar",
+				BaseAssetDecimals:  matcheng.AssetDecimals,
+				QuoteAssetDecimals: matcheng.AssetDecimals,
 			},
 			{
-				ID:              store.NewEntityID(4),
-				BaseAssetDenom:  "ubtc",
-				QuoteAssetDenom: "uzar",
+				ID:                 store.NewEntityID(4),
+				BaseAssetDenom:     "ubtc",
+				QuoteAssetDenom:    "uzar",
+				BaseAssetDecimals:  matcheng.AssetDecimals,
+				QuoteAssetDecimals: matcheng.AssetDecimals,
 			},
 		},
 		Nominees: []string{},
@@ -13,6 +13,10 @@ func NewHandler(k Keeper) sdk.Handler {
 		switch msg := msg.(type) {
 		case types.MsgCreateMarket:
 			return handleCreateMarket(ctx, k, msg)
+		case types.MsgUpdateMarket:
+			return handleUpdateMarket(ctx, k, msg)
+		case types.MsgSetMarketStatus:
+			return handleSetMarketStatus(ctx, k, msg)
 		default:
 			return sdk.ErrUnknownRequest(fmt.Sprintf("unrecognized market message type: %T", msg)).Result()
 		}
@@ -20,9 +24,32 @@ func NewHandler(k Keeper) sdk.Handler {
 }
 
 func handleCreateMarket(ctx sdk.Context, keeper Keeper, msg types.MsgCreateMarket) sdk.Result {
-	_, err := keeper.CreateMarket(ctx, msg.Nominee.String(), msg.BaseAsset, msg.QuoteAsset)
+	_, err := keeper.CreateMarketWithDecimals(ctx, msg.Nominee.String(), msg.BaseAsset, msg.QuoteAsset, msg.BaseDecimals, msg.QuoteDecimals)
 	if err != nil {
 		return err.Result()
 	}
 	return sdk.Result{Events: ctx.EventManager().Events()}
 }
+
+func handleUpdateMarket(ctx sdk.Context, keeper Keeper, msg types.MsgUpdateMarket) sdk.Result {
+	_, err := keeper.UpdateMarket(ctx, msg.Nominee.String(), msg.MarketID, msg.TickSize, msg.LotSize, msg.MinNotional)
+	if err != nil {
+		return err.Result()
+	}
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+func handleSetMarketStatus(ctx sdk.Context, keeper Keeper, msg types.MsgSetMarketStatus) sdk.Result {
+	mkt, err := keeper.SetStatus(ctx, msg.Nominee.String(), msg.MarketID, msg.Status)
+	if err != nil {
+		return err.Result()
+	}
+	ctx.EventManager().EmitEvent(
+		sdk.NewEvent(
+			types.EventTypeMarketStatus,
+			sdk.NewAttribute(types.AttributeKeyMarketID, mkt.ID.String()),
+			sdk.NewAttribute(types.AttributeKeyStatus, mkt.Status.String()),
+		),
+	)
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
@@ -3,6 +3,7 @@ package market
 import (
 	"fmt"
 
+	"github.com/xar-network/xar-network/pkg/matcheng"
 	"github.com/xar-network/xar-network/types/errs"
 	"github.com/xar-network/xar-network/types/store"
 	"github.com/xar-network/xar-network/x/market/types"
@@ -66,18 +67,78 @@ func (k Keeper) Has(ctx sdk.Context, id store.EntityID) bool {
 }
 
 func (k Keeper) CreateMarket(ctx sdk.Context, nominee, baseAsset, quoteAsset string) (types.Market, sdk.Error) {
+	return k.CreateMarketWithDecimals(ctx, nominee, baseAsset, quoteAsset, matcheng.AssetDecimals, matcheng.AssetDecimals)
+}
+
+// CreateMarketWithDecimals creates a market between two assets with the given
+// precisions. Quote amounts in the market are normalized with them.
+func (k Keeper) CreateMarketWithDecimals(ctx sdk.Context, nominee, baseAsset, quoteAsset string, baseDecimals, quoteDecimals uint8) (types.Market, sdk.Error) {
 	if !k.IsNominee(ctx, nominee) {
 		return types.Market{}, sdk.ErrInternal(fmt.Sprintf("not a nominee: '%s'", nominee))
 	}
+	return k.createMarket(ctx, baseAsset, quoteAsset, baseDecimals, quoteDecimals)
+}
+
+func (k Keeper) createMarket(ctx sdk.Context, baseAsset, quoteAsset string, baseDecimals, quoteDecimals uint8) (types.Market, sdk.Error) {
+	if baseDecimals > matcheng.MaxAssetDecimals || quoteDecimals > matcheng.MaxAssetDecimals {
+		return types.Market{}, sdk.ErrUnknownRequest(fmt.Sprintf("assets cannot have more than %d decimals", matcheng.MaxAssetDecimals))
+	}
 	params := k.GetParams(ctx)
 	id := uint64(len(params.Markets))
-	market := types.NewMarket(store.NewEntityID(id).Inc(), baseAsset, quoteAsset)
+	market := types.NewMarket(store.NewEntityID(id).Inc(), baseAsset, quoteAsset, baseDecimals, quoteDecimals)
 	params.Markets = append(params.Markets, market)
 	k.SetParams(ctx, params)
 
 	return market, nil
 }
 
+// UpdateMarket replaces the tick size, lot size and minimum notional of a
+// market. Orders already on the book are not affected.
+func (k Keeper) UpdateMarket(ctx sdk.Context, nominee string, id store.EntityID, tickSize, lotSize, minNotional uint64) (types.Market, sdk.Error) {
+	if !k.IsNominee(ctx, nominee) {
+		return types.Market{}, sdk.ErrInternal(fmt.Sprintf("not a nominee: '%s'", nominee))
+	}
+	market, err := k.Get(ctx, id)
+	if err != nil {
+		return types.Market{}, err
+	}
+	market.TickSize = tickSize
+	market.LotSize = lotSize
+	market.MinNotional = minNotional
+	k.setMarket(ctx, market)
+
+	return market, nil
+}
+
+// SetStatus moves a market to a new lifecycle status. Delisted markets cannot
+// be brought back.
+func (k Keeper) SetStatus(ctx sdk.Context, nominee string, id store.EntityID, status types.Status) (types.Market, sdk.Error) {
+	if !k.IsNominee(ctx, nominee) {
+		return types.Market{}, sdk.ErrInternal(fmt.Sprintf("not a nominee: '%s'", nominee))
+	}
+	if !status.IsValid() {
+		return types.Market{}, sdk.ErrUnknownRequest("invalid market status")
+	}
+	market, err := k.Get(ctx, id)
+	if err != nil {
+		return types.Market{}, err
+	}
+	if market.Status == types.StatusDelisted {
+		return types.Market{}, types.ErrMarketClosed(k.codespace, market.Status)
+	}
+	market.Status = status
+	k.setMarket(ctx, market)
+
+	return market, nil
+}
+
+// setMarket overwrites a market that is known to exist.
+func (k Keeper) setMarket(ctx sdk.Context, market types.Market) {
+	params := k.GetParams(ctx)
+	params.Markets[market.ID.Dec().Uint64()] = market
+	k.SetParams(ctx, params)
+}
+
 func (k Keeper) Iterator(ctx sdk.Context, cb IteratorCB) {
 	params := k.GetParams(ctx)
 	for _, mkt := range params.Markets {
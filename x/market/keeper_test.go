@@ -66,6 +66,41 @@ func TestKeeperCoverage(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, mkt.BaseAssetDenom, msg.BaseAsset)
 
+	require.Equal(t, uint8(8), mkt.BaseAssetDecimals)
+
+	// Create market with its own precisions
+	mkt6, err := mk.CreateMarketWithDecimals(ctx, msg.Nominee.String(), "new3", "new4", 6, 18)
+	require.Nil(t, err)
+	require.Equal(t, uint8(6), mkt6.BaseAssetDecimals)
+	require.Equal(t, uint8(18), mkt6.QuoteAssetDecimals)
+	_, err = mk.CreateMarketWithDecimals(ctx, msg.Nominee.String(), "new3", "new4", 6, 19)
+	assert.Error(t, err)
+
+	// Update market as a nominee
+	mkt, err = mk.UpdateMarket(ctx, msg.Nominee.String(), mkt.ID, 100, 1000, 10000)
+	require.Nil(t, err)
+	mkt, err = mk.Get(ctx, mkt.ID)
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), mkt.TickSize)
+	require.Equal(t, uint64(1000), mkt.LotSize)
+	require.Equal(t, uint64(10000), mkt.MinNotional)
+
+	// Halt, resume and delist market as a nominee
+	mkt, err = mk.SetStatus(ctx, msg.Nominee.String(), mkt.ID, types.StatusHalted)
+	require.Nil(t, err)
+	require.Equal(t, types.StatusHalted, mkt.Status)
+	mkt, err = mk.SetStatus(ctx, msg.Nominee.String(), mkt.ID, types.StatusActive)
+	require.Nil(t, err)
+	require.Equal(t, types.StatusActive, mkt.Status)
+	mkt, err = mk.SetStatus(ctx, msg.Nominee.String(), mkt.ID, types.StatusDelisted)
+	require.Nil(t, err)
+	_, err = mk.SetStatus(ctx, msg.Nominee.String(), mkt.ID, types.StatusActive)
+	assert.Error(t, err)
+
+	// Update market as a non-nominee
+	_, err = mk.UpdateMarket(ctx, sdk.AccAddress([]byte("someInvalidName")).String(), mkt.ID, 1, 1, 1)
+	assert.Error(t, err)
+
 	// Create market as a nominee
 	addr = sdk.AccAddress([]byte("someInvalidName"))
 	msg = types.NewMsgCreateMarket(addr, "new1", "new2")
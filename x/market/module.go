@@ -27,7 +27,9 @@ type AppModuleBasic struct{}
 
 func (a AppModuleBasic) Name() string { return types.ModuleName }
 
-func (a AppModuleBasic) RegisterCodec(*codec.Codec) {}
+func (a AppModuleBasic) RegisterCodec(cdc *codec.Codec) {
+	types.RegisterCodec(cdc)
+}
 
 func (a AppModuleBasic) DefaultGenesis() json.RawMessage {
 	return types.ModuleCdc.MustMarshalJSON(DefaultGenesisState())
@@ -45,8 +47,8 @@ func (a AppModuleBasic) ValidateGenesis(b json.RawMessage) error {
 func (a AppModuleBasic) RegisterRESTRoutes(context.CLIContext, *mux.Router) {
 }
 
-func (a AppModuleBasic) GetTxCmd(*codec.Codec) *cobra.Command {
-	return cli.GetTxCmd()
+func (a AppModuleBasic) GetTxCmd(cdc *codec.Codec) *cobra.Command {
+	return cli.GetTxCmd(cdc)
 }
 
 func (a AppModuleBasic) GetQueryCmd(cdc *codec.Codec) *cobra.Command {
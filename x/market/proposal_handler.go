@@ -0,0 +1,42 @@
+package market
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+
+	"github.com/xar-network/xar-network/x/market/types"
+)
+
+// NewProposalHandler handles market proposals that have passed governance.
+// Unlike the nominee messages, they need no further authorization.
+func NewProposalHandler(k Keeper) govtypes.Handler {
+	return func(ctx sdk.Context, content govtypes.Content) sdk.Error {
+		switch c := content.(type) {
+		case types.CreateMarketProposal:
+			return handleCreateMarketProposal(ctx, k, c)
+		case types.UpdateMarketProposal:
+			return handleUpdateMarketProposal(ctx, k, c)
+		default:
+			return sdk.ErrUnknownRequest(fmt.Sprintf("unrecognized market proposal content type: %T", c))
+		}
+	}
+}
+
+func handleCreateMarketProposal(ctx sdk.Context, k Keeper, p types.CreateMarketProposal) sdk.Error {
+	_, err := k.createMarket(ctx, p.BaseAsset, p.QuoteAsset, p.BaseDecimals, p.QuoteDecimals)
+	return err
+}
+
+func handleUpdateMarketProposal(ctx sdk.Context, k Keeper, p types.UpdateMarketProposal) sdk.Error {
+	mkt, err := k.Get(ctx, p.MarketID)
+	if err != nil {
+		return err
+	}
+	if mkt.Status == types.StatusDelisted {
+		return types.ErrMarketClosed(k.codespace, mkt.Status)
+	}
+	k.setMarket(ctx, p.Apply(mkt))
+	return nil
+}
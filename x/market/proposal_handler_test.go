@@ -0,0 +1,110 @@
+package market_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+
+	cstore "github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/params"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/types/store"
+	"github.com/xar-network/xar-network/x/market"
+	"github.com/xar-network/xar-network/x/market/types"
+)
+
+func TestProposalHandler(t *testing.T) {
+	cdc := makeTestCodec()
+	keyParams := sdk.NewKVStoreKey(params.StoreKey)
+	tkeyParams := sdk.NewTransientStoreKey(params.TStoreKey)
+
+	db := dbm.NewMemDB()
+	ms := cstore.NewCommitMultiStore(db)
+	ms.MountStoreWithDB(keyParams, sdk.StoreTypeIAVL, db)
+	ms.MountStoreWithDB(tkeyParams, sdk.StoreTypeTransient, db)
+	require.Nil(t, ms.LoadLatestVersion())
+
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "xar-chain"}, true, log.NewNopLogger())
+	pk := params.NewKeeper(cdc, keyParams, tkeyParams, params.DefaultCodespace)
+	mk := market.NewKeeper(sdk.NewKVStoreKey(market.StoreKey), cdc, pk.Subspace(market.DefaultParamspace), market.DefaultCodespace)
+	mk.SetParams(ctx, types.NewParams(market.DefaultGenesisState().Markets, []string{}))
+	handler := market.NewProposalHandler(mk)
+
+	// Create market without a nominee
+	err := handler(ctx, types.NewCreateMarketProposal("List", "Lists new1/new2", "new1", "new2", 6, 8))
+	require.Nil(t, err)
+	mkt, err := mk.Get(ctx, store.NewEntityID(5))
+	require.Nil(t, err)
+	require.Equal(t, "new1", mkt.BaseAssetDenom)
+	require.Equal(t, uint8(6), mkt.BaseAssetDecimals)
+
+	// Update market
+	makerFee, takerFee := uint16(10), uint16(20)
+	stp := matcheng.STPCancelOldest
+	tick, lot, minNotional := uint64(100), uint64(1000), uint64(10000)
+	status := types.StatusPostOnly
+	update := types.UpdateMarketProposal{
+		Title:               "Configure",
+		Description:         "Configures new1/new2",
+		MarketID:            mkt.ID,
+		MakerFeeBps:         &makerFee,
+		TakerFeeBps:         &takerFee,
+		SelfTradePrevention: &stp,
+		TickSize:            &tick,
+		LotSize:             &lot,
+		MinNotional:         &minNotional,
+		Status:              &status,
+	}
+	require.Nil(t, update.ValidateBasic())
+	require.Nil(t, handler(ctx, update))
+	mkt, err = mk.Get(ctx, mkt.ID)
+	require.Nil(t, err)
+	require.Equal(t, uint16(20), mkt.TakerFeeBps)
+	require.Equal(t, matcheng.STPCancelOldest, mkt.SelfTradePrevention)
+	require.Equal(t, uint64(1000), mkt.LotSize)
+	require.Equal(t, types.StatusPostOnly, mkt.Status)
+	require.Equal(t, "new1", mkt.BaseAssetDenom)
+
+	// Settings left out of a proposal are kept, including after encoding
+	takerFee = 30
+	feesOnly := types.UpdateMarketProposal{
+		Title:       "Fees",
+		Description: "Raises the taker fee of new1/new2",
+		MarketID:    mkt.ID,
+		TakerFeeBps: &takerFee,
+	}
+	var decoded types.UpdateMarketProposal
+	require.Nil(t, cdc.UnmarshalBinaryLengthPrefixed(cdc.MustMarshalBinaryLengthPrefixed(feesOnly), &decoded))
+	require.Nil(t, decoded.ValidateBasic())
+	require.Nil(t, handler(ctx, decoded))
+	mkt, err = mk.Get(ctx, mkt.ID)
+	require.Nil(t, err)
+	require.Equal(t, uint16(30), mkt.TakerFeeBps)
+	require.Equal(t, uint16(10), mkt.MakerFeeBps)
+	require.Equal(t, uint64(100), mkt.TickSize)
+	require.Equal(t, matcheng.STPCancelOldest, mkt.SelfTradePrevention)
+	require.Equal(t, types.StatusPostOnly, mkt.Status)
+
+	// A proposal must change something
+	assert.Error(t, types.UpdateMarketProposal{Title: "None", Description: "Changes nothing", MarketID: mkt.ID}.ValidateBasic())
+
+	// Delisted markets stay delisted
+	status = types.StatusDelisted
+	require.Nil(t, handler(ctx, update))
+	status = types.StatusActive
+	assert.Error(t, handler(ctx, update))
+
+	// Update a market that does not exist
+	update.MarketID = store.NewEntityID(99)
+	assert.Error(t, handler(ctx, update))
+
+	// Invalid fees are rejected before voting
+	takerFee = types.MaxFeeBps + 1
+	assert.Error(t, update.ValidateBasic())
+}
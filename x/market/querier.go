@@ -42,6 +42,13 @@ func queryList(ctx sdk.Context, keeper Keeper) ([]byte, sdk.Error) {
 			BaseAssetDenom:  mkt.BaseAssetDenom,
 			QuoteAssetDenom: mkt.QuoteAssetDenom,
 			Name:            name,
+			TickSize:        mkt.TickSize,
+			LotSize:         mkt.LotSize,
+			MinNotional:     mkt.MinNotional,
+			BaseDecimals:    mkt.BaseAssetDecimals,
+			QuoteDecimals:   mkt.QuoteAssetDecimals,
+			Status:          mkt.Status,
+			MatchingMode:    mkt.MatchingMode,
 		})
 		return true
 	})
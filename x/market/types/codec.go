@@ -6,6 +6,10 @@ var ModuleCdc *codec.Codec
 
 func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MsgCreateMarket{}, "market/MsgCreateMarket", nil)
+	cdc.RegisterConcrete(MsgUpdateMarket{}, "market/MsgUpdateMarket", nil)
+	cdc.RegisterConcrete(MsgSetMarketStatus{}, "market/MsgSetMarketStatus", nil)
+	cdc.RegisterConcrete(CreateMarketProposal{}, "market/CreateMarketProposal", nil)
+	cdc.RegisterConcrete(UpdateMarketProposal{}, "market/UpdateMarketProposal", nil)
 }
 
 func init() {
@@ -12,9 +12,17 @@ const (
 
 	// CodeEmptyInput error code for empty input errors
 	CodeNoPOA sdk.CodeType = 1
+
+	// CodeMarketClosed error code for orders on markets that do not accept them
+	CodeMarketClosed sdk.CodeType = 2
 )
 
 // ErrEmptyInput Error constructor
 func ErrNoPOA(codespace sdk.CodespaceType) sdk.Error {
 	return sdk.NewError(codespace, CodeNoPOA, fmt.Sprintf("Invalid POA address."))
 }
+
+// ErrMarketClosed Error constructor
+func ErrMarketClosed(codespace sdk.CodespaceType, status Status) sdk.Error {
+	return sdk.NewError(codespace, CodeMarketClosed, fmt.Sprintf("market is %s", status))
+}
@@ -0,0 +1,8 @@
+package types
+
+const (
+	EventTypeMarketStatus = "market_status"
+
+	AttributeKeyMarketID = "market_id"
+	AttributeKeyStatus   = "status"
+)
@@ -1,26 +1,49 @@
 package types
 
-import sdk "github.com/cosmos/cosmos-sdk/types"
+import (
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
 
 var (
 	_ sdk.Msg = MsgCreateMarket{}
+	_ sdk.Msg = MsgUpdateMarket{}
+	_ sdk.Msg = MsgSetMarketStatus{}
 )
 
+// MsgCreateMarket creates a market between two assets. BaseDecimals and
+// QuoteDecimals are the precisions of the assets.
 type MsgCreateMarket struct {
-	Nominee    sdk.AccAddress `json:"nominee" yaml:"nominee"`
-	BaseAsset  string         `json:"base_asset" yaml:"base_asset"`
-	QuoteAsset string         `json:"quote_asset" yaml:"quote_asset"`
+	Nominee       sdk.AccAddress `json:"nominee" yaml:"nominee"`
+	BaseAsset     string         `json:"base_asset" yaml:"base_asset"`
+	QuoteAsset    string         `json:"quote_asset" yaml:"quote_asset"`
+	BaseDecimals  uint8          `json:"base_decimals" yaml:"base_decimals"`
+	QuoteDecimals uint8          `json:"quote_decimals" yaml:"quote_decimals"`
 }
 
 func NewMsgCreateMarket(
 	nominee sdk.AccAddress,
 	baseAsset string,
 	quoteAsset string,
+) MsgCreateMarket {
+	return NewMsgCreateMarketWithDecimals(nominee, baseAsset, quoteAsset, matcheng.AssetDecimals, matcheng.AssetDecimals)
+}
+
+func NewMsgCreateMarketWithDecimals(
+	nominee sdk.AccAddress,
+	baseAsset string,
+	quoteAsset string,
+	baseDecimals uint8,
+	quoteDecimals uint8,
 ) MsgCreateMarket {
 	return MsgCreateMarket{
-		Nominee:    nominee,
-		BaseAsset:  baseAsset,
-		QuoteAsset: quoteAsset,
+		Nominee:       nominee,
+		BaseAsset:     baseAsset,
+		QuoteAsset:    quoteAsset,
+		BaseDecimals:  baseDecimals,
+		QuoteDecimals: quoteDecimals,
 	}
 }
 func (msg MsgCreateMarket) Route() string { return ModuleName }
@@ -41,6 +64,10 @@ func (msg MsgCreateMarket) ValidateBasic() sdk.Error {
 		return sdk.ErrInvalidAddress("missing nominee address")
 	}
 
+	if msg.BaseDecimals > matcheng.MaxAssetDecimals || msg.QuoteDecimals > matcheng.MaxAssetDecimals {
+		return sdk.ErrUnknownRequest("too many decimals")
+	}
+
 	return nil
 }
 
@@ -51,3 +78,96 @@ func (msg MsgCreateMarket) GetSigners() []sdk.AccAddress {
 func (msg MsgCreateMarket) GetSignBytes() []byte {
 	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
 }
+
+// MsgUpdateMarket sets the trading rules of an existing market. A zero value
+// lifts the corresponding rule.
+type MsgUpdateMarket struct {
+	Nominee     sdk.AccAddress `json:"nominee" yaml:"nominee"`
+	MarketID    store.EntityID `json:"market_id" yaml:"market_id"`
+	TickSize    uint64         `json:"tick_size" yaml:"tick_size"`
+	LotSize     uint64         `json:"lot_size" yaml:"lot_size"`
+	MinNotional uint64         `json:"min_notional" yaml:"min_notional"`
+}
+
+func NewMsgUpdateMarket(
+	nominee sdk.AccAddress,
+	marketID store.EntityID,
+	tickSize uint64,
+	lotSize uint64,
+	minNotional uint64,
+) MsgUpdateMarket {
+	return MsgUpdateMarket{
+		Nominee:     nominee,
+		MarketID:    marketID,
+		TickSize:    tickSize,
+		LotSize:     lotSize,
+		MinNotional: minNotional,
+	}
+}
+
+func (msg MsgUpdateMarket) Route() string { return ModuleName }
+
+func (msg MsgUpdateMarket) Type() string { return "updateMarket" }
+
+func (msg MsgUpdateMarket) ValidateBasic() sdk.Error {
+	if !msg.MarketID.IsDefined() {
+		return sdk.ErrUnknownRequest("invalid market ID")
+	}
+
+	if msg.Nominee.Empty() {
+		return sdk.ErrInvalidAddress("missing nominee address")
+	}
+
+	return nil
+}
+
+func (msg MsgUpdateMarket) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Nominee}
+}
+
+func (msg MsgUpdateMarket) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
+
+// MsgSetMarketStatus halts, resumes, makes post-only or delists a market.
+type MsgSetMarketStatus struct {
+	Nominee  sdk.AccAddress `json:"nominee" yaml:"nominee"`
+	MarketID store.EntityID `json:"market_id" yaml:"market_id"`
+	Status   Status         `json:"status" yaml:"status"`
+}
+
+func NewMsgSetMarketStatus(nominee sdk.AccAddress, marketID store.EntityID, status Status) MsgSetMarketStatus {
+	return MsgSetMarketStatus{
+		Nominee:  nominee,
+		MarketID: marketID,
+		Status:   status,
+	}
+}
+
+func (msg MsgSetMarketStatus) Route() string { return ModuleName }
+
+func (msg MsgSetMarketStatus) Type() string { return "setMarketStatus" }
+
+func (msg MsgSetMarketStatus) ValidateBasic() sdk.Error {
+	if !msg.MarketID.IsDefined() {
+		return sdk.ErrUnknownRequest("invalid market ID")
+	}
+
+	if !msg.Status.IsValid() {
+		return sdk.ErrUnknownRequest("invalid market status")
+	}
+
+	if msg.Nominee.Empty() {
+		return sdk.ErrInvalidAddress("missing nominee address")
+	}
+
+	return nil
+}
+
+func (msg MsgSetMarketStatus) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Nominee}
+}
+
+func (msg MsgSetMarketStatus) GetSignBytes() []byte {
+	return sdk.MustSortJSON(ModuleCdc.MustMarshalJSON(msg))
+}
@@ -6,6 +6,8 @@ import (
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/x/params/subspace"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
 )
 
 // Parameter keys
@@ -73,6 +75,21 @@ func (p Params) Validate() error {
 		if !market.ID.IsDefined() {
 			return fmt.Errorf("invalid id: %s. missing id", market.String())
 		}
+		if market.MakerFeeBps > MaxFeeBps || market.TakerFeeBps > MaxFeeBps {
+			return fmt.Errorf("invalid fee: %s. fees cannot exceed %d bps", market.String(), MaxFeeBps)
+		}
+		if !market.SelfTradePrevention.IsValid() {
+			return fmt.Errorf("invalid self-trade prevention mode: %s", market.String())
+		}
+		if market.BaseAssetDecimals > matcheng.MaxAssetDecimals || market.QuoteAssetDecimals > matcheng.MaxAssetDecimals {
+			return fmt.Errorf("invalid decimals: %s. assets cannot have more than %d decimals", market.String(), matcheng.MaxAssetDecimals)
+		}
+		if !market.Status.IsValid() {
+			return fmt.Errorf("invalid status: %s", market.String())
+		}
+		if !market.MatchingMode.IsValid() {
+			return fmt.Errorf("invalid matching mode: %s", market.String())
+		}
 	}
 	return nil
 }
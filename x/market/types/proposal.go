@@ -0,0 +1,198 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+)
+
+const (
+	ProposalTypeCreateMarket = "CreateMarket"
+	ProposalTypeUpdateMarket = "UpdateMarket"
+)
+
+var (
+	_ govtypes.Content = CreateMarketProposal{}
+	_ govtypes.Content = UpdateMarketProposal{}
+)
+
+func init() {
+	govtypes.RegisterProposalType(ProposalTypeCreateMarket)
+	govtypes.RegisterProposalTypeCodec(CreateMarketProposal{}, "market/CreateMarketProposal")
+	govtypes.RegisterProposalType(ProposalTypeUpdateMarket)
+	govtypes.RegisterProposalTypeCodec(UpdateMarketProposal{}, "market/UpdateMarketProposal")
+}
+
+// CreateMarketProposal lists a new market once it passes, without needing a
+// nominee.
+type CreateMarketProposal struct {
+	Title         string `json:"title" yaml:"title"`
+	Description   string `json:"description" yaml:"description"`
+	BaseAsset     string `json:"base_asset" yaml:"base_asset"`
+	QuoteAsset    string `json:"quote_asset" yaml:"quote_asset"`
+	BaseDecimals  uint8  `json:"base_decimals" yaml:"base_decimals"`
+	QuoteDecimals uint8  `json:"quote_decimals" yaml:"quote_decimals"`
+}
+
+func NewCreateMarketProposal(title, description, baseAsset, quoteAsset string, baseDecimals, quoteDecimals uint8) CreateMarketProposal {
+	return CreateMarketProposal{
+		Title:         title,
+		Description:   description,
+		BaseAsset:     baseAsset,
+		QuoteAsset:    quoteAsset,
+		BaseDecimals:  baseDecimals,
+		QuoteDecimals: quoteDecimals,
+	}
+}
+
+func (p CreateMarketProposal) GetTitle() string { return p.Title }
+
+func (p CreateMarketProposal) GetDescription() string { return p.Description }
+
+func (p CreateMarketProposal) ProposalRoute() string { return RouterKey }
+
+func (p CreateMarketProposal) ProposalType() string { return ProposalTypeCreateMarket }
+
+func (p CreateMarketProposal) ValidateBasic() sdk.Error {
+	if err := govtypes.ValidateAbstract(DefaultCodespace, p); err != nil {
+		return err
+	}
+	if p.BaseAsset == "" || p.QuoteAsset == "" {
+		return sdk.ErrUnknownRequest("missing base or quote asset")
+	}
+	if p.BaseDecimals > matcheng.MaxAssetDecimals || p.QuoteDecimals > matcheng.MaxAssetDecimals {
+		return sdk.ErrUnknownRequest("too many decimals")
+	}
+	return nil
+}
+
+func (p CreateMarketProposal) String() string {
+	return fmt.Sprintf(`Create Market Proposal:
+  Title:       %s
+  Description: %s
+  Base Asset:  %s (%d decimals)
+  Quote Asset: %s (%d decimals)
+`, p.Title, p.Description, p.BaseAsset, p.BaseDecimals, p.QuoteAsset, p.QuoteDecimals)
+}
+
+// UpdateMarketProposal changes the fees, self-trade prevention mode, trading
+// rules, status or matching mode of an existing market once it passes. Only
+// the settings it sets are changed, so it does not undo changes made since it
+// was submitted to settings it leaves out. A market's assets cannot be
+// changed.
+type UpdateMarketProposal struct {
+	Title               string                        `json:"title" yaml:"title"`
+	Description         string                        `json:"description" yaml:"description"`
+	MarketID            store.EntityID                `json:"market_id" yaml:"market_id"`
+	MakerFeeBps         *uint16                       `json:"maker_fee_bps,omitempty" yaml:"maker_fee_bps,omitempty"`
+	TakerFeeBps         *uint16                       `json:"taker_fee_bps,omitempty" yaml:"taker_fee_bps,omitempty"`
+	SelfTradePrevention *matcheng.SelfTradePrevention `json:"self_trade_prevention,omitempty" yaml:"self_trade_prevention,omitempty"`
+	TickSize            *uint64                       `json:"tick_size,omitempty" yaml:"tick_size,omitempty"`
+	LotSize             *uint64                       `json:"lot_size,omitempty" yaml:"lot_size,omitempty"`
+	MinNotional         *uint64                       `json:"min_notional,omitempty" yaml:"min_notional,omitempty"`
+	Status              *Status                       `json:"status,omitempty" yaml:"status,omitempty"`
+	MatchingMode        *matcheng.MatchingMode        `json:"matching_mode,omitempty" yaml:"matching_mode,omitempty"`
+}
+
+func (p UpdateMarketProposal) GetTitle() string { return p.Title }
+
+func (p UpdateMarketProposal) GetDescription() string { return p.Description }
+
+func (p UpdateMarketProposal) ProposalRoute() string { return RouterKey }
+
+func (p UpdateMarketProposal) ProposalType() string { return ProposalTypeUpdateMarket }
+
+func (p UpdateMarketProposal) ValidateBasic() sdk.Error {
+	if err := govtypes.ValidateAbstract(DefaultCodespace, p); err != nil {
+		return err
+	}
+	if !p.MarketID.IsDefined() {
+		return sdk.ErrUnknownRequest("invalid market ID")
+	}
+	if p.MakerFeeBps == nil && p.TakerFeeBps == nil && p.SelfTradePrevention == nil &&
+		p.TickSize == nil && p.LotSize == nil && p.MinNotional == nil &&
+		p.Status == nil && p.MatchingMode == nil {
+		return sdk.ErrUnknownRequest("proposal does not change any setting")
+	}
+	if (p.MakerFeeBps != nil && *p.MakerFeeBps > MaxFeeBps) || (p.TakerFeeBps != nil && *p.TakerFeeBps > MaxFeeBps) {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("fees cannot exceed %d bps", MaxFeeBps))
+	}
+	if p.SelfTradePrevention != nil && !p.SelfTradePrevention.IsValid() {
+		return sdk.ErrUnknownRequest("invalid self-trade prevention mode")
+	}
+	if p.Status != nil && !p.Status.IsValid() {
+		return sdk.ErrUnknownRequest("invalid market status")
+	}
+	if p.MatchingMode != nil && !p.MatchingMode.IsValid() {
+		return sdk.ErrUnknownRequest("invalid matching mode")
+	}
+	return nil
+}
+
+// Apply returns mkt with the settings the proposal sets.
+func (p UpdateMarketProposal) Apply(mkt Market) Market {
+	if p.MakerFeeBps != nil {
+		mkt.MakerFeeBps = *p.MakerFeeBps
+	}
+	if p.TakerFeeBps != nil {
+		mkt.TakerFeeBps = *p.TakerFeeBps
+	}
+	if p.SelfTradePrevention != nil {
+		mkt.SelfTradePrevention = *p.SelfTradePrevention
+	}
+	if p.TickSize != nil {
+		mkt.TickSize = *p.TickSize
+	}
+	if p.LotSize != nil {
+		mkt.LotSize = *p.LotSize
+	}
+	if p.MinNotional != nil {
+		mkt.MinNotional = *p.MinNotional
+	}
+	if p.Status != nil {
+		mkt.Status = *p.Status
+	}
+	if p.MatchingMode != nil {
+		mkt.MatchingMode = *p.MatchingMode
+	}
+	return mkt
+}
+
+func (p UpdateMarketProposal) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `Update Market Proposal:
+  Title:                 %s
+  Description:           %s
+  Market ID:             %s
+`, p.Title, p.Description, p.MarketID)
+	if p.MakerFeeBps != nil {
+		fmt.Fprintf(&b, "  Maker Fee (bps):       %d\n", *p.MakerFeeBps)
+	}
+	if p.TakerFeeBps != nil {
+		fmt.Fprintf(&b, "  Taker Fee (bps):       %d\n", *p.TakerFeeBps)
+	}
+	if p.SelfTradePrevention != nil {
+		fmt.Fprintf(&b, "  Self-Trade Prevention: %s\n", *p.SelfTradePrevention)
+	}
+	if p.TickSize != nil {
+		fmt.Fprintf(&b, "  Tick Size:             %d\n", *p.TickSize)
+	}
+	if p.LotSize != nil {
+		fmt.Fprintf(&b, "  Lot Size:              %d\n", *p.LotSize)
+	}
+	if p.MinNotional != nil {
+		fmt.Fprintf(&b, "  Min Notional:          %d\n", *p.MinNotional)
+	}
+	if p.Status != nil {
+		fmt.Fprintf(&b, "  Status:                %s\n", *p.Status)
+	}
+	if p.MatchingMode != nil {
+		fmt.Fprintf(&b, "  Matching Mode:         %s\n", *p.MatchingMode)
+	}
+	return b.String()
+}
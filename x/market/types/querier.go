@@ -2,8 +2,11 @@ package types
 
 import (
 	"bytes"
+	"strconv"
 
 	"github.com/olekukonko/tablewriter"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
 )
 
 type NamedMarket struct {
@@ -11,6 +14,13 @@ type NamedMarket struct {
 	BaseAssetDenom  string
 	QuoteAssetDenom string
 	Name            string
+	TickSize        uint64
+	LotSize         uint64
+	MinNotional     uint64
+	BaseDecimals    uint8
+	QuoteDecimals   uint8
+	Status          Status
+	MatchingMode    matcheng.MatchingMode
 }
 
 type ListQueryResult struct {
@@ -25,6 +35,11 @@ func (l ListQueryResult) String() string {
 		"Name",
 		"Base Asset ID",
 		"Quote Asset ID",
+		"Tick Size",
+		"Lot Size",
+		"Min Notional",
+		"Status",
+		"Matching Mode",
 	})
 
 	for _, m := range l.Markets {
@@ -33,6 +48,11 @@ func (l ListQueryResult) String() string {
 			m.Name,
 			m.BaseAssetDenom,
 			m.QuoteAssetDenom,
+			strconv.FormatUint(m.TickSize, 10),
+			strconv.FormatUint(m.LotSize, 10),
+			strconv.FormatUint(m.MinNotional, 10),
+			m.Status.String(),
+			m.MatchingMode.String(),
 		})
 	}
 
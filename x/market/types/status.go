@@ -0,0 +1,79 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	StatusActive Status = iota
+	StatusHalted
+	StatusPostOnly
+	StatusDelisted
+)
+
+// Status is the lifecycle state of a market. Active markets accept orders and
+// match them. Post-only markets accept limit orders but do not match, so a
+// book can be rebuilt before trading resumes. Halted markets accept nothing
+// but cancellations. Delisting is final: every order in the market is
+// cancelled and refunded at the end of the block.
+type Status uint8
+
+var statusNames = map[Status]string{
+	StatusActive:   "ACTIVE",
+	StatusHalted:   "HALTED",
+	StatusPostOnly: "POST_ONLY",
+	StatusDelisted: "DELISTED",
+}
+
+func NewStatusFromString(str string) (Status, error) {
+	for s, name := range statusNames {
+		if name == str {
+			return s, nil
+		}
+	}
+	return StatusActive, errors.New("invalid market status")
+}
+
+func (s Status) IsValid() bool {
+	_, ok := statusNames[s]
+	return ok
+}
+
+// AcceptsOrders reports whether new orders may be posted to the market.
+func (s Status) AcceptsOrders() bool {
+	return s == StatusActive || s == StatusPostOnly
+}
+
+// Matches reports whether the market's orders are matched each block.
+func (s Status) Matches() bool {
+	return s == StatusActive
+}
+
+func (s Status) String() string {
+	name, ok := statusNames[s]
+	if !ok {
+		return "UNKNOWN"
+	}
+	return name
+}
+
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+
+	out, err := NewStatusFromString(str)
+	if err != nil {
+		return err
+	}
+
+	*s = out
+	return nil
+}
+
+func (s Status) MarshalJSON() ([]byte, error) {
+	return []byte("\"" + s.String() + "\""), nil
+}
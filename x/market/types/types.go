@@ -3,26 +3,61 @@ package types
 import (
 	"fmt"
 
+	"github.com/xar-network/xar-network/pkg/matcheng"
 	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
 type Markets []Market
 
+// MaxFeeBps is the largest fee a market may charge, i.e. 100%.
+const MaxFeeBps = 10000
+
+// Market is a trading pair. MakerFeeBps and TakerFeeBps are charged, in basis
+// points, on the asset an order receives when it is filled: the base asset for
+// bids and the quote asset for asks. In batch auctions, orders resting from an
+// earlier block are makers and orders posted in the block they are filled in
+// are takers; in continuous markets the resting side of every trade is the
+// maker.
+// SelfTradePrevention decides what happens to crossing orders that share an
+// owner; it is off unless set. TickSize and LotSize are the increments that
+// prices and quantities must be multiples of, and MinNotional is the smallest
+// quote amount an order may be worth. All three are in base units and are not
+// enforced when zero. BaseAssetDecimals and QuoteAssetDecimals are the
+// precisions of the two assets, which quote amounts are normalized with.
+// Status controls whether the market accepts and matches orders, and
+// MatchingMode whether they are matched in a batch auction every block or
+// continuously as they arrive.
 type Market struct {
-	ID              store.EntityID `json:"id" yaml:"id"`
-	BaseAssetDenom  string         `json:"base_asset_denom" yaml:"base_asset_denom"`
-	QuoteAssetDenom string         `json:"quote_asset_denom" yaml:"quote_asset_denom"`
+	ID                  store.EntityID               `json:"id" yaml:"id"`
+	BaseAssetDenom      string                       `json:"base_asset_denom" yaml:"base_asset_denom"`
+	QuoteAssetDenom     string                       `json:"quote_asset_denom" yaml:"quote_asset_denom"`
+	MakerFeeBps         uint16                       `json:"maker_fee_bps" yaml:"maker_fee_bps"`
+	TakerFeeBps         uint16                       `json:"taker_fee_bps" yaml:"taker_fee_bps"`
+	SelfTradePrevention matcheng.SelfTradePrevention `json:"self_trade_prevention" yaml:"self_trade_prevention"`
+	TickSize            uint64                       `json:"tick_size" yaml:"tick_size"`
+	LotSize             uint64                       `json:"lot_size" yaml:"lot_size"`
+	MinNotional         uint64                       `json:"min_notional" yaml:"min_notional"`
+	BaseAssetDecimals   uint8                        `json:"base_asset_decimals" yaml:"base_asset_decimals"`
+	QuoteAssetDecimals  uint8                        `json:"quote_asset_decimals" yaml:"quote_asset_decimals"`
+	Status              Status                       `json:"status" yaml:"status"`
+	MatchingMode        matcheng.MatchingMode        `json:"matching_mode" yaml:"matching_mode"`
 }
 
 func NewMarket(
 	id store.EntityID,
 	baseAsset string,
 	quoteAsset string,
+	baseDecimals uint8,
+	quoteDecimals uint8,
 ) Market {
 	return Market{
-		ID:              id,
-		BaseAssetDenom:  baseAsset,
-		QuoteAssetDenom: quoteAsset,
+		ID:                 id,
+		BaseAssetDenom:     baseAsset,
+		QuoteAssetDenom:    quoteAsset,
+		BaseAssetDecimals:  baseDecimals,
+		QuoteAssetDecimals: quoteDecimals,
 	}
 }
 
@@ -30,7 +65,69 @@ func NewMarket(
 func (m Market) String() string {
 	return fmt.Sprintf(`Market:
 	ID: %s
-	Base Asset: %s
-	Quote Asset: %s`,
-		m.ID.String(), m.BaseAssetDenom, m.QuoteAssetDenom)
+	Base Asset: %s (%d decimals)
+	Quote Asset: %s (%d decimals)
+	Maker Fee (bps): %d
+	Taker Fee (bps): %d
+	Self-Trade Prevention: %s
+	Tick Size: %d
+	Lot Size: %d
+	Min Notional: %d
+	Status: %s
+	Matching Mode: %s`,
+		m.ID.String(), m.BaseAssetDenom, m.BaseAssetDecimals, m.QuoteAssetDenom, m.QuoteAssetDecimals, m.MakerFeeBps, m.TakerFeeBps, m.SelfTradePrevention,
+		m.TickSize, m.LotSize, m.MinNotional, m.Status, m.MatchingMode)
+}
+
+// FeeBps returns the fee rate charged to a maker or taker fill.
+func (m Market) FeeBps(maker bool) uint16 {
+	if maker {
+		return m.MakerFeeBps
+	}
+	return m.TakerFeeBps
+}
+
+// NormalizeQuoteQuantity returns the quote amount that quantity is worth at
+// price, taking the precision of both assets into account.
+func (m Market) NormalizeQuoteQuantity(price sdk.Uint, quantity sdk.Uint) (sdk.Uint, error) {
+	return matcheng.NormalizeQuoteQuantityWithDecimals(price, quantity, m.BaseAssetDecimals, m.QuoteAssetDecimals)
+}
+
+// PriceFromQuoteQuantity returns the highest price at which quantity costs no
+// more than quoteAmount.
+func (m Market) PriceFromQuoteQuantity(quoteAmount sdk.Uint, quantity sdk.Uint) (sdk.Uint, error) {
+	return matcheng.PriceFromQuoteQuantityWithDecimals(quoteAmount, quantity, m.BaseAssetDecimals, m.QuoteAssetDecimals)
+}
+
+// ValidatePrice checks that a limit price is a multiple of the tick size.
+func (m Market) ValidatePrice(price sdk.Uint) sdk.Error {
+	if !isMultiple(price, m.TickSize) {
+		return sdk.ErrInvalidCoins(fmt.Sprintf("price must be a multiple of the tick size %d", m.TickSize))
+	}
+	return nil
+}
+
+// ValidateQuantity checks that a quantity is a multiple of the lot size.
+func (m Market) ValidateQuantity(quantity sdk.Uint) sdk.Error {
+	if !isMultiple(quantity, m.LotSize) {
+		return sdk.ErrInvalidCoins(fmt.Sprintf("quantity must be a multiple of the lot size %d", m.LotSize))
+	}
+	return nil
+}
+
+// ValidateNotional checks that the quote amount of an order is at least the
+// minimum notional.
+func (m Market) ValidateNotional(notional sdk.Uint) sdk.Error {
+	if notional.LT(sdk.NewUint(m.MinNotional)) {
+		return sdk.ErrInvalidCoins(fmt.Sprintf("order is worth less than the minimum notional %d", m.MinNotional))
+	}
+	return nil
+}
+
+func isMultiple(amount sdk.Uint, step uint64) bool {
+	if step == 0 {
+		return true
+	}
+	s := sdk.NewUint(step)
+	return amount.Quo(s).Mul(s).Equal(amount)
 }
@@ -14,6 +14,7 @@ func GetQueryCmd(sk string, cdc *codec.Codec) *cobra.Command {
 	}
 	queryCmd.AddCommand(client.GetCommands(
 		GetCmdListOrders(sk, cdc),
+		GetCmdListConditionalOrders(sk, cdc),
 	)...)
 	return queryCmd
 }
@@ -26,6 +27,12 @@ func GetTxCmd(cdc *codec.Codec) *cobra.Command {
 	txCmd.AddCommand(client.PostCommands(
 		GetCmdPost(cdc),
 		GetCmdCancel(cdc),
+		GetCmdCancelAll(cdc),
+		GetCmdBatchPost(cdc),
+		GetCmdBatchCancel(cdc),
+		GetCmdAmend(cdc),
+		GetCmdPostConditional(cdc),
+		GetCmdCancelConditional(cdc),
 	)...)
 	return txCmd
 }
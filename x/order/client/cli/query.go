@@ -29,3 +29,22 @@ func GetCmdListOrders(queryRoute string, cdc *codec.Codec) *cobra.Command {
 	}
 	return out
 }
+
+func GetCmdListConditionalOrders(queryRoute string, cdc *codec.Codec) *cobra.Command {
+	out := &cobra.Command{
+		Use:   "list-conditional",
+		Short: "lists stop-loss and take-profit orders that have not been triggered",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.NewCLIContext().WithCodec(cdc)
+			res, _, err := ctx.QueryWithData(fmt.Sprintf("custom/%s/conditional", queryRoute), nil)
+			if err != nil {
+				return err
+			}
+
+			var out types.ListConditionalQueryResult
+			cdc.MustUnmarshalJSON(res, &out)
+			return ctx.PrintOutput(out)
+		},
+	}
+	return out
+}
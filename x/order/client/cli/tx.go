@@ -3,6 +3,7 @@ package cli
 import (
 	"bufio"
 	"errors"
+	"io/ioutil"
 	"math"
 	"strconv"
 	"strings"
@@ -22,11 +23,24 @@ import (
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 )
 
+const (
+	flagType     = "type"
+	flagMaxSpend = "max-spend"
+	flagTrigger  = "trigger"
+	flagMarketID = "market-id"
+)
+
 func GetCmdPost(cdc *codec.Codec) *cobra.Command {
-	return &cobra.Command{
+	var orderTypeArg string
+	var maxSpendArg string
+	cmd := &cobra.Command{
 		Use:   "post [market-id] [direction] [price] [quantity] [time-in-force-blocks]",
 		Short: "posts an order",
-		Args:  cobra.ExactArgs(5),
+		Long: `Posts an order of the given --type (limit, market, ioc or fok). Market orders
+take a price of 0, and market bids must set --max-spend to the most quote
+asset they may spend. Only limit orders stay on the book after the block they
+are posted in.`,
+		Args: cobra.ExactArgs(5),
 		RunE: func(cmd *cobra.Command, args []string) error {
 
 			inBuf := bufio.NewReader(cmd.InOrStdin())
@@ -38,14 +52,9 @@ func GetCmdPost(cdc *codec.Codec) *cobra.Command {
 			bldr := authtypes.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
 
 			marketID := store.NewEntityIDFromString(args[0])
-			var direction matcheng.Direction
-			dirArg := strings.ToLower(args[1])
-			if dirArg == "bid" {
-				direction = matcheng.Bid
-			} else if dirArg == "ask" {
-				direction = matcheng.Ask
-			} else {
-				return errors.New("invalid direction")
+			direction, err := parseDirection(args[1])
+			if err != nil {
+				return err
 			}
 
 			price, err := sdk.ParseUint(args[2])
@@ -63,11 +72,22 @@ func GetCmdPost(cdc *codec.Codec) *cobra.Command {
 			if tif > math.MaxUint16 {
 				return errors.New("time in force too large")
 			}
+			orderType, err := matcheng.NewOrderTypeFromString(strings.ToUpper(orderTypeArg))
+			if err != nil {
+				return err
+			}
+			maxSpend, err := sdk.ParseUint(maxSpendArg)
+			if err != nil {
+				return err
+			}
 
-			msg := types.NewMsgPost(cliCtx.GetFromAddress(), marketID, direction, price, quantity, uint16(tif))
+			msg := types.NewMsgPostWithType(cliCtx.GetFromAddress(), marketID, orderType, direction, price, quantity, maxSpend, uint16(tif))
 			return cliutil.ValidateAndBroadcast(cliCtx, bldr, msg)
 		},
 	}
+	cmd.Flags().StringVar(&orderTypeArg, flagType, "limit", "order type: limit, market, ioc or fok")
+	cmd.Flags().StringVar(&maxSpendArg, flagMaxSpend, "0", "maximum quote amount a market bid may spend")
+	return cmd
 }
 
 func GetCmdCancel(cdc *codec.Codec) *cobra.Command {
@@ -91,3 +111,232 @@ func GetCmdCancel(cdc *codec.Codec) *cobra.Command {
 		},
 	}
 }
+
+func GetCmdCancelAll(cdc *codec.Codec) *cobra.Command {
+	var marketIDArg string
+	cmd := &cobra.Command{
+		Use:   "cancel-all",
+		Short: "cancels all of your orders, optionally only those in --market-id",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := client.NewCLIContext().WithCodec(cdc)
+			accGetter := authtypes.NewAccountRetriever(cliCtx)
+			if err := accGetter.EnsureExists(cliCtx.GetFromAddress()); err != nil {
+				return err
+			}
+			bldr := authtypes.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			marketID := store.NewEntityIDFromString(marketIDArg)
+			msg := types.NewMsgCancelAll(cliCtx.GetFromAddress(), marketID)
+			return cliutil.ValidateAndBroadcast(cliCtx, bldr, msg)
+		},
+	}
+	cmd.Flags().StringVar(&marketIDArg, flagMarketID, "0", "only cancel orders in this market")
+	return cmd
+}
+
+func GetCmdBatchPost(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "batch-post [orders-file]",
+		Short: "posts the orders in a JSON file atomically",
+		Long: `Posts every order in the file in a single message. If any order fails, none
+are posted. The file holds a JSON array of orders with the same fields as a
+post message, e.g.:
+
+[{"market_id":"1","direction":"BID","price":"100000000","quantity":"500000000","time_in_force":600}]`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := client.NewCLIContext().WithCodec(cdc)
+			accGetter := authtypes.NewAccountRetriever(cliCtx)
+			if err := accGetter.EnsureExists(cliCtx.GetFromAddress()); err != nil {
+				return err
+			}
+			bldr := authtypes.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			b, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			var orders []types.PostRequest
+			if err := cdc.UnmarshalJSON(b, &orders); err != nil {
+				return err
+			}
+			for i := range orders {
+				if orders[i].MaxSpend.String() == "<nil>" {
+					orders[i].MaxSpend = sdk.ZeroUint()
+				}
+			}
+
+			msg := types.NewMsgBatchPost(cliCtx.GetFromAddress(), orders)
+			return cliutil.ValidateAndBroadcast(cliCtx, bldr, msg)
+		},
+	}
+}
+
+func GetCmdBatchCancel(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "batch-cancel [order-id]...",
+		Short: "cancels several orders atomically",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := client.NewCLIContext().WithCodec(cdc)
+			accGetter := authtypes.NewAccountRetriever(cliCtx)
+			if err := accGetter.EnsureExists(cliCtx.GetFromAddress()); err != nil {
+				return err
+			}
+			bldr := authtypes.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			orderIDs := make([]store.EntityID, len(args))
+			for i, arg := range args {
+				orderIDs[i] = store.NewEntityIDFromString(arg)
+			}
+			msg := types.NewMsgBatchCancel(cliCtx.GetFromAddress(), orderIDs)
+			return cliutil.ValidateAndBroadcast(cliCtx, bldr, msg)
+		},
+	}
+}
+
+func GetCmdAmend(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "amend [order-id] [price] [quantity]",
+		Short: "reprices an order or reduces its unfilled quantity",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := client.NewCLIContext().WithCodec(cdc)
+			accGetter := authtypes.NewAccountRetriever(cliCtx)
+			if err := accGetter.EnsureExists(cliCtx.GetFromAddress()); err != nil {
+				return err
+			}
+			bldr := authtypes.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			orderID := store.NewEntityIDFromString(args[0])
+			price, err := sdk.ParseUint(args[1])
+			if err != nil {
+				return err
+			}
+			quantity, err := sdk.ParseUint(args[2])
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgAmend(cliCtx.GetFromAddress(), orderID, price, quantity)
+			return cliutil.ValidateAndBroadcast(cliCtx, bldr, msg)
+		},
+	}
+}
+
+func GetCmdPostConditional(cdc *codec.Codec) *cobra.Command {
+	var orderTypeArg string
+	var maxSpendArg string
+	var triggerArg string
+	cmd := &cobra.Command{
+		Use:   "post-conditional [market-id] [direction] [stop-loss|take-profit] [trigger-price] [price] [quantity] [time-in-force-blocks]",
+		Short: "posts a stop-loss or take-profit order",
+		Long: `Posts an order that stays dormant until a batch clearing price crosses the
+trigger price, or the market's oracle price with --trigger oracle. A stop-loss
+ask or take-profit bid fires when the price falls to the trigger; a stop-loss
+bid or take-profit ask fires when it rises to it. Funds are escrowed
+immediately. The --type and --max-spend flags describe the order that is
+posted once triggered, as for the post command.`,
+		Args: cobra.ExactArgs(7),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := client.NewCLIContext().WithCodec(cdc)
+			accGetter := authtypes.NewAccountRetriever(cliCtx)
+			if err := accGetter.EnsureExists(cliCtx.GetFromAddress()); err != nil {
+				return err
+			}
+			bldr := authtypes.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			marketID := store.NewEntityIDFromString(args[0])
+			direction, err := parseDirection(args[1])
+			if err != nil {
+				return err
+			}
+			kind, err := types.NewTriggerKindFromString(strings.ToUpper(strings.Replace(args[2], "-", "_", -1)))
+			if err != nil {
+				return err
+			}
+			source, err := types.NewTriggerSourceFromString(strings.ToUpper(triggerArg) + "_PRICE")
+			if err != nil {
+				return err
+			}
+			triggerPrice, err := sdk.ParseUint(args[3])
+			if err != nil {
+				return err
+			}
+			price, err := sdk.ParseUint(args[4])
+			if err != nil {
+				return err
+			}
+			quantity, err := sdk.ParseUint(args[5])
+			if err != nil {
+				return err
+			}
+			tif, err := strconv.ParseUint(args[6], 10, 64)
+			if err != nil {
+				return err
+			}
+			if tif > math.MaxUint16 {
+				return errors.New("time in force too large")
+			}
+			orderType, err := matcheng.NewOrderTypeFromString(strings.ToUpper(orderTypeArg))
+			if err != nil {
+				return err
+			}
+			maxSpend, err := sdk.ParseUint(maxSpendArg)
+			if err != nil {
+				return err
+			}
+
+			msg := types.NewMsgPostConditional(cliCtx.GetFromAddress(), marketID, direction, kind, source, triggerPrice, orderType, price, quantity, maxSpend, uint16(tif))
+			return cliutil.ValidateAndBroadcast(cliCtx, bldr, msg)
+		},
+	}
+	cmd.Flags().StringVar(&orderTypeArg, flagType, "limit", "order type once triggered: limit, market, ioc or fok")
+	cmd.Flags().StringVar(&triggerArg, flagTrigger, "last", "price the order is triggered by: last or oracle")
+	cmd.Flags().StringVar(&maxSpendArg, flagMaxSpend, "0", "maximum quote amount a market bid may spend")
+	return cmd
+}
+
+func GetCmdCancelConditional(cdc *codec.Codec) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel-conditional [conditional-id]",
+		Short: "cancels a stop-loss or take-profit order that has not been triggered",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			inBuf := bufio.NewReader(cmd.InOrStdin())
+			cliCtx := client.NewCLIContext().WithCodec(cdc)
+			accGetter := authtypes.NewAccountRetriever(cliCtx)
+			if err := accGetter.EnsureExists(cliCtx.GetFromAddress()); err != nil {
+				return err
+			}
+			bldr := authtypes.NewTxBuilderFromCLI(inBuf).WithTxEncoder(utils.GetTxEncoder(cdc))
+
+			conditionalID := store.NewEntityIDFromString(args[0])
+			msg := types.NewMsgCancelConditional(cliCtx.GetFromAddress(), conditionalID)
+			return cliutil.ValidateAndBroadcast(cliCtx, bldr, msg)
+		},
+	}
+}
+
+func parseDirection(arg string) (matcheng.Direction, error) {
+	switch strings.ToLower(arg) {
+	case "bid":
+		return matcheng.Bid, nil
+	case "ask":
+		return matcheng.Ask, nil
+	default:
+		return matcheng.Bid, errors.New("invalid direction")
+	}
+}
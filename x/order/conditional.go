@@ -0,0 +1,288 @@
+package order
+
+import (
+	"github.com/xar-network/xar-network/pkg/conv"
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/types/store"
+	types2 "github.com/xar-network/xar-network/x/market/types"
+	types3 "github.com/xar-network/xar-network/x/order/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	condSeqKey   = "cseq"
+	condValKey   = "cval"
+	triggerKey   = "ctrig"
+	lastPriceKey = "clast"
+
+	triggerBelow       byte = 0
+	triggerAbove       byte = 1
+	oracleTriggerBelow byte = 2
+	oracleTriggerAbove byte = 3
+)
+
+type ConditionalIteratorCB func(cond types3.ConditionalOrder) bool
+
+// PostConditional escrows the owner's funds and stores a dormant order that
+// is activated once the price given by source crosses triggerPrice. Orders
+// can only be triggered by the oracle price in markets that have an oracle
+// feed.
+func (k Keeper) PostConditional(ctx sdk.Context, owner sdk.AccAddress, mktID store.EntityID, direction matcheng.Direction, kind types3.TriggerKind, source types3.TriggerSource, triggerPrice sdk.Uint, orderType matcheng.OrderType, price sdk.Uint, quantity sdk.Uint, maxSpend sdk.Uint, tif uint16) (types3.ConditionalOrder, sdk.Error) {
+	mkt, err := k.marketKeeper.Get(ctx, mktID)
+	if err != nil {
+		return types3.ConditionalOrder{}, err
+	}
+	if !kind.IsValid() {
+		return types3.ConditionalOrder{}, sdk.ErrUnknownRequest("invalid trigger kind")
+	}
+	if !source.IsValid() {
+		return types3.ConditionalOrder{}, sdk.ErrUnknownRequest("invalid trigger source")
+	}
+	if _, ok := k.oracleAsset(ctx, mkt); source == types3.OraclePrice && !ok {
+		return types3.ConditionalOrder{}, sdk.ErrUnknownRequest("market has no oracle price feed")
+	}
+	if err := checkMarketStatus(mkt, orderType); err != nil {
+		return types3.ConditionalOrder{}, err
+	}
+	price, err = effectivePrice(mkt, orderType, direction, price, quantity, maxSpend)
+	if err != nil {
+		return types3.ConditionalOrder{}, err
+	}
+	if err := checkTradingRules(mkt, orderType, direction, price, quantity); err != nil {
+		return types3.ConditionalOrder{}, err
+	}
+	if !orderType.Rests() {
+		tif = 0
+	}
+
+	if err := k.escrow(ctx, owner, mkt, direction, price, quantity); err != nil {
+		return types3.ConditionalOrder{}, err
+	}
+
+	cond := types3.ConditionalOrder{
+		ID:                store.IncrementSeq(ctx, k.storeKey, []byte(condSeqKey)),
+		Owner:             owner,
+		MarketID:          mktID,
+		Direction:         direction,
+		Kind:              kind,
+		TriggerPrice:      triggerPrice,
+		Type:              orderType,
+		Price:             price,
+		Quantity:          quantity,
+		TimeInForceBlocks: tif,
+		CreatedBlock:      ctx.BlockHeight(),
+		Source:            source,
+	}
+	if err := store.SetNotExists(ctx, k.storeKey, k.cdc, conditionalKey(cond.ID), cond); err != nil {
+		return types3.ConditionalOrder{}, err
+	}
+	ctx.KVStore(k.storeKey).Set(triggerIndexKey(cond), cond.ID.Bytes())
+	return cond, nil
+}
+
+func (k Keeper) GetConditional(ctx sdk.Context, id store.EntityID) (types3.ConditionalOrder, sdk.Error) {
+	var out types3.ConditionalOrder
+	err := store.Get(ctx, k.storeKey, k.cdc, conditionalKey(id), &out)
+	return out, err
+}
+
+func (k Keeper) HasConditional(ctx sdk.Context, id store.EntityID) bool {
+	return store.Has(ctx, k.storeKey, conditionalKey(id))
+}
+
+// CancelConditional removes a conditional order that has not been triggered
+// yet and refunds its escrow.
+func (k Keeper) CancelConditional(ctx sdk.Context, id store.EntityID) sdk.Error {
+	cond, err := k.GetConditional(ctx, id)
+	if err != nil {
+		return err
+	}
+	mkt, err := k.marketKeeper.Get(ctx, cond.MarketID)
+	if err != nil {
+		// should never happen; implies consensus
+		// or storage bug
+		panic(err)
+	}
+
+	k.refund(ctx, cond.Owner, mkt, cond.Direction, cond.Price, cond.Quantity)
+	return k.delConditional(ctx, cond)
+}
+
+func (k Keeper) ConditionalIterator(ctx sdk.Context, cb ConditionalIteratorCB) {
+	kv := ctx.KVStore(k.storeKey)
+	iter := sdk.KVStorePrefixIterator(kv, []byte(condValKey))
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		var cond types3.ConditionalOrder
+		k.cdc.MustUnmarshalBinaryBare(iter.Value(), &cond)
+
+		if !cb(cond) {
+			break
+		}
+	}
+}
+
+// SetLastClearingPrice records the clearing price of the latest batch in a
+// market. It is what conditional orders are triggered against.
+func (k Keeper) SetLastClearingPrice(ctx sdk.Context, mktID store.EntityID, price sdk.Uint) {
+	store.Set(ctx, k.storeKey, k.cdc, lastClearingPriceKey(mktID), price)
+}
+
+func (k Keeper) LastClearingPrice(ctx sdk.Context, mktID store.EntityID) (sdk.Uint, bool) {
+	var out sdk.Uint
+	if err := store.Get(ctx, k.storeKey, k.cdc, lastClearingPriceKey(mktID), &out); err != nil {
+		return sdk.Uint{}, false
+	}
+	return out, true
+}
+
+// OraclePrice returns the current price of the market's oracle feed, scaled
+// to the market's price decimals.
+func (k Keeper) OraclePrice(ctx sdk.Context, mkt types2.Market) (sdk.Uint, bool) {
+	assetCode, ok := k.oracleAsset(ctx, mkt)
+	if !ok {
+		return sdk.Uint{}, false
+	}
+	current := k.oracleKeeper.GetCurrentPrice(ctx, assetCode)
+	if current.Price.IsNil() || !current.Price.IsPositive() {
+		return sdk.Uint{}, false
+	}
+	scaled := current.Price.MulInt(sdk.NewIntWithDecimal(1, matcheng.PriceDecimals)).TruncateInt()
+	if !scaled.IsPositive() {
+		return sdk.Uint{}, false
+	}
+	return sdk.NewUintFromBigInt(scaled.BigInt()), true
+}
+
+// oracleAsset returns the code of the active oracle asset that prices the
+// market's base asset in its quote asset.
+func (k Keeper) oracleAsset(ctx sdk.Context, mkt types2.Market) (string, bool) {
+	for _, asset := range k.oracleKeeper.GetAssetParams(ctx) {
+		if asset.Active && asset.BaseAsset == mkt.BaseAssetDenom && asset.QuoteAsset == mkt.QuoteAssetDenom {
+			return asset.AssetCode, true
+		}
+	}
+	return "", false
+}
+
+// ActivateConditionals converts every conditional order whose trigger price
+// has been crossed by its market's last clearing price or oracle price, as
+// the order's source requires, into a live order.
+// The escrow taken when the conditional order was posted backs the new order,
+// so no funds move. Markets that are not matching are skipped. It returns the
+// IDs of the orders that were created.
+func (k Keeper) ActivateConditionals(ctx sdk.Context) ([]store.EntityID, sdk.Error) {
+	var triggered []store.EntityID
+	k.marketKeeper.Iterator(ctx, func(mkt types2.Market) bool {
+		if !mkt.Status.Matches() {
+			return true
+		}
+		if price, ok := k.LastClearingPrice(ctx, mkt.ID); ok {
+			triggered = append(triggered, k.triggeredBy(ctx, mkt.ID, types3.LastPrice, price)...)
+		}
+		if price, ok := k.OraclePrice(ctx, mkt); ok {
+			triggered = append(triggered, k.triggeredBy(ctx, mkt.ID, types3.OraclePrice, price)...)
+		}
+		return true
+	})
+
+	var created []store.EntityID
+	for _, id := range triggered {
+		cond, err := k.GetConditional(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := k.delConditional(ctx, cond); err != nil {
+			return nil, err
+		}
+		ord, err := k.Create(
+			ctx,
+			cond.Owner,
+			cond.MarketID,
+			cond.Type,
+			cond.Direction,
+			cond.Price,
+			cond.Quantity,
+			cond.TimeInForceBlocks,
+		)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info(
+			"activated conditional order",
+			"conditional_id", cond.ID.String(),
+			"order_id", ord.ID.String(),
+			"kind", cond.Kind.String(),
+			"trigger_source", cond.Source.String(),
+			"trigger_price", cond.TriggerPrice.String(),
+		)
+		created = append(created, ord.ID)
+	}
+	return created, nil
+}
+
+// triggeredBy walks the trigger index of a market for orders triggered by
+// source. Orders that fire on the way down are those whose trigger price is
+// at or above the price, and orders that fire on the way up are those at or
+// below it, so both are a single contiguous range of the index.
+func (k Keeper) triggeredBy(ctx sdk.Context, mktID store.EntityID, source types3.TriggerSource, price sdk.Uint) []store.EntityID {
+	kv := ctx.KVStore(k.storeKey)
+	var out []store.EntityID
+	collect := func(iter sdk.Iterator) {
+		defer iter.Close()
+		for ; iter.Valid(); iter.Next() {
+			out = append(out, store.NewEntityIDFromBytes(iter.Value()))
+		}
+	}
+
+	belowSide, aboveSide := triggerSides(source)
+	below := triggerPrefix(mktID, belowSide)
+	collect(kv.Iterator(store.PrefixKeyBytes(below, priceSubkey(price)), sdk.PrefixEndBytes(below)))
+	above := triggerPrefix(mktID, aboveSide)
+	collect(kv.Iterator(above, store.PrefixKeyBytes(above, priceSubkey(price.Add(sdk.OneUint())))))
+	return out
+}
+
+func (k Keeper) delConditional(ctx sdk.Context, cond types3.ConditionalOrder) sdk.Error {
+	ctx.KVStore(k.storeKey).Delete(triggerIndexKey(cond))
+	return store.Del(ctx, k.storeKey, conditionalKey(cond.ID))
+}
+
+func conditionalKey(id store.EntityID) []byte {
+	return store.PrefixKeyString(condValKey, id.Bytes())
+}
+
+func lastClearingPriceKey(mktID store.EntityID) []byte {
+	return store.PrefixKeyString(lastPriceKey, mktID.Bytes())
+}
+
+func triggerPrefix(mktID store.EntityID, side byte) []byte {
+	return store.PrefixKeyString(triggerKey, mktID.Bytes(), []byte{side})
+}
+
+// triggerSides returns the index sides of orders that are triggered by source
+// on the way down and on the way up.
+func triggerSides(source types3.TriggerSource) (byte, byte) {
+	if source == types3.OraclePrice {
+		return oracleTriggerBelow, oracleTriggerAbove
+	}
+	return triggerBelow, triggerAbove
+}
+
+func triggerIndexKey(cond types3.ConditionalOrder) []byte {
+	side, aboveSide := triggerSides(cond.Source)
+	if cond.TriggersAbove() {
+		side = aboveSide
+	}
+	return store.PrefixKeyBytes(triggerPrefix(cond.MarketID, side), priceSubkey(cond.TriggerPrice), cond.ID.Bytes())
+}
+
+// priceSubkey encodes a price as fixed-width big-endian bytes so that the
+// trigger index sorts by price.
+func priceSubkey(price sdk.Uint) []byte {
+	var buf [32]byte
+	b := conv.SDKUint2Big(price).Bytes()
+	copy(buf[32-len(b):], b)
+	return buf[:]
+}
@@ -0,0 +1,150 @@
+package order_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/testutil"
+	"github.com/xar-network/xar-network/testutil/testflags"
+	"github.com/xar-network/xar-network/types/errs"
+	"github.com/xar-network/xar-network/x/oracle"
+	types4 "github.com/xar-network/xar-network/x/order/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestKeeper_PostConditional(t *testing.T) {
+	testflags.UnitTest(t)
+	t.Run("returns an error for a nonexistent market", func(t *testing.T) {
+		ctx := setupTest(t)
+		_, err := ctx.app.OrderKeeper.PostConditional(ctx.ctx, ctx.seller, ctx.marketID.Inc(), matcheng.Ask, types4.StopLoss, types4.LastPrice, testutil.ToBaseUnits(1), matcheng.Limit, testutil.ToBaseUnits(1), testutil.ToBaseUnits(10), sdk.ZeroUint(), 599)
+		assert.Error(t, err)
+		assert.Equal(t, err.Code(), errs.CodeNotFound)
+	})
+	t.Run("escrows funds without posting an order", func(t *testing.T) {
+		ctx := setupTest(t)
+		cond, err := ctx.app.OrderKeeper.PostConditional(ctx.ctx, ctx.seller, ctx.marketID, matcheng.Ask, types4.StopLoss, types4.LastPrice, testutil.ToBaseUnits(1), matcheng.Limit, testutil.ToBaseUnits(1), testutil.ToBaseUnits(10), sdk.ZeroUint(), 599)
+		require.NoError(t, err)
+		retrieved, err := ctx.app.OrderKeeper.GetConditional(ctx.ctx, cond.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, cond, retrieved)
+
+		bal := sdk.NewUintFromBigInt(ctx.app.BankKeeper.GetCoins(ctx.ctx, ctx.seller).AmountOf(ctx.asset1).BigInt())
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(90), bal)
+		var count int
+		ctx.app.OrderKeeper.Iterator(ctx.ctx, func(types4.Order) bool {
+			count++
+			return true
+		})
+		assert.Equal(t, 0, count)
+	})
+	t.Run("refunds the escrow on cancellation", func(t *testing.T) {
+		ctx := setupTest(t)
+		cond, err := ctx.app.OrderKeeper.PostConditional(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, types4.TakeProfit, types4.LastPrice, testutil.ToBaseUnits(1), matcheng.Limit, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), sdk.ZeroUint(), 599)
+		require.NoError(t, err)
+		require.NoError(t, ctx.app.OrderKeeper.CancelConditional(ctx.ctx, cond.ID))
+		assert.False(t, ctx.app.OrderKeeper.HasConditional(ctx.ctx, cond.ID))
+		bal := sdk.NewUintFromBigInt(ctx.app.BankKeeper.GetCoins(ctx.ctx, ctx.buyer).AmountOf(ctx.asset2).BigInt())
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), bal)
+	})
+}
+
+func TestKeeper_ActivateConditionals(t *testing.T) {
+	testflags.UnitTest(t)
+	ctx := setupTest(t)
+	k := ctx.app.OrderKeeper
+	post := func(direction matcheng.Direction, kind types4.TriggerKind, trigger uint64) types4.ConditionalOrder {
+		owner := ctx.seller
+		if direction == matcheng.Bid {
+			owner = ctx.buyer
+		}
+		cond, err := k.PostConditional(ctx.ctx, owner, ctx.marketID, direction, kind, types4.LastPrice, testutil.ToBaseUnits(trigger), matcheng.Limit, testutil.ToBaseUnits(2), testutil.ToBaseUnits(1), sdk.ZeroUint(), 599)
+		require.NoError(t, err)
+		return cond
+	}
+	stopAsk := post(matcheng.Ask, types4.StopLoss, 3)
+	deepStopAsk := post(matcheng.Ask, types4.StopLoss, 1)
+	profitAsk := post(matcheng.Ask, types4.TakeProfit, 5)
+	stopBid := post(matcheng.Bid, types4.StopLoss, 5)
+	profitBid := post(matcheng.Bid, types4.TakeProfit, 3)
+
+	created, err := k.ActivateConditionals(ctx.ctx)
+	require.NoError(t, err)
+	assert.Empty(t, created, "nothing triggers without a clearing price")
+
+	k.SetLastClearingPrice(ctx.ctx, ctx.marketID, testutil.ToBaseUnits(3))
+	created, err = k.ActivateConditionals(ctx.ctx)
+	require.NoError(t, err)
+	assert.Len(t, created, 2)
+	assert.False(t, k.HasConditional(ctx.ctx, stopAsk.ID))
+	assert.False(t, k.HasConditional(ctx.ctx, profitBid.ID))
+	assert.True(t, k.HasConditional(ctx.ctx, deepStopAsk.ID))
+	assert.True(t, k.HasConditional(ctx.ctx, profitAsk.ID))
+	assert.True(t, k.HasConditional(ctx.ctx, stopBid.ID))
+	for _, id := range created {
+		ord, err := k.Get(ctx.ctx, id)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(2), ord.Price)
+	}
+
+	k.SetLastClearingPrice(ctx.ctx, ctx.marketID, testutil.ToBaseUnits(5))
+	created, err = k.ActivateConditionals(ctx.ctx)
+	require.NoError(t, err)
+	assert.Len(t, created, 2)
+	assert.False(t, k.HasConditional(ctx.ctx, profitAsk.ID))
+	assert.False(t, k.HasConditional(ctx.ctx, stopBid.ID))
+	assert.True(t, k.HasConditional(ctx.ctx, deepStopAsk.ID))
+}
+
+func TestKeeper_ActivateConditionalsByOraclePrice(t *testing.T) {
+	testflags.UnitTest(t)
+	ctx := setupTest(t)
+	k := ctx.app.OrderKeeper
+	post := func() (types4.ConditionalOrder, sdk.Error) {
+		return k.PostConditional(ctx.ctx, ctx.seller, ctx.marketID, matcheng.Ask, types4.StopLoss, types4.OraclePrice, testutil.ToBaseUnits(3), matcheng.Limit, testutil.ToBaseUnits(2), testutil.ToBaseUnits(1), sdk.ZeroUint(), 599)
+	}
+
+	_, err := post()
+	assert.Error(t, err, "market has no oracle feed")
+
+	oracleAddr := testutil.RandAddr()
+	asset := oracle.Asset{
+		AssetCode:  "tst",
+		BaseAsset:  ctx.asset1,
+		QuoteAsset: ctx.asset2,
+		Oracles:    oracle.Oracles{{Address: oracleAddr}},
+		Active:     true,
+	}
+	ctx.app.OracleKeeper.SetParams(ctx.ctx, oracle.NewParams(oracle.Assets{asset}, nil))
+	setPrice := func(price string) {
+		_, err := ctx.app.OracleKeeper.SetPrice(ctx.ctx, oracleAddr, "tst", sdk.MustNewDecFromStr(price), ctx.ctx.BlockTime().Add(time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, ctx.app.OracleKeeper.SetCurrentPrices(ctx.ctx))
+	}
+
+	cond, err := post()
+	require.NoError(t, err)
+	assert.Equal(t, types4.OraclePrice, cond.Source)
+
+	created, err := k.ActivateConditionals(ctx.ctx)
+	require.NoError(t, err)
+	assert.Empty(t, created, "nothing triggers without an oracle price")
+
+	// the last clearing price does not trigger oracle-priced orders
+	k.SetLastClearingPrice(ctx.ctx, ctx.marketID, testutil.ToBaseUnits(1))
+	setPrice("3.5")
+	created, err = k.ActivateConditionals(ctx.ctx)
+	require.NoError(t, err)
+	assert.Empty(t, created)
+	assert.True(t, k.HasConditional(ctx.ctx, cond.ID))
+
+	setPrice("2.9")
+	created, err = k.ActivateConditionals(ctx.ctx)
+	require.NoError(t, err)
+	assert.Len(t, created, 1)
+	assert.False(t, k.HasConditional(ctx.ctx, cond.ID))
+}
@@ -2,11 +2,12 @@ package order
 
 import (
 	"fmt"
+	"strings"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
 	"github.com/xar-network/xar-network/pkg/log"
-	"github.com/xar-network/xar-network/types/errs"
+	"github.com/xar-network/xar-network/types/store"
 	"github.com/xar-network/xar-network/x/order/types"
 )
 
@@ -19,6 +20,18 @@ func NewHandler(keeper Keeper) sdk.Handler {
 			return handleMsgPost(ctx, keeper, msg)
 		case types.MsgCancel:
 			return handleMsgCancel(ctx, keeper, msg)
+		case types.MsgCancelAll:
+			return handleMsgCancelAll(ctx, keeper, msg)
+		case types.MsgBatchPost:
+			return handleMsgBatchPost(ctx, keeper, msg)
+		case types.MsgBatchCancel:
+			return handleMsgBatchCancel(ctx, keeper, msg)
+		case types.MsgAmend:
+			return handleMsgAmend(ctx, keeper, msg)
+		case types.MsgPostConditional:
+			return handleMsgPostConditional(ctx, keeper, msg)
+		case types.MsgCancelConditional:
+			return handleMsgCancelConditional(ctx, keeper, msg)
 		default:
 			return sdk.ErrUnknownRequest(fmt.Sprintf("unknown message type %v", msg.Type())).Result()
 		}
@@ -26,13 +39,15 @@ func NewHandler(keeper Keeper) sdk.Handler {
 }
 
 func handleMsgPost(ctx sdk.Context, keeper Keeper, msg types.MsgPost) sdk.Result {
-	order, err := keeper.Post(
+	order, err := keeper.PostWithType(
 		ctx,
 		msg.Owner,
 		msg.MarketID,
+		msg.OrderType,
 		msg.Direction,
 		msg.Price,
 		msg.Quantity,
+		msg.MaxSpend,
 		msg.TimeInForce,
 	)
 
@@ -44,9 +59,12 @@ func handleMsgPost(ctx sdk.Context, keeper Keeper, msg types.MsgPost) sdk.Result
 			"price", order.Price.String(),
 			"quantity", order.Quantity.String(),
 			"direction", order.Direction.String(),
+			"type", order.Type.String(),
 		)
+		emitOrderEvent(ctx, types.EventTypePostOrder, msg.Owner, order.ID, order.MarketID)
 		return sdk.Result{
-			Log: fmt.Sprintf("order_id:%s", order.ID),
+			Log:    fmt.Sprintf("order_id:%s", order.ID),
+			Events: ctx.EventManager().Events(),
 		}
 	}
 
@@ -61,5 +79,206 @@ func handleMsgCancel(ctx sdk.Context, keeper Keeper, msg types.MsgCancel) sdk.Re
 	if !order.Owner.Equals(msg.Owner) {
 		return sdk.ErrUnauthorized("cannot cancel unowned order").Result()
 	}
-	return errs.ErrOrBlankResult(keeper.Del(ctx, order.ID))
+	if err := keeper.Cancel(ctx, order.ID); err != nil {
+		return err.Result()
+	}
+	emitOrderEvent(ctx, types.EventTypeCancelOrder, msg.Owner, order.ID, order.MarketID)
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+// Batch handlers return on the first failure. The SDK only commits a
+// message's state changes when its handler succeeds, so either every order in
+// a batch is processed or none is. On success the log has one line per order.
+
+func handleMsgCancelAll(ctx sdk.Context, keeper Keeper, msg types.MsgCancelAll) sdk.Result {
+	cancelled, err := keeper.CancelAll(ctx, msg.Owner, msg.MarketID)
+	if err != nil {
+		return err.Result()
+	}
+	logger.Info(
+		"cancelled all orders",
+		"owner", msg.Owner.String(),
+		"market_id", msg.MarketID.String(),
+		"count", len(cancelled),
+	)
+	ids := make([]store.EntityID, 0, len(cancelled))
+	for _, ord := range cancelled {
+		ids = append(ids, ord.ID)
+		emitOrderEvent(ctx, types.EventTypeCancelOrder, msg.Owner, ord.ID, ord.MarketID)
+	}
+	return sdk.Result{
+		Log:    batchLog("cancelled_order_id", ids),
+		Events: ctx.EventManager().Events(),
+	}
+}
+
+func handleMsgBatchPost(ctx sdk.Context, keeper Keeper, msg types.MsgBatchPost) sdk.Result {
+	posted := make([]store.EntityID, 0, len(msg.Orders))
+	for i := range msg.Orders {
+		req := msg.Msg(i)
+		order, err := keeper.PostWithType(
+			ctx,
+			req.Owner,
+			req.MarketID,
+			req.OrderType,
+			req.Direction,
+			req.Price,
+			req.Quantity,
+			req.MaxSpend,
+			req.TimeInForce,
+		)
+		if err != nil {
+			return err.Result()
+		}
+		posted = append(posted, order.ID)
+		emitOrderEvent(ctx, types.EventTypePostOrder, msg.Owner, order.ID, order.MarketID)
+	}
+	logger.Info("posted order batch", "owner", msg.Owner.String(), "count", len(posted))
+	return sdk.Result{
+		Log:    batchLog("order_id", posted),
+		Events: ctx.EventManager().Events(),
+	}
+}
+
+func handleMsgBatchCancel(ctx sdk.Context, keeper Keeper, msg types.MsgBatchCancel) sdk.Result {
+	mktIDs := make([]store.EntityID, len(msg.OrderIDs))
+	for i, id := range msg.OrderIDs {
+		order, err := keeper.Get(ctx, id)
+		if err != nil {
+			return err.Result()
+		}
+		if !order.Owner.Equals(msg.Owner) {
+			return sdk.ErrUnauthorized("cannot cancel unowned order").Result()
+		}
+		mktIDs[i] = order.MarketID
+	}
+	for i, id := range msg.OrderIDs {
+		if err := keeper.Cancel(ctx, id); err != nil {
+			return err.Result()
+		}
+		emitOrderEvent(ctx, types.EventTypeCancelOrder, msg.Owner, id, mktIDs[i])
+	}
+	logger.Info("cancelled order batch", "owner", msg.Owner.String(), "count", len(msg.OrderIDs))
+	return sdk.Result{
+		Log:    batchLog("cancelled_order_id", msg.OrderIDs),
+		Events: ctx.EventManager().Events(),
+	}
+}
+
+func batchLog(key string, ids []store.EntityID) string {
+	lines := make([]string, len(ids))
+	for i, id := range ids {
+		lines[i] = fmt.Sprintf("%s:%s", key, id)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func handleMsgAmend(ctx sdk.Context, keeper Keeper, msg types.MsgAmend) sdk.Result {
+	order, err := keeper.Get(ctx, msg.OrderID)
+	if err != nil {
+		return err.Result()
+	}
+	if !order.Owner.Equals(msg.Owner) {
+		return sdk.ErrUnauthorized("cannot amend unowned order").Result()
+	}
+	if !order.Type.Rests() {
+		return sdk.ErrUnknownRequest("only resting orders can be amended").Result()
+	}
+	order, err = keeper.Amend(ctx, order.ID, msg.Price, msg.Quantity)
+	if err != nil {
+		return err.Result()
+	}
+	logger.Info(
+		"amended order",
+		"id", order.ID.String(),
+		"price", order.Price.String(),
+		"quantity", order.Quantity.String(),
+	)
+	emitOrderEvent(ctx, types.EventTypeAmendOrder, msg.Owner, order.ID, order.MarketID)
+	return sdk.Result{
+		Log:    fmt.Sprintf("order_id:%s", order.ID),
+		Events: ctx.EventManager().Events(),
+	}
+}
+
+func handleMsgPostConditional(ctx sdk.Context, keeper Keeper, msg types.MsgPostConditional) sdk.Result {
+	cond, err := keeper.PostConditional(
+		ctx,
+		msg.Owner,
+		msg.MarketID,
+		msg.Direction,
+		msg.Kind,
+		msg.TriggerSource,
+		msg.TriggerPrice,
+		msg.OrderType,
+		msg.Price,
+		msg.Quantity,
+		msg.MaxSpend,
+		msg.TimeInForce,
+	)
+
+	if err == nil {
+		logger.Info(
+			"posted conditional order",
+			"id", cond.ID.String(),
+			"market_id", cond.MarketID.String(),
+			"kind", cond.Kind.String(),
+			"trigger_source", cond.Source.String(),
+			"trigger_price", cond.TriggerPrice.String(),
+			"direction", cond.Direction.String(),
+			"type", cond.Type.String(),
+		)
+		emitConditionalEvent(ctx, types.EventTypePostConditionalOrder, msg.Owner, cond.ID, cond.MarketID)
+		return sdk.Result{
+			Log:    fmt.Sprintf("conditional_id:%s", cond.ID),
+			Events: ctx.EventManager().Events(),
+		}
+	}
+
+	return err.Result()
+}
+
+func handleMsgCancelConditional(ctx sdk.Context, keeper Keeper, msg types.MsgCancelConditional) sdk.Result {
+	cond, err := keeper.GetConditional(ctx, msg.ConditionalID)
+	if err != nil {
+		return err.Result()
+	}
+	if !cond.Owner.Equals(msg.Owner) {
+		return sdk.ErrUnauthorized("cannot cancel unowned conditional order").Result()
+	}
+	if err := keeper.CancelConditional(ctx, cond.ID); err != nil {
+		return err.Result()
+	}
+	emitConditionalEvent(ctx, types.EventTypeCancelConditionalOrder, msg.Owner, cond.ID, cond.MarketID)
+	return sdk.Result{Events: ctx.EventManager().Events()}
+}
+
+// emitOrderEvent emits an event naming the order a message affected, so
+// clients can read order IDs from a transaction's events. The market ID is
+// left out when it is not defined.
+func emitOrderEvent(ctx sdk.Context, eventType string, owner sdk.AccAddress, id store.EntityID, mktID store.EntityID) {
+	attrs := []sdk.Attribute{sdk.NewAttribute(types.AttributeKeyOrderID, id.String())}
+	if mktID.IsDefined() {
+		attrs = append(attrs, sdk.NewAttribute(types.AttributeKeyMarketID, mktID.String()))
+	}
+	emitEvent(ctx, sdk.NewEvent(eventType, attrs...), owner)
+}
+
+func emitConditionalEvent(ctx sdk.Context, eventType string, owner sdk.AccAddress, id store.EntityID, mktID store.EntityID) {
+	emitEvent(ctx, sdk.NewEvent(
+		eventType,
+		sdk.NewAttribute(types.AttributeKeyConditionalID, id.String()),
+		sdk.NewAttribute(types.AttributeKeyMarketID, mktID.String()),
+	), owner)
+}
+
+func emitEvent(ctx sdk.Context, event sdk.Event, owner sdk.AccAddress) {
+	ctx.EventManager().EmitEvents(sdk.Events{
+		event,
+		sdk.NewEvent(
+			sdk.EventTypeMessage,
+			sdk.NewAttribute(sdk.AttributeKeyModule, types.AttributeValueCategory),
+			sdk.NewAttribute(sdk.AttributeKeySender, owner.String()),
+		),
+	})
 }
@@ -0,0 +1,123 @@
+package order_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/testutil"
+	"github.com/xar-network/xar-network/testutil/testflags"
+	"github.com/xar-network/xar-network/types/store"
+	"github.com/xar-network/xar-network/x/order"
+	types4 "github.com/xar-network/xar-network/x/order/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestHandler_BatchPost(t *testing.T) {
+	testflags.UnitTest(t)
+	ctx := setupTest(t)
+	handler := order.NewHandler(ctx.app.OrderKeeper)
+	req := types4.PostRequest{
+		MarketID:    ctx.marketID,
+		Direction:   matcheng.Bid,
+		Price:       testutil.ToBaseUnits(2),
+		Quantity:    testutil.ToBaseUnits(10),
+		TimeInForce: 599,
+		MaxSpend:    sdk.ZeroUint(),
+	}
+
+	res := handler(ctx.ctx, types4.NewMsgBatchPost(ctx.buyer, []types4.PostRequest{req, req}))
+	require.True(t, res.IsOK(), res.Log)
+	assert.Equal(t, "order_id:1\norder_id:2", res.Log)
+	assert.True(t, ctx.app.OrderKeeper.Has(ctx.ctx, store.NewEntityID(2)))
+}
+
+func TestHandler_Cancel(t *testing.T) {
+	testflags.UnitTest(t)
+	ctx := setupTest(t)
+	handler := order.NewHandler(ctx.app.OrderKeeper)
+	balance := func() sdk.Int {
+		return ctx.app.BankKeeper.GetCoins(ctx.ctx, ctx.buyer).AmountOf(ctx.asset2)
+	}
+	before := balance()
+	ord, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+	require.NoError(t, err)
+	require.True(t, balance().LT(before))
+
+	t.Run("rejects an order that is not owned", func(t *testing.T) {
+		res := handler(ctx.ctx, types4.NewMsgCancel(ctx.seller, ord.ID))
+		assert.False(t, res.IsOK())
+		assert.True(t, ctx.app.OrderKeeper.Has(ctx.ctx, ord.ID))
+	})
+	t.Run("refunds the escrow of the order", func(t *testing.T) {
+		res := handler(ctx.ctx, types4.NewMsgCancel(ctx.buyer, ord.ID))
+		require.True(t, res.IsOK(), res.Log)
+		assert.False(t, ctx.app.OrderKeeper.Has(ctx.ctx, ord.ID))
+		assert.True(t, balance().Equal(before))
+	})
+}
+
+func TestHandler_BatchCancel(t *testing.T) {
+	testflags.UnitTest(t)
+	ctx := setupTest(t)
+	handler := order.NewHandler(ctx.app.OrderKeeper)
+	own, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+	require.NoError(t, err)
+	foreign, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.seller, ctx.marketID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+	require.NoError(t, err)
+
+	t.Run("cancels nothing if any order is not owned", func(t *testing.T) {
+		res := handler(ctx.ctx, types4.NewMsgBatchCancel(ctx.buyer, []store.EntityID{own.ID, foreign.ID}))
+		assert.False(t, res.IsOK())
+		assert.True(t, ctx.app.OrderKeeper.Has(ctx.ctx, own.ID))
+		assert.True(t, ctx.app.OrderKeeper.Has(ctx.ctx, foreign.ID))
+	})
+	t.Run("cancels every order in the batch", func(t *testing.T) {
+		res := handler(ctx.ctx, types4.NewMsgBatchCancel(ctx.buyer, []store.EntityID{own.ID}))
+		require.True(t, res.IsOK(), res.Log)
+		assert.Equal(t, "cancelled_order_id:1", res.Log)
+		assert.False(t, ctx.app.OrderKeeper.Has(ctx.ctx, own.ID))
+	})
+}
+
+func TestHandler_Events(t *testing.T) {
+	testflags.UnitTest(t)
+	ctx := setupTest(t)
+	handler := order.NewHandler(ctx.app.OrderKeeper)
+	event := func(res sdk.Result, eventType string) sdk.StringEvent {
+		for _, ev := range sdk.StringifyEvents(res.Events.ToABCIEvents()) {
+			if ev.Type == eventType {
+				return ev
+			}
+		}
+		require.FailNow(t, "event not found", eventType)
+		return sdk.StringEvent{}
+	}
+
+	res := handler(ctx.ctx.WithEventManager(sdk.NewEventManager()), types4.NewMsgPost(ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599))
+	require.True(t, res.IsOK(), res.Log)
+	assert.Equal(t, []sdk.Attribute{
+		sdk.NewAttribute(types4.AttributeKeyOrderID, "1"),
+		sdk.NewAttribute(types4.AttributeKeyMarketID, ctx.marketID.String()),
+	}, event(res, types4.EventTypePostOrder).Attributes)
+	assert.Contains(t, event(res, sdk.EventTypeMessage).Attributes, sdk.NewAttribute(sdk.AttributeKeyModule, types4.ModuleName))
+
+	res = handler(ctx.ctx.WithEventManager(sdk.NewEventManager()), types4.NewMsgCancel(ctx.buyer, store.NewEntityID(1)))
+	require.True(t, res.IsOK(), res.Log)
+	assert.Equal(t, []sdk.Attribute{
+		sdk.NewAttribute(types4.AttributeKeyOrderID, "1"),
+		sdk.NewAttribute(types4.AttributeKeyMarketID, ctx.marketID.String()),
+	}, event(res, types4.EventTypeCancelOrder).Attributes)
+
+	res = handler(ctx.ctx, types4.NewMsgPost(ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599))
+	require.True(t, res.IsOK(), res.Log)
+	res = handler(ctx.ctx.WithEventManager(sdk.NewEventManager()), types4.NewMsgCancelAll(ctx.buyer, store.ZeroEntityID))
+	require.True(t, res.IsOK(), res.Log)
+	assert.Equal(t, []sdk.Attribute{
+		sdk.NewAttribute(types4.AttributeKeyOrderID, "2"),
+		sdk.NewAttribute(types4.AttributeKeyMarketID, ctx.marketID.String()),
+	}, event(res, types4.EventTypeCancelOrder).Attributes)
+}
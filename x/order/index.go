@@ -0,0 +1,136 @@
+package order
+
+import (
+	"encoding/binary"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/types/store"
+	types3 "github.com/xar-network/xar-network/x/order/types"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	bookKey         = "book"
+	expiryKey       = "exp"
+	ownerKey        = "owner"
+	indexVersionKey = "idxver"
+
+	// indexVersion is the version of the order indexes. Stores written by an
+	// older version are reindexed by MigrateIndexes. Version 2 added the
+	// owner index.
+	indexVersion uint64 = 2
+)
+
+// BookIterator iterates over one side of a market's book, from the lowest
+// price to the highest and oldest first within a price.
+func (k Keeper) BookIterator(ctx sdk.Context, mktID store.EntityID, direction matcheng.Direction, cb IteratorCB) {
+	kv := ctx.KVStore(k.storeKey)
+	k.doIndexIterator(ctx, sdk.KVStorePrefixIterator(kv, bookPrefix(mktID, direction)), cb)
+}
+
+// ExpiringIterator iterates over every order whose time in force ended before
+// height, earliest expiry first. An order expires after the block
+// CreatedBlock + TimeInForceBlocks.
+func (k Keeper) ExpiringIterator(ctx sdk.Context, height int64, cb IteratorCB) {
+	kv := ctx.KVStore(k.storeKey)
+	start := []byte(expiryKey)
+	end := store.PrefixKeyString(expiryKey, heightSubkey(height))
+	k.doIndexIterator(ctx, kv.Iterator(start, end), cb)
+}
+
+// OwnerIterator iterates over the orders of owner, limited to a single market
+// when mktID is defined, ordered by market and then by ID.
+func (k Keeper) OwnerIterator(ctx sdk.Context, owner sdk.AccAddress, mktID store.EntityID, cb IteratorCB) {
+	kv := ctx.KVStore(k.storeKey)
+	prefix := ownerPrefix(owner)
+	if mktID.IsDefined() {
+		prefix = store.PrefixKeyBytes(prefix, mktID.Bytes())
+	}
+	k.doIndexIterator(ctx, sdk.KVStorePrefixIterator(kv, prefix), cb)
+}
+
+// doIndexIterator resolves the order IDs an index iterator yields. Orders are
+// loaded before the callback runs, so the callback may not modify the store.
+func (k Keeper) doIndexIterator(ctx sdk.Context, iter sdk.Iterator, cb IteratorCB) {
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		ord, err := k.Get(ctx, store.NewEntityIDFromBytes(iter.Value()))
+		if err != nil {
+			// should never happen; the index is written with the order
+			panic(err)
+		}
+
+		if !cb(ord) {
+			break
+		}
+	}
+}
+
+// MigrateIndexes builds the order indexes for every stored order if the store
+// was written before they all existed. Orders posted before then would
+// otherwise never be matched, expire or be found by owner. It returns the
+// number of orders that were indexed.
+func (k Keeper) MigrateIndexes(ctx sdk.Context) int {
+	kv := ctx.KVStore(k.storeKey)
+	if ver := kv.Get([]byte(indexVersionKey)); ver != nil && binary.BigEndian.Uint64(ver) >= indexVersion {
+		return 0
+	}
+
+	var orders []types3.Order
+	k.Iterator(ctx, func(ord types3.Order) bool {
+		orders = append(orders, ord)
+		return true
+	})
+	for _, ord := range orders {
+		k.index(ctx, ord)
+	}
+
+	var ver [8]byte
+	binary.BigEndian.PutUint64(ver[:], indexVersion)
+	kv.Set([]byte(indexVersionKey), ver[:])
+	return len(orders)
+}
+
+func (k Keeper) index(ctx sdk.Context, ord types3.Order) {
+	kv := ctx.KVStore(k.storeKey)
+	kv.Set(bookIndexKey(ord), ord.ID.Bytes())
+	kv.Set(expiryIndexKey(ord), ord.ID.Bytes())
+	kv.Set(ownerIndexKey(ord), ord.ID.Bytes())
+}
+
+func (k Keeper) unindex(ctx sdk.Context, ord types3.Order) {
+	kv := ctx.KVStore(k.storeKey)
+	kv.Delete(bookIndexKey(ord))
+	kv.Delete(expiryIndexKey(ord))
+	kv.Delete(ownerIndexKey(ord))
+}
+
+func bookPrefix(mktID store.EntityID, direction matcheng.Direction) []byte {
+	return store.PrefixKeyString(bookKey, mktID.Bytes(), []byte{byte(direction)})
+}
+
+func bookIndexKey(ord types3.Order) []byte {
+	return store.PrefixKeyBytes(bookPrefix(ord.MarketID, ord.Direction), priceSubkey(ord.Price), ord.ID.Bytes())
+}
+
+func expiryIndexKey(ord types3.Order) []byte {
+	expiry := ord.CreatedBlock + int64(ord.TimeInForceBlocks)
+	return store.PrefixKeyString(expiryKey, heightSubkey(expiry), ord.ID.Bytes())
+}
+
+func ownerPrefix(owner sdk.AccAddress) []byte {
+	return store.PrefixKeyString(ownerKey, owner.Bytes())
+}
+
+func ownerIndexKey(ord types3.Order) []byte {
+	return store.PrefixKeyBytes(ownerPrefix(ord.Owner), ord.MarketID.Bytes(), ord.ID.Bytes())
+}
+
+// heightSubkey encodes a block height as big-endian bytes so that the expiry
+// index sorts by height.
+func heightSubkey(height int64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(height))
+	return buf[:]
+}
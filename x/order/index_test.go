@@ -0,0 +1,77 @@
+package order
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/testutil"
+	"github.com/xar-network/xar-network/testutil/testflags"
+	"github.com/xar-network/xar-network/types/store"
+	types3 "github.com/xar-network/xar-network/x/order/types"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	cstore "github.com/cosmos/cosmos-sdk/store"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestKeeper_MigrateIndexes(t *testing.T) {
+	testflags.UnitTest(t)
+	key := sdk.NewKVStoreKey(types3.StoreKey)
+	db := dbm.NewMemDB()
+	ms := cstore.NewCommitMultiStore(db)
+	ms.MountStoreWithDB(key, sdk.StoreTypeIAVL, db)
+	require.NoError(t, ms.LoadLatestVersion())
+	ctx := sdk.NewContext(ms, abci.Header{ChainID: "unit-test-chain", Height: 10}, false, log.NewNopLogger())
+	k := Keeper{storeKey: key, cdc: codec.New()}
+
+	// written the way the module stored orders before they were indexed
+	mktID := store.NewEntityID(1)
+	ord := types3.Order{
+		ID:                store.NewEntityID(1),
+		Owner:             testutil.RandAddr(),
+		MarketID:          mktID,
+		Direction:         matcheng.Bid,
+		Price:             testutil.ToBaseUnits(2),
+		Quantity:          testutil.ToBaseUnits(10),
+		TimeInForceBlocks: 5,
+		CreatedBlock:      1,
+	}
+	require.NoError(t, store.SetNotExists(ctx, key, k.cdc, orderKey(ord.ID), ord))
+
+	collect := func(iter func(IteratorCB)) []store.EntityID {
+		var out []store.EntityID
+		iter(func(o types3.Order) bool {
+			out = append(out, o.ID)
+			return true
+		})
+		return out
+	}
+	book := func(cb IteratorCB) { k.BookIterator(ctx, mktID, matcheng.Bid, cb) }
+	expiring := func(cb IteratorCB) { k.ExpiringIterator(ctx, 10, cb) }
+	owned := func(cb IteratorCB) { k.OwnerIterator(ctx, ord.Owner, store.ZeroEntityID, cb) }
+	assert.Empty(t, collect(book))
+
+	assert.Equal(t, 1, k.MigrateIndexes(ctx))
+	assert.Equal(t, []store.EntityID{ord.ID}, collect(book))
+	assert.Equal(t, []store.EntityID{ord.ID}, collect(expiring))
+	assert.Equal(t, []store.EntityID{ord.ID}, collect(owned))
+
+	assert.Equal(t, 0, k.MigrateIndexes(ctx), "indexes are only built once")
+
+	// stores indexed before the owner index existed are indexed again
+	var ver [8]byte
+	binary.BigEndian.PutUint64(ver[:], 1)
+	ctx.KVStore(key).Set([]byte(indexVersionKey), ver[:])
+	ctx.KVStore(key).Delete(ownerIndexKey(ord))
+	assert.Empty(t, collect(owned))
+	assert.Equal(t, 1, k.MigrateIndexes(ctx))
+	assert.Equal(t, []store.EntityID{ord.ID}, collect(owned))
+}
@@ -1,10 +1,12 @@
 package order
 
 import (
+	"github.com/xar-network/xar-network/pkg/conv"
 	"github.com/xar-network/xar-network/pkg/matcheng"
 	"github.com/xar-network/xar-network/types"
 	"github.com/xar-network/xar-network/types/store"
 	"github.com/xar-network/xar-network/x/market"
+	types2 "github.com/xar-network/xar-network/x/market/types"
 	types3 "github.com/xar-network/xar-network/x/order/types"
 
 	"github.com/cosmos/cosmos-sdk/codec"
@@ -22,15 +24,17 @@ type IteratorCB func(order types3.Order) bool
 type Keeper struct {
 	sk           supply.Keeper
 	marketKeeper market.Keeper
+	oracleKeeper types3.OracleKeeper
 	storeKey     sdk.StoreKey
 	queue        types.Backend
 	cdc          *codec.Codec
 }
 
-func NewKeeper(sk supply.Keeper, mk market.Keeper, storeKey sdk.StoreKey, queue types.Backend, cdc *codec.Codec) Keeper {
+func NewKeeper(sk supply.Keeper, mk market.Keeper, ok types3.OracleKeeper, storeKey sdk.StoreKey, queue types.Backend, cdc *codec.Codec) Keeper {
 	return Keeper{
 		sk:           sk,
 		marketKeeper: mk,
+		oracleKeeper: ok,
 		storeKey:     storeKey,
 		queue:        queue,
 		cdc:          cdc,
@@ -38,42 +42,32 @@ func NewKeeper(sk supply.Keeper, mk market.Keeper, storeKey sdk.StoreKey, queue
 }
 
 func (k Keeper) Post(ctx sdk.Context, owner sdk.AccAddress, mktID store.EntityID, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint, tif uint16) (types3.Order, sdk.Error) {
-	var err sdk.Error
+	return k.PostWithType(ctx, owner, mktID, matcheng.Limit, direction, price, quantity, sdk.ZeroUint(), tif)
+}
+
+// PostWithType escrows the owner's funds and creates an order of the given
+// type. See effectivePrice for how MARKET orders are priced.
+func (k Keeper) PostWithType(ctx sdk.Context, owner sdk.AccAddress, mktID store.EntityID, orderType matcheng.OrderType, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint, maxSpend sdk.Uint, tif uint16) (types3.Order, sdk.Error) {
 	mkt, err := k.marketKeeper.Get(ctx, mktID)
 	if err != nil {
 		return types3.Order{}, err
 	}
-
-	// validateSufficientQuantity
-	// price - assumed to be the 8 decimal value integer
-
-	var postedAsset string
-	var postedAmt sdk.Uint
-	if direction == matcheng.Bid {
-
-		postedAsset = mkt.QuoteAssetDenom
-		p, err := matcheng.NormalizeQuoteQuantity(price, quantity)
-		if err != nil {
-			return types3.Order{}, sdk.ErrInvalidCoins(err.Error())
-		}
-		postedAmt = p
-	} else {
-		postedAsset = mkt.BaseAssetDenom
-		postedAmt = quantity
+	if err := checkMarketStatus(mkt, orderType); err != nil {
+		return types3.Order{}, err
 	}
+	price, err = effectivePrice(mkt, orderType, direction, price, quantity, maxSpend)
 	if err != nil {
-		// should never happen; implies consensus
-		// or storage bug
-		panic(err)
+		return types3.Order{}, err
 	}
-
-	amount, ok := sdk.NewIntFromString(postedAmt.String())
-	if !ok {
+	if err := checkTradingRules(mkt, orderType, direction, price, quantity); err != nil {
 		return types3.Order{}, err
 	}
+	if !orderType.Rests() {
+		// immediate orders never outlive the batch they are posted in
+		tif = 0
+	}
 
-	err = k.sk.SendCoinsFromAccountToModule(ctx, owner, ModuleName, sdk.NewCoins(sdk.NewCoin(postedAsset, amount)))
-	if err != nil {
+	if err := k.escrow(ctx, owner, mkt, direction, price, quantity); err != nil {
 		return types3.Order{}, err
 	}
 
@@ -81,6 +75,7 @@ func (k Keeper) Post(ctx sdk.Context, owner sdk.AccAddress, mktID store.EntityID
 		ctx,
 		owner,
 		mktID,
+		orderType,
 		direction,
 		price,
 		quantity,
@@ -88,7 +83,7 @@ func (k Keeper) Post(ctx sdk.Context, owner sdk.AccAddress, mktID store.EntityID
 	)
 }
 
-func (k Keeper) Create(ctx sdk.Context, owner sdk.AccAddress, marketID store.EntityID, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint, tif uint16) (types3.Order, sdk.Error) {
+func (k Keeper) Create(ctx sdk.Context, owner sdk.AccAddress, marketID store.EntityID, orderType matcheng.OrderType, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint, tif uint16) (types3.Order, sdk.Error) {
 	id := k.incrementSeq(ctx)
 	order := types3.Order{
 		ID:                id,
@@ -99,8 +94,12 @@ func (k Keeper) Create(ctx sdk.Context, owner sdk.AccAddress, marketID store.Ent
 		Quantity:          quantity,
 		TimeInForceBlocks: tif,
 		CreatedBlock:      ctx.BlockHeight(),
+		Type:              orderType,
 	}
 	err := store.SetNotExists(ctx, k.storeKey, k.cdc, orderKey(id), order)
+	if err == nil {
+		k.index(ctx, order)
+	}
 	_ = k.queue.Publish(types.OrderCreated{
 		ID:                order.ID,
 		Owner:             order.Owner,
@@ -110,6 +109,7 @@ func (k Keeper) Create(ctx sdk.Context, owner sdk.AccAddress, marketID store.Ent
 		Quantity:          order.Quantity,
 		TimeInForceBlocks: order.TimeInForceBlocks,
 		CreatedBlock:      order.CreatedBlock,
+		Type:              order.Type,
 	})
 
 	return order, err
@@ -128,41 +128,157 @@ func (k Keeper) Cancel(ctx sdk.Context, id store.EntityID) sdk.Error {
 		panic(err)
 	}
 
-	var postedAsset string
-	var postedAmt sdk.Uint
+	k.refund(ctx, ord.Owner, mkt, ord.Direction, ord.Price, ord.Quantity)
+	_ = k.queue.Publish(types.OrderCancelled{
+		OrderID:  id,
+		Owner:    ord.Owner,
+		MarketID: ord.MarketID,
+	})
+
+	return k.Del(ctx, ord.ID)
+}
+
+// CancelAll cancels every order belonging to owner, limited to a single
+// market when mktID is defined. It returns the cancelled orders.
+func (k Keeper) CancelAll(ctx sdk.Context, owner sdk.AccAddress, mktID store.EntityID) ([]types3.Order, sdk.Error) {
+	toCancel := make([]types3.Order, 0)
+	k.OwnerIterator(ctx, owner, mktID, func(ord types3.Order) bool {
+		toCancel = append(toCancel, ord)
+		return true
+	})
+	for _, ord := range toCancel {
+		if err := k.Cancel(ctx, ord.ID); err != nil {
+			return nil, err
+		}
+	}
+	return toCancel, nil
+}
+
+// Amend changes the price and unfilled quantity of an order in place.
+// The quantity can only be reduced. A changed price or quantity is held to the
+// market's tick size or lot size, and the amended order to its minimum
+// notional, as when posting. For bids the quote escrow is recomputed at the
+// new price, and the owner pays or is refunded the difference; for asks the
+// released base quantity is refunded.
+func (k Keeper) Amend(ctx sdk.Context, id store.EntityID, price sdk.Uint, quantity sdk.Uint) (types3.Order, sdk.Error) {
+	ord, err := k.Get(ctx, id)
+	if err != nil {
+		return types3.Order{}, err
+	}
+	if quantity.IsZero() || quantity.GT(ord.Quantity) {
+		return types3.Order{}, sdk.ErrInvalidCoins("quantity can only be reduced")
+	}
+	mkt, err := k.marketKeeper.Get(ctx, ord.MarketID)
+	if err != nil {
+		return types3.Order{}, err
+	}
+	if !mkt.Status.AcceptsOrders() {
+		return types3.Order{}, types2.ErrMarketClosed(types2.DefaultCodespace, mkt.Status)
+	}
+	if !price.Equal(ord.Price) {
+		if err := mkt.ValidatePrice(price); err != nil {
+			return types3.Order{}, err
+		}
+	}
+	if !quantity.Equal(ord.Quantity) {
+		if err := mkt.ValidateQuantity(quantity); err != nil {
+			return types3.Order{}, err
+		}
+	}
+	if ord.Type != matcheng.Market || ord.Direction == matcheng.Bid {
+		notional, _ := mkt.NormalizeQuoteQuantity(price, quantity)
+		if err := mkt.ValidateNotional(notional); err != nil {
+			return types3.Order{}, err
+		}
+	}
+
 	if ord.Direction == matcheng.Bid {
-		postedAsset = mkt.QuoteAssetDenom
-		p, err := matcheng.NormalizeQuoteQuantity(ord.Price, ord.Quantity)
-		if err != nil {
-			return sdk.ErrInvalidCoins(err.Error())
+		oldAmt, _ := mkt.NormalizeQuoteQuantity(ord.Price, ord.Quantity)
+		newAmt, qErr := mkt.NormalizeQuoteQuantity(price, quantity)
+		if qErr != nil {
+			return types3.Order{}, sdk.ErrInvalidCoins(qErr.Error())
 		}
-		postedAmt = p
-	} else {
-		postedAsset = mkt.BaseAssetDenom
-		postedAmt = ord.Quantity
+		if newAmt.GT(oldAmt) {
+			err = k.sk.SendCoinsFromAccountToModule(ctx, ord.Owner, ModuleName, uintCoins(mkt.QuoteAssetDenom, newAmt.Sub(oldAmt)))
+		} else if newAmt.LT(oldAmt) {
+			err = k.sk.SendCoinsFromModuleToAccount(ctx, ModuleName, ord.Owner, uintCoins(mkt.QuoteAssetDenom, oldAmt.Sub(newAmt)))
+		}
+	} else if quantity.LT(ord.Quantity) {
+		err = k.sk.SendCoinsFromModuleToAccount(ctx, ModuleName, ord.Owner, uintCoins(mkt.BaseAssetDenom, ord.Quantity.Sub(quantity)))
 	}
 	if err != nil {
-		// should never happen; implies consensus
-		// or storage bug
-		panic(err)
+		return types3.Order{}, err
 	}
 
-	amount, ok := sdk.NewIntFromString(postedAmt.String())
-	if !ok {
-		return err
+	ord.Price = price
+	ord.Quantity = quantity
+	if err := k.Set(ctx, ord); err != nil {
+		return types3.Order{}, err
 	}
+	_ = k.queue.Publish(types.OrderAmended{
+		OrderID:  ord.ID,
+		Price:    ord.Price,
+		Quantity: ord.Quantity,
+		Owner:    ord.Owner,
+		MarketID: ord.MarketID,
+	})
+	return ord, nil
+}
 
-	err = k.sk.SendCoinsFromModuleToAccount(ctx, ModuleName, ord.Owner, sdk.NewCoins(sdk.NewCoin(postedAsset, amount)))
+// Reduce lowers the quantity of an order and refunds the escrow released by
+// the difference. Unlike Amend it is not held to the market's rules, as it is
+// applied by the matching engine itself, which has already matched the order
+// at its new quantity. The order is only removed once nothing is left of it;
+// a bid whose remainder is worth less than a unit of the quote asset stays on
+// the book with no escrow left, as it would after a partial fill.
+func (k Keeper) Reduce(ctx sdk.Context, id store.EntityID, quantity sdk.Uint) sdk.Error {
+	ord, err := k.Get(ctx, id)
 	if err != nil {
-		// should never happen, implies consensus
+		return err
+	}
+	if quantity.GT(ord.Quantity) {
+		return sdk.ErrInvalidCoins("quantity can only be reduced")
+	}
+	if quantity.Equal(ord.Quantity) {
+		return nil
+	}
+	mkt, err := k.marketKeeper.Get(ctx, ord.MarketID)
+	if err != nil {
+		// should never happen; implies consensus
 		// or storage bug
 		panic(err)
 	}
-	_ = k.queue.Publish(types.OrderCancelled{
-		OrderID: id,
-	})
 
-	return k.Del(ctx, ord.ID)
+	if quantity.IsZero() {
+		return k.Cancel(ctx, id)
+	}
+
+	var released sdk.Coins
+	if ord.Direction == matcheng.Bid {
+		oldAmt, _ := mkt.NormalizeQuoteQuantity(ord.Price, ord.Quantity)
+		newAmt, _ := mkt.NormalizeQuoteQuantity(ord.Price, quantity)
+		released = uintCoins(mkt.QuoteAssetDenom, oldAmt.Sub(newAmt))
+	} else {
+		released = uintCoins(mkt.BaseAssetDenom, ord.Quantity.Sub(quantity))
+	}
+	if !released.IsZero() {
+		if err := k.sk.SendCoinsFromModuleToAccount(ctx, ModuleName, ord.Owner, released); err != nil {
+			return err
+		}
+	}
+
+	ord.Quantity = quantity
+	if err := k.Set(ctx, ord); err != nil {
+		return err
+	}
+	_ = k.queue.Publish(types.OrderAmended{
+		OrderID:  ord.ID,
+		Price:    ord.Price,
+		Quantity: ord.Quantity,
+		Owner:    ord.Owner,
+		MarketID: ord.MarketID,
+	})
+	return nil
 }
 
 func (k Keeper) Get(ctx sdk.Context, id store.EntityID) (types3.Order, sdk.Error) {
@@ -172,7 +288,16 @@ func (k Keeper) Get(ctx sdk.Context, id store.EntityID) (types3.Order, sdk.Error
 }
 
 func (k Keeper) Set(ctx sdk.Context, order types3.Order) sdk.Error {
-	return store.SetExists(ctx, k.storeKey, k.cdc, orderKey(order.ID), order)
+	old, err := k.Get(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if err := store.SetExists(ctx, k.storeKey, k.cdc, orderKey(order.ID), order); err != nil {
+		return err
+	}
+	k.unindex(ctx, old)
+	k.index(ctx, order)
+	return nil
 }
 
 func (k Keeper) Has(ctx sdk.Context, id store.EntityID) bool {
@@ -180,6 +305,11 @@ func (k Keeper) Has(ctx sdk.Context, id store.EntityID) bool {
 }
 
 func (k Keeper) Del(ctx sdk.Context, id store.EntityID) sdk.Error {
+	ord, err := k.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	k.unindex(ctx, ord)
 	return store.Del(ctx, k.storeKey, orderKey(id))
 }
 
@@ -212,6 +342,117 @@ func (k Keeper) doIterator(iter sdk.Iterator, cb IteratorCB) {
 	}
 }
 
+// effectivePrice returns the price an order of the given type is escrowed and
+// matched at. MARKET orders are stored with an effective limit price: a bid's
+// price is derived from maxSpend so that the escrow never exceeds it. An ask
+// escrows only its quantity, so it is stored at the smallest representable
+// unit; the batch auction matches it at the best bid instead, so that it
+// never sets the clearing price.
+func effectivePrice(mkt types2.Market, orderType matcheng.OrderType, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint, maxSpend sdk.Uint) (sdk.Uint, sdk.Error) {
+	if !orderType.IsValid() {
+		return sdk.Uint{}, sdk.ErrUnknownRequest("invalid order type")
+	}
+	if orderType != matcheng.Market {
+		return price, nil
+	}
+	if direction == matcheng.Ask {
+		return sdk.OneUint(), nil
+	}
+	p, err := mkt.PriceFromQuoteQuantity(maxSpend, quantity)
+	if err != nil {
+		return sdk.Uint{}, sdk.ErrInvalidCoins(err.Error())
+	}
+	return p, nil
+}
+
+// checkMarketStatus rejects orders on markets that are halted or delisted,
+// and orders that would not rest on markets that are post-only.
+func checkMarketStatus(mkt types2.Market, orderType matcheng.OrderType) sdk.Error {
+	if !mkt.Status.AcceptsOrders() {
+		return types2.ErrMarketClosed(types2.DefaultCodespace, mkt.Status)
+	}
+	if mkt.Status == types2.StatusPostOnly && !orderType.Rests() {
+		return sdk.ErrUnknownRequest("only limit orders can be posted to a post-only market")
+	}
+	return nil
+}
+
+// checkTradingRules enforces the market's tick size, lot size and minimum
+// notional. MARKET orders carry a derived price, so it is not held to the tick
+// size; market asks, whose worth is unknown until they clear, also skip the
+// minimum notional.
+func checkTradingRules(mkt types2.Market, orderType matcheng.OrderType, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint) sdk.Error {
+	if err := mkt.ValidateQuantity(quantity); err != nil {
+		return err
+	}
+	if orderType != matcheng.Market {
+		if err := mkt.ValidatePrice(price); err != nil {
+			return err
+		}
+	} else if direction == matcheng.Ask {
+		return nil
+	}
+	notional, _ := mkt.NormalizeQuoteQuantity(price, quantity)
+	return mkt.ValidateNotional(notional)
+}
+
+// escrow moves the funds backing an order from its owner into the module
+// account: quote asset for bids, base asset for asks.
+func (k Keeper) escrow(ctx sdk.Context, owner sdk.AccAddress, mkt types2.Market, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint) sdk.Error {
+	// price - assumed to be the 8 decimal value integer
+	var postedAsset string
+	var postedAmt sdk.Uint
+	if direction == matcheng.Bid {
+		postedAsset = mkt.QuoteAssetDenom
+		p, err := mkt.NormalizeQuoteQuantity(price, quantity)
+		if err != nil {
+			return sdk.ErrInvalidCoins(err.Error())
+		}
+		postedAmt = p
+	} else {
+		postedAsset = mkt.BaseAssetDenom
+		postedAmt = quantity
+	}
+
+	amount, ok := sdk.NewIntFromString(postedAmt.String())
+	if !ok {
+		return sdk.ErrInvalidCoins("invalid escrow amount")
+	}
+
+	return k.sk.SendCoinsFromAccountToModule(ctx, owner, ModuleName, sdk.NewCoins(sdk.NewCoin(postedAsset, amount)))
+}
+
+// refund returns the escrow still backing an order to its owner.
+func (k Keeper) refund(ctx sdk.Context, owner sdk.AccAddress, mkt types2.Market, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint) {
+	var postedAsset string
+	var postedAmt sdk.Uint
+	if direction == matcheng.Bid {
+		postedAsset = mkt.QuoteAssetDenom
+		// a partially filled bid can leave a remainder whose escrow
+		// truncates to zero; there is nothing left to refund in that case
+		postedAmt, _ = mkt.NormalizeQuoteQuantity(price, quantity)
+	} else {
+		postedAsset = mkt.BaseAssetDenom
+		postedAmt = quantity
+	}
+
+	amount, ok := sdk.NewIntFromString(postedAmt.String())
+	if !ok || !amount.IsPositive() {
+		return
+	}
+
+	err := k.sk.SendCoinsFromModuleToAccount(ctx, ModuleName, owner, sdk.NewCoins(sdk.NewCoin(postedAsset, amount)))
+	if err != nil {
+		// should never happen, implies consensus
+		// or storage bug
+		panic(err)
+	}
+}
+
+func uintCoins(denom string, amount sdk.Uint) sdk.Coins {
+	return sdk.NewCoins(sdk.NewCoin(denom, sdk.NewIntFromBigInt(conv.SDKUint2Big(amount))))
+}
+
 func orderKey(id store.EntityID) []byte {
 	return store.PrefixKeyString(valKey, id.Bytes())
 }
@@ -24,6 +24,7 @@ type testCtx struct {
 	ctx      sdk.Context
 	marketID store.EntityID
 	owner    sdk.AccAddress
+	nominee  sdk.AccAddress
 	buyer    sdk.AccAddress
 	seller   sdk.AccAddress
 	app      *mockapp.MockApp
@@ -75,6 +76,106 @@ func TestKeeper_Post(t *testing.T) {
 	})
 }
 
+func TestKeeper_PostWithType(t *testing.T) {
+	testflags.UnitTest(t)
+	t.Run("escrows at most max spend for a market bid", func(t *testing.T) {
+		ctx := setupTest(t)
+		created, err := ctx.app.OrderKeeper.PostWithType(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Market, matcheng.Bid, sdk.ZeroUint(), testutil.ToBaseUnits(10), testutil.ToBaseUnits(25), 0)
+		require.NoError(t, err)
+		assert.Equal(t, matcheng.Market, created.Type)
+		testutil.AssertEqualUints(t, sdk.NewUint(250000000), created.Price)
+		bal := sdk.NewUintFromBigInt(ctx.app.BankKeeper.GetCoins(ctx.ctx, ctx.buyer).AmountOf(ctx.asset2).BigInt())
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(75), bal)
+	})
+	t.Run("returns an error if max spend cannot buy a single unit", func(t *testing.T) {
+		ctx := setupTest(t)
+		_, err := ctx.app.OrderKeeper.PostWithType(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Market, matcheng.Bid, sdk.ZeroUint(), testutil.ToBaseUnits(10), sdk.NewUint(1), 0)
+		assert.Error(t, err)
+		assert.Equal(t, err.Code(), sdk.CodeInvalidCoins)
+	})
+	t.Run("does not keep a time in force for immediate orders", func(t *testing.T) {
+		ctx := setupTest(t)
+		created, err := ctx.app.OrderKeeper.PostWithType(ctx.ctx, ctx.seller, ctx.marketID, matcheng.ImmediateOrCancel, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), sdk.ZeroUint(), 599)
+		require.NoError(t, err)
+		assert.Equal(t, matcheng.ImmediateOrCancel, created.Type)
+		assert.EqualValues(t, 0, created.TimeInForceBlocks)
+	})
+	t.Run("returns an error for an unknown order type", func(t *testing.T) {
+		ctx := setupTest(t)
+		_, err := ctx.app.OrderKeeper.PostWithType(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.OrderType(99), matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), sdk.ZeroUint(), 599)
+		assert.Error(t, err)
+	})
+}
+
+func TestKeeper_PostTradingRules(t *testing.T) {
+	testflags.UnitTest(t)
+	setup := func(t *testing.T) *testCtx {
+		ctx := setupTest(t)
+		_, err := ctx.app.MarketKeeper.UpdateMarket(ctx.ctx, ctx.nominee.String(), ctx.marketID, 1000000, 10000000, testutil.ToBaseUnits(5).Uint64())
+		require.NoError(t, err)
+		return ctx
+	}
+
+	t.Run("rejects a price off the tick size", func(t *testing.T) {
+		ctx := setup(t)
+		_, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2).AddUint64(1), testutil.ToBaseUnits(10), 599)
+		assert.Error(t, err)
+		assert.Equal(t, err.Code(), sdk.CodeInvalidCoins)
+	})
+	t.Run("rejects a quantity off the lot size", func(t *testing.T) {
+		ctx := setup(t)
+		_, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.seller, ctx.marketID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10).AddUint64(1), 599)
+		assert.Error(t, err)
+		assert.Equal(t, err.Code(), sdk.CodeInvalidCoins)
+	})
+	t.Run("rejects an order worth less than the minimum notional", func(t *testing.T) {
+		ctx := setup(t)
+		_, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(2), 599)
+		assert.Error(t, err)
+		assert.Equal(t, err.Code(), sdk.CodeInvalidCoins)
+	})
+	t.Run("accepts an order that meets every rule", func(t *testing.T) {
+		ctx := setup(t)
+		_, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(3), 599)
+		require.NoError(t, err)
+	})
+	t.Run("does not hold market orders to the tick size", func(t *testing.T) {
+		ctx := setup(t)
+		_, err := ctx.app.OrderKeeper.PostWithType(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Market, matcheng.Bid, sdk.ZeroUint(), testutil.ToBaseUnits(3), testutil.ToBaseUnits(7), 0)
+		require.NoError(t, err)
+	})
+}
+
+func TestKeeper_PostMarketStatus(t *testing.T) {
+	testflags.UnitTest(t)
+	setup := func(t *testing.T, status types2.Status) *testCtx {
+		ctx := setupTest(t)
+		_, err := ctx.app.MarketKeeper.SetStatus(ctx.ctx, ctx.nominee.String(), ctx.marketID, status)
+		require.NoError(t, err)
+		return ctx
+	}
+
+	t.Run("rejects orders on a halted market", func(t *testing.T) {
+		ctx := setup(t, types2.StatusHalted)
+		_, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+		assert.Error(t, err)
+		assert.Equal(t, types2.CodeMarketClosed, err.Code())
+	})
+	t.Run("rejects orders on a delisted market", func(t *testing.T) {
+		ctx := setup(t, types2.StatusDelisted)
+		_, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+		assert.Error(t, err)
+		assert.Equal(t, types2.CodeMarketClosed, err.Code())
+	})
+	t.Run("accepts only limit orders on a post-only market", func(t *testing.T) {
+		ctx := setup(t, types2.StatusPostOnly)
+		_, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+		require.NoError(t, err)
+		_, err = ctx.app.OrderKeeper.PostWithType(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.ImmediateOrCancel, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), sdk.ZeroUint(), 0)
+		assert.Error(t, err)
+	})
+}
+
 func TestKeeper_Cancel(t *testing.T) {
 	testflags.UnitTest(t)
 	t.Run("returns an error for a nonexistent order", func(t *testing.T) {
@@ -93,6 +194,108 @@ func TestKeeper_Cancel(t *testing.T) {
 	})
 }
 
+func TestKeeper_CancelAll(t *testing.T) {
+	testflags.UnitTest(t)
+	ctx := setupTest(t)
+	other, err := ctx.app.MarketKeeper.CreateMarket(ctx.ctx, ctx.app.MarketKeeper.GetParams(ctx.ctx).Nominees[0], ctx.asset2, ctx.asset1)
+	require.NoError(t, err)
+	first, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+	require.NoError(t, err)
+	second, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, other.ID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+	require.NoError(t, err)
+	third, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+	require.NoError(t, err)
+	foreign, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.seller, ctx.marketID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+	require.NoError(t, err)
+
+	ids := func(orders []types4.Order) []store.EntityID {
+		var out []store.EntityID
+		for _, ord := range orders {
+			out = append(out, ord.ID)
+		}
+		return out
+	}
+
+	cancelled, err := ctx.app.OrderKeeper.CancelAll(ctx.ctx, ctx.buyer, ctx.marketID)
+	require.NoError(t, err)
+	assert.EqualValues(t, []store.EntityID{first.ID, third.ID}, ids(cancelled))
+	assert.True(t, ctx.app.OrderKeeper.Has(ctx.ctx, second.ID))
+	assert.True(t, ctx.app.OrderKeeper.Has(ctx.ctx, foreign.ID))
+
+	cancelled, err = ctx.app.OrderKeeper.CancelAll(ctx.ctx, ctx.buyer, store.ZeroEntityID)
+	require.NoError(t, err)
+	assert.EqualValues(t, []store.EntityID{second.ID}, ids(cancelled))
+	assert.True(t, cancelled[0].MarketID.Equals(other.ID))
+	assert.True(t, ctx.app.OrderKeeper.Has(ctx.ctx, foreign.ID))
+	bal := sdk.NewUintFromBigInt(ctx.app.BankKeeper.GetCoins(ctx.ctx, ctx.buyer).AmountOf(ctx.asset2).BigInt())
+	testutil.AssertEqualUints(t, testutil.ToBaseUnits(100), bal)
+}
+
+func TestKeeper_Amend(t *testing.T) {
+	testflags.UnitTest(t)
+	quoteBal := func(ctx *testCtx, addr sdk.AccAddress) sdk.Uint {
+		return sdk.NewUintFromBigInt(ctx.app.BankKeeper.GetCoins(ctx.ctx, addr).AmountOf(ctx.asset2).BigInt())
+	}
+	t.Run("returns an error for a nonexistent order", func(t *testing.T) {
+		ctx := setupTest(t)
+		_, err := ctx.app.OrderKeeper.Amend(ctx.ctx, store.NewEntityID(1), testutil.ToBaseUnits(2), testutil.ToBaseUnits(10))
+		assert.Error(t, err)
+		assert.Equal(t, err.Code(), errs.CodeNotFound)
+	})
+	t.Run("returns an error when increasing quantity", func(t *testing.T) {
+		ctx := setupTest(t)
+		bid, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+		require.NoError(t, err)
+		_, err = ctx.app.OrderKeeper.Amend(ctx.ctx, bid.ID, testutil.ToBaseUnits(2), testutil.ToBaseUnits(11))
+		assert.Error(t, err)
+		assert.Equal(t, err.Code(), sdk.CodeInvalidCoins)
+	})
+	t.Run("re-escrows the difference when repricing a bid", func(t *testing.T) {
+		ctx := setupTest(t)
+		bid, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(80), quoteBal(ctx, ctx.buyer))
+
+		amended, err := ctx.app.OrderKeeper.Amend(ctx.ctx, bid.ID, testutil.ToBaseUnits(3), testutil.ToBaseUnits(10))
+		require.NoError(t, err)
+		assert.True(t, amended.ID.Equals(bid.ID))
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(70), quoteBal(ctx, ctx.buyer))
+
+		_, err = ctx.app.OrderKeeper.Amend(ctx.ctx, bid.ID, testutil.ToBaseUnits(1), testutil.ToBaseUnits(5))
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(95), quoteBal(ctx, ctx.buyer))
+		retrieved, err := ctx.app.OrderKeeper.Get(ctx.ctx, bid.ID)
+		require.NoError(t, err)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(1), retrieved.Price)
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(5), retrieved.Quantity)
+	})
+	t.Run("refunds the released quantity of an ask", func(t *testing.T) {
+		ctx := setupTest(t)
+		ask, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.seller, ctx.marketID, matcheng.Ask, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+		require.NoError(t, err)
+		_, err = ctx.app.OrderKeeper.Amend(ctx.ctx, ask.ID, testutil.ToBaseUnits(4), testutil.ToBaseUnits(4))
+		require.NoError(t, err)
+		bal := sdk.NewUintFromBigInt(ctx.app.BankKeeper.GetCoins(ctx.ctx, ctx.seller).AmountOf(ctx.asset1).BigInt())
+		testutil.AssertEqualUints(t, testutil.ToBaseUnits(96), bal)
+	})
+	t.Run("holds the amended order to the market's trading rules", func(t *testing.T) {
+		ctx := setupTest(t)
+		bid, err := ctx.app.OrderKeeper.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 599)
+		require.NoError(t, err)
+		params := ctx.app.MarketKeeper.GetParams(ctx.ctx)
+		params.Markets[ctx.marketID.Dec().Uint64()].LotSize = testutil.ToBaseUnits(1).Uint64()
+		params.Markets[ctx.marketID.Dec().Uint64()].MinNotional = testutil.ToBaseUnits(5).Uint64()
+		ctx.app.MarketKeeper.SetParams(ctx.ctx, params)
+
+		_, err = ctx.app.OrderKeeper.Amend(ctx.ctx, bid.ID, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10).SubUint64(1))
+		assert.Error(t, err)
+		_, err = ctx.app.OrderKeeper.Amend(ctx.ctx, bid.ID, testutil.ToBaseUnits(2), testutil.ToBaseUnits(2))
+		assert.Error(t, err)
+		_, err = ctx.app.OrderKeeper.Amend(ctx.ctx, bid.ID, testutil.ToBaseUnits(2), testutil.ToBaseUnits(3))
+		assert.NoError(t, err)
+	})
+}
+
 func TestKeeper_Iteration(t *testing.T) {
 	testflags.UnitTest(t)
 	ctx := setupTest(t)
@@ -124,6 +327,58 @@ func TestKeeper_Iteration(t *testing.T) {
 	assert.EqualValues(t, []store.EntityID{store.NewEntityID(3), store.NewEntityID(2)}, coll)
 }
 
+func TestKeeper_Indexes(t *testing.T) {
+	testflags.UnitTest(t)
+	ctx := setupTest(t)
+	k := ctx.app.OrderKeeper
+	dear, err := k.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(3), testutil.ToBaseUnits(10), 10)
+	require.NoError(t, err)
+	cheap, err := k.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(1), testutil.ToBaseUnits(10), 5)
+	require.NoError(t, err)
+	mid, err := k.Post(ctx.ctx, ctx.buyer, ctx.marketID, matcheng.Bid, testutil.ToBaseUnits(2), testutil.ToBaseUnits(10), 20)
+	require.NoError(t, err)
+	ask, err := k.Post(ctx.ctx, ctx.seller, ctx.marketID, matcheng.Ask, testutil.ToBaseUnits(4), testutil.ToBaseUnits(10), 1)
+	require.NoError(t, err)
+
+	book := func(dir matcheng.Direction) []store.EntityID {
+		var out []store.EntityID
+		k.BookIterator(ctx.ctx, ctx.marketID, dir, func(ord types4.Order) bool {
+			out = append(out, ord.ID)
+			return true
+		})
+		return out
+	}
+	expiring := func(height int64) []store.EntityID {
+		var out []store.EntityID
+		k.ExpiringIterator(ctx.ctx, height, func(ord types4.Order) bool {
+			out = append(out, ord.ID)
+			return true
+		})
+		return out
+	}
+
+	t.Run("sorts each side of the book by price", func(t *testing.T) {
+		assert.EqualValues(t, []store.EntityID{cheap.ID, mid.ID, dear.ID}, book(matcheng.Bid))
+		assert.EqualValues(t, []store.EntityID{ask.ID}, book(matcheng.Ask))
+	})
+	t.Run("sorts orders by expiry", func(t *testing.T) {
+		height := ctx.ctx.BlockHeight()
+		assert.Empty(t, expiring(height+1))
+		assert.EqualValues(t, []store.EntityID{ask.ID, cheap.ID}, expiring(height+6))
+		assert.EqualValues(t, []store.EntityID{ask.ID, cheap.ID, dear.ID, mid.ID}, expiring(height+21))
+	})
+	t.Run("moves amended orders", func(t *testing.T) {
+		_, err := k.Amend(ctx.ctx, dear.ID, testutil.ToBaseUnits(1), testutil.ToBaseUnits(10))
+		require.NoError(t, err)
+		assert.EqualValues(t, []store.EntityID{dear.ID, cheap.ID, mid.ID}, book(matcheng.Bid))
+	})
+	t.Run("drops cancelled orders", func(t *testing.T) {
+		require.NoError(t, k.Cancel(ctx.ctx, cheap.ID))
+		assert.EqualValues(t, []store.EntityID{dear.ID, mid.ID}, book(matcheng.Bid))
+		assert.EqualValues(t, []store.EntityID{ask.ID, dear.ID, mid.ID}, expiring(ctx.ctx.BlockHeight()+21))
+	})
+}
+
 func setupTest(t *testing.T) *testCtx {
 	app := mockapp.New(t)
 	nominee := testutil.RandAddr()
@@ -150,6 +405,7 @@ func setupTest(t *testing.T) *testCtx {
 	return &testCtx{
 		ctx:      app.Ctx,
 		marketID: mkt.ID,
+		nominee:  nominee,
 		buyer:    buyer,
 		seller:   seller,
 		app:      app,
@@ -90,7 +90,12 @@ func (a AppModule) NewQuerierHandler() types.Querier {
 	return NewQuerier(a.keeper)
 }
 
-func (a AppModule) BeginBlock(types.Context, abci.RequestBeginBlock) {
+// BeginBlock indexes orders left in the store by a version of the module that
+// did not index them, before any of them can be matched.
+func (a AppModule) BeginBlock(ctx types.Context, _ abci.RequestBeginBlock) {
+	if count := a.keeper.MigrateIndexes(ctx); count > 0 {
+		logger.Info("reindexed orders", "count", count)
+	}
 }
 
 func (a AppModule) EndBlock(types.Context, abci.RequestEndBlock) []abci.ValidatorUpdate {
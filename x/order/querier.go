@@ -6,11 +6,16 @@ import (
 	"github.com/cosmos/cosmos-sdk/codec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/types/errs"
+	"github.com/xar-network/xar-network/types/store"
 	"github.com/xar-network/xar-network/x/order/types"
 )
 
 const (
-	QueryList = "list"
+	QueryList            = "list"
+	QueryListConditional = "conditional"
+	QueryBook            = "book"
 )
 
 func NewQuerier(keeper Keeper) sdk.Querier {
@@ -18,6 +23,10 @@ func NewQuerier(keeper Keeper) sdk.Querier {
 		switch path[0] {
 		case QueryList:
 			return queryList(ctx, keeper)
+		case QueryListConditional:
+			return queryListConditional(ctx, keeper)
+		case QueryBook:
+			return queryBook(ctx, path[1:], keeper)
 		default:
 			return nil, sdk.ErrUnknownRequest("unknown order query endpoint")
 		}
@@ -40,3 +49,48 @@ func queryList(ctx sdk.Context, keeper Keeper) ([]byte, sdk.Error) {
 	}
 	return b, nil
 }
+
+func queryListConditional(ctx sdk.Context, keeper Keeper) ([]byte, sdk.Error) {
+	res := types.ListConditionalQueryResult{
+		Orders: make([]types.ConditionalOrder, 0),
+	}
+
+	keeper.ConditionalIterator(ctx, func(cond types.ConditionalOrder) bool {
+		res.Orders = append(res.Orders, cond)
+		return true
+	})
+
+	b, err := codec.MarshalJSONIndent(keeper.cdc, res)
+	if err != nil {
+		panic("could not marshal result")
+	}
+	return b, nil
+}
+
+func queryBook(ctx sdk.Context, path []string, keeper Keeper) ([]byte, sdk.Error) {
+	if len(path) != 1 {
+		return nil, errs.ErrInvalidArgument("must specify a market ID")
+	}
+
+	mktID := store.NewEntityIDFromString(path[0])
+	res := types.BookQueryResult{
+		MarketID:    mktID,
+		BlockNumber: ctx.BlockHeight(),
+		Bids:        make([]types.Order, 0),
+		Asks:        make([]types.Order, 0),
+	}
+	keeper.BookIterator(ctx, mktID, matcheng.Bid, func(order types.Order) bool {
+		res.Bids = append(res.Bids, order)
+		return true
+	})
+	keeper.BookIterator(ctx, mktID, matcheng.Ask, func(order types.Order) bool {
+		res.Asks = append(res.Asks, order)
+		return true
+	})
+
+	b, err := codec.MarshalJSONIndent(keeper.cdc, res)
+	if err != nil {
+		panic("could not marshal result")
+	}
+	return b, nil
+}
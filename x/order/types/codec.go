@@ -7,4 +7,10 @@ var ModuleCdc = codec.New()
 func RegisterCodec(cdc *codec.Codec) {
 	cdc.RegisterConcrete(MsgPost{}, "order/Post", nil)
 	cdc.RegisterConcrete(MsgCancel{}, "order/Cancel", nil)
+	cdc.RegisterConcrete(MsgCancelAll{}, "order/CancelAll", nil)
+	cdc.RegisterConcrete(MsgBatchPost{}, "order/BatchPost", nil)
+	cdc.RegisterConcrete(MsgBatchCancel{}, "order/BatchCancel", nil)
+	cdc.RegisterConcrete(MsgAmend{}, "order/Amend", nil)
+	cdc.RegisterConcrete(MsgPostConditional{}, "order/PostConditional", nil)
+	cdc.RegisterConcrete(MsgCancelConditional{}, "order/CancelConditional", nil)
 }
@@ -0,0 +1,155 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/types/store"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+const (
+	StopLoss TriggerKind = iota
+	TakeProfit
+)
+
+type TriggerKind uint8
+
+var triggerKindNames = map[TriggerKind]string{
+	StopLoss:   "STOP_LOSS",
+	TakeProfit: "TAKE_PROFIT",
+}
+
+func NewTriggerKindFromString(str string) (TriggerKind, error) {
+	for k, name := range triggerKindNames {
+		if name == str {
+			return k, nil
+		}
+	}
+	return StopLoss, errors.New("invalid trigger kind")
+}
+
+func (k TriggerKind) IsValid() bool {
+	_, ok := triggerKindNames[k]
+	return ok
+}
+
+func (k TriggerKind) String() string {
+	name, ok := triggerKindNames[k]
+	if !ok {
+		return "UNKNOWN"
+	}
+	return name
+}
+
+func (k *TriggerKind) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+
+	out, err := NewTriggerKindFromString(str)
+	if err != nil {
+		return err
+	}
+
+	*k = out
+	return nil
+}
+
+func (k TriggerKind) MarshalJSON() ([]byte, error) {
+	return []byte("\"" + k.String() + "\""), nil
+}
+
+// TriggerSource is the price a conditional order is triggered against: the
+// last clearing price of its market, or the price its oracle feed reports.
+type TriggerSource uint8
+
+const (
+	LastPrice TriggerSource = iota
+	OraclePrice
+)
+
+var triggerSourceNames = map[TriggerSource]string{
+	LastPrice:   "LAST_PRICE",
+	OraclePrice: "ORACLE_PRICE",
+}
+
+func NewTriggerSourceFromString(str string) (TriggerSource, error) {
+	for s, name := range triggerSourceNames {
+		if name == str {
+			return s, nil
+		}
+	}
+	return LastPrice, errors.New("invalid trigger source")
+}
+
+func (s TriggerSource) IsValid() bool {
+	_, ok := triggerSourceNames[s]
+	return ok
+}
+
+func (s TriggerSource) String() string {
+	name, ok := triggerSourceNames[s]
+	if !ok {
+		return "UNKNOWN"
+	}
+	return name
+}
+
+func (s *TriggerSource) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+
+	out, err := NewTriggerSourceFromString(str)
+	if err != nil {
+		return err
+	}
+
+	*s = out
+	return nil
+}
+
+func (s TriggerSource) MarshalJSON() ([]byte, error) {
+	return []byte("\"" + s.String() + "\""), nil
+}
+
+// ConditionalOrder is an order that stays dormant, with its funds already
+// escrowed, until the price given by Source crosses TriggerPrice. It is then
+// converted into a live Order with the same type, direction, price and
+// quantity.
+type ConditionalOrder struct {
+	ID                store.EntityID     `json:"id"`
+	Owner             sdk.AccAddress     `json:"owner"`
+	MarketID          store.EntityID     `json:"market"`
+	Direction         matcheng.Direction `json:"direction"`
+	Kind              TriggerKind        `json:"kind"`
+	TriggerPrice      sdk.Uint           `json:"trigger_price"`
+	Type              matcheng.OrderType `json:"type"`
+	Price             sdk.Uint           `json:"price"`
+	Quantity          sdk.Uint           `json:"quantity"`
+	TimeInForceBlocks uint16             `json:"time_in_force_blocks"`
+	CreatedBlock      int64              `json:"created_block"`
+	Source            TriggerSource      `json:"trigger_source"`
+}
+
+// TriggersAbove reports whether the order fires once the clearing price rises
+// to the trigger price, as opposed to falling to it. A stop-loss sell and a
+// take-profit buy fire on the way down; the other two fire on the way up.
+func (c ConditionalOrder) TriggersAbove() bool {
+	return (c.Kind == StopLoss) == (c.Direction == matcheng.Bid)
+}
+
+// IsTriggeredBy reports whether a clearing price crosses the trigger price.
+func (c ConditionalOrder) IsTriggeredBy(price sdk.Uint) bool {
+	if c.TriggersAbove() {
+		return price.GTE(c.TriggerPrice)
+	}
+	return price.LTE(c.TriggerPrice)
+}
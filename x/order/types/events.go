@@ -0,0 +1,16 @@
+package types
+
+// order module event types
+const (
+	EventTypePostOrder              = "post_order"
+	EventTypeCancelOrder            = "cancel_order"
+	EventTypeAmendOrder             = "amend_order"
+	EventTypePostConditionalOrder   = "post_conditional_order"
+	EventTypeCancelConditionalOrder = "cancel_conditional_order"
+
+	AttributeValueCategory = ModuleName
+
+	AttributeKeyOrderID       = "order_id"
+	AttributeKeyMarketID      = "market_id"
+	AttributeKeyConditionalID = "conditional_id"
+)
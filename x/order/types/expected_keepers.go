@@ -0,0 +1,14 @@
+package types
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/xar-network/xar-network/x/oracle"
+)
+
+// OracleKeeper provides the oracle prices conditional orders can be
+// triggered against.
+type OracleKeeper interface {
+	GetAssetParams(sdk.Context) oracle.Assets
+	GetCurrentPrice(sdk.Context, string) oracle.CurrentPrice
+}
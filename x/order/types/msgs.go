@@ -1,6 +1,8 @@
 package types
 
 import (
+	"fmt"
+
 	"github.com/xar-network/xar-network/pkg/matcheng"
 	"github.com/xar-network/xar-network/pkg/serde"
 	"github.com/xar-network/xar-network/types/store"
@@ -8,6 +10,10 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 )
 
+// MsgPost posts a new order. Price is the limit price for LIMIT, IOC and FOK
+// orders and must be zero for MARKET orders. MaxSpend is the most quote asset
+// a MARKET bid may spend and is escrowed in place of price * quantity; it must
+// be omitted for every other order.
 type MsgPost struct {
 	Owner       sdk.AccAddress     `json:"owner" yaml:"owner"`
 	MarketID    store.EntityID     `json:"market_id" yaml:"market_id"`
@@ -15,9 +21,15 @@ type MsgPost struct {
 	Price       sdk.Uint           `json:"price" yaml:"price"`
 	Quantity    sdk.Uint           `json:"quantity" yaml:"quantity"`
 	TimeInForce uint16             `json:"time_in_force" yaml:"time_in_force"`
+	OrderType   matcheng.OrderType `json:"order_type,omitempty" yaml:"order_type"`
+	MaxSpend    sdk.Uint           `json:"max_spend" yaml:"max_spend"`
 }
 
 func NewMsgPost(owner sdk.AccAddress, marketID store.EntityID, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint, tif uint16) MsgPost {
+	return NewMsgPostWithType(owner, marketID, matcheng.Limit, direction, price, quantity, sdk.ZeroUint(), tif)
+}
+
+func NewMsgPostWithType(owner sdk.AccAddress, marketID store.EntityID, orderType matcheng.OrderType, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint, maxSpend sdk.Uint, tif uint16) MsgPost {
 	return MsgPost{
 		Owner:       owner,
 		MarketID:    marketID,
@@ -25,6 +37,8 @@ func NewMsgPost(owner sdk.AccAddress, marketID store.EntityID, direction matchen
 		Price:       price,
 		Quantity:    quantity,
 		TimeInForce: tif,
+		OrderType:   orderType,
+		MaxSpend:    maxSpend,
 	}
 }
 
@@ -40,13 +54,31 @@ func (msg MsgPost) ValidateBasic() sdk.Error {
 	if !msg.MarketID.IsDefined() {
 		return sdk.ErrUnauthorized("invalid market ID")
 	}
-	if msg.Price.IsZero() {
-		return sdk.ErrInvalidCoins("price cannot be zero")
+	if !msg.OrderType.IsValid() {
+		return sdk.ErrUnknownRequest("invalid order type")
 	}
 	if msg.Quantity.IsZero() {
 		return sdk.ErrInvalidCoins("quantity cannot be zero")
 	}
-	if msg.TimeInForce == 0 {
+	if msg.OrderType == matcheng.Market {
+		if !msg.Price.IsZero() {
+			return sdk.ErrInvalidCoins("market orders cannot specify a price")
+		}
+		if msg.Direction == matcheng.Bid && isZeroOrUnset(msg.MaxSpend) {
+			return sdk.ErrInvalidCoins("market bids must specify a max spend")
+		}
+		if msg.Direction == matcheng.Ask && !isZeroOrUnset(msg.MaxSpend) {
+			return sdk.ErrInvalidCoins("market asks cannot specify a max spend")
+		}
+	} else {
+		if msg.Price.IsZero() {
+			return sdk.ErrInvalidCoins("price cannot be zero")
+		}
+		if !isZeroOrUnset(msg.MaxSpend) {
+			return sdk.ErrInvalidCoins("only market bids can specify a max spend")
+		}
+	}
+	if msg.OrderType == matcheng.Limit && msg.TimeInForce == 0 {
 		return sdk.ErrInternal("time in force cannot be zero")
 	}
 	if msg.TimeInForce > MaxTimeInForce {
@@ -100,3 +132,327 @@ func (msg MsgCancel) GetSignBytes() []byte {
 func (msg MsgCancel) GetSigners() []sdk.AccAddress {
 	return []sdk.AccAddress{msg.Owner}
 }
+
+// MaxBatchSize is the largest number of orders a single batch message may
+// post or cancel.
+const MaxBatchSize = 100
+
+// MsgCancelAll cancels every order the owner has resting, or only those in
+// MarketID when it is set.
+type MsgCancelAll struct {
+	Owner    sdk.AccAddress `json:"owner" yaml:"owner"`
+	MarketID store.EntityID `json:"market_id" yaml:"market_id"`
+}
+
+func NewMsgCancelAll(owner sdk.AccAddress, marketID store.EntityID) MsgCancelAll {
+	return MsgCancelAll{
+		Owner:    owner,
+		MarketID: marketID,
+	}
+}
+
+func (msg MsgCancelAll) Route() string {
+	return "order"
+}
+
+func (msg MsgCancelAll) Type() string {
+	return "cancel_all"
+}
+
+func (msg MsgCancelAll) ValidateBasic() sdk.Error {
+	if msg.Owner.Empty() {
+		return sdk.ErrUnauthorized("owner cannot be empty")
+	}
+	return nil
+}
+
+func (msg MsgCancelAll) GetSignBytes() []byte {
+	return serde.MustMarshalSortedJSON(msg)
+}
+
+func (msg MsgCancelAll) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// PostRequest is a single order within a MsgBatchPost. Its fields mean the
+// same as in MsgPost.
+type PostRequest struct {
+	MarketID    store.EntityID     `json:"market_id" yaml:"market_id"`
+	Direction   matcheng.Direction `json:"direction" yaml:"direction"`
+	Price       sdk.Uint           `json:"price" yaml:"price"`
+	Quantity    sdk.Uint           `json:"quantity" yaml:"quantity"`
+	TimeInForce uint16             `json:"time_in_force" yaml:"time_in_force"`
+	OrderType   matcheng.OrderType `json:"order_type,omitempty" yaml:"order_type"`
+	MaxSpend    sdk.Uint           `json:"max_spend" yaml:"max_spend"`
+}
+
+// MsgBatchPost posts several orders atomically: if any of them fails, none
+// are posted.
+type MsgBatchPost struct {
+	Owner  sdk.AccAddress `json:"owner" yaml:"owner"`
+	Orders []PostRequest  `json:"orders" yaml:"orders"`
+}
+
+func NewMsgBatchPost(owner sdk.AccAddress, orders []PostRequest) MsgBatchPost {
+	return MsgBatchPost{
+		Owner:  owner,
+		Orders: orders,
+	}
+}
+
+// Msg returns the MsgPost equivalent to the i-th order of the batch.
+func (msg MsgBatchPost) Msg(i int) MsgPost {
+	req := msg.Orders[i]
+	return NewMsgPostWithType(msg.Owner, req.MarketID, req.OrderType, req.Direction, req.Price, req.Quantity, req.MaxSpend, req.TimeInForce)
+}
+
+func (msg MsgBatchPost) Route() string {
+	return "order"
+}
+
+func (msg MsgBatchPost) Type() string {
+	return "batch_post"
+}
+
+func (msg MsgBatchPost) ValidateBasic() sdk.Error {
+	if msg.Owner.Empty() {
+		return sdk.ErrUnauthorized("owner cannot be empty")
+	}
+	if len(msg.Orders) == 0 {
+		return sdk.ErrUnknownRequest("batch cannot be empty")
+	}
+	if len(msg.Orders) > MaxBatchSize {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("batch cannot contain more than %d orders", MaxBatchSize))
+	}
+	for i := range msg.Orders {
+		if err := msg.Msg(i).ValidateBasic(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (msg MsgBatchPost) GetSignBytes() []byte {
+	return serde.MustMarshalSortedJSON(msg)
+}
+
+func (msg MsgBatchPost) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgBatchCancel cancels several orders atomically: if any of them cannot be
+// cancelled, none are.
+type MsgBatchCancel struct {
+	Owner    sdk.AccAddress   `json:"owner" yaml:"owner"`
+	OrderIDs []store.EntityID `json:"order_ids" yaml:"order_ids"`
+}
+
+func NewMsgBatchCancel(owner sdk.AccAddress, orderIDs []store.EntityID) MsgBatchCancel {
+	return MsgBatchCancel{
+		Owner:    owner,
+		OrderIDs: orderIDs,
+	}
+}
+
+func (msg MsgBatchCancel) Route() string {
+	return "order"
+}
+
+func (msg MsgBatchCancel) Type() string {
+	return "batch_cancel"
+}
+
+func (msg MsgBatchCancel) ValidateBasic() sdk.Error {
+	if msg.Owner.Empty() {
+		return sdk.ErrUnauthorized("owner cannot be empty")
+	}
+	if len(msg.OrderIDs) == 0 {
+		return sdk.ErrUnknownRequest("batch cannot be empty")
+	}
+	if len(msg.OrderIDs) > MaxBatchSize {
+		return sdk.ErrUnknownRequest(fmt.Sprintf("batch cannot contain more than %d orders", MaxBatchSize))
+	}
+	for _, id := range msg.OrderIDs {
+		if !id.IsDefined() {
+			return sdk.ErrInternal("invalid order ID")
+		}
+	}
+	return nil
+}
+
+func (msg MsgBatchCancel) GetSignBytes() []byte {
+	return serde.MustMarshalSortedJSON(msg)
+}
+
+func (msg MsgBatchCancel) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgAmend reprices a resting order or reduces its quantity. The order keeps
+// its ID, and the difference in escrow is taken from or returned to the owner.
+type MsgAmend struct {
+	Owner    sdk.AccAddress `json:"owner" yaml:"owner"`
+	OrderID  store.EntityID `json:"order_id" yaml:"order_id"`
+	Price    sdk.Uint       `json:"price" yaml:"price"`
+	Quantity sdk.Uint       `json:"quantity" yaml:"quantity"`
+}
+
+func NewMsgAmend(owner sdk.AccAddress, orderID store.EntityID, price sdk.Uint, quantity sdk.Uint) MsgAmend {
+	return MsgAmend{
+		Owner:    owner,
+		OrderID:  orderID,
+		Price:    price,
+		Quantity: quantity,
+	}
+}
+
+func (msg MsgAmend) Route() string {
+	return "order"
+}
+
+func (msg MsgAmend) Type() string {
+	return "amend"
+}
+
+func (msg MsgAmend) ValidateBasic() sdk.Error {
+	if msg.Owner.Empty() {
+		return sdk.ErrUnauthorized("owner cannot be empty")
+	}
+	if !msg.OrderID.IsDefined() {
+		return sdk.ErrInternal("invalid order ID")
+	}
+	if isZeroOrUnset(msg.Price) {
+		return sdk.ErrInvalidCoins("price cannot be zero")
+	}
+	if isZeroOrUnset(msg.Quantity) {
+		return sdk.ErrInvalidCoins("quantity cannot be zero")
+	}
+	return nil
+}
+
+func (msg MsgAmend) GetSignBytes() []byte {
+	return serde.MustMarshalSortedJSON(msg)
+}
+
+func (msg MsgAmend) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// MsgPostConditional places a stop-loss or take-profit order. Funds are
+// escrowed immediately, exactly as for MsgPost, but no order reaches the book
+// until the price given by TriggerSource crosses TriggerPrice.
+type MsgPostConditional struct {
+	Owner         sdk.AccAddress     `json:"owner" yaml:"owner"`
+	MarketID      store.EntityID     `json:"market_id" yaml:"market_id"`
+	Direction     matcheng.Direction `json:"direction" yaml:"direction"`
+	Kind          TriggerKind        `json:"kind" yaml:"kind"`
+	TriggerPrice  sdk.Uint           `json:"trigger_price" yaml:"trigger_price"`
+	OrderType     matcheng.OrderType `json:"order_type,omitempty" yaml:"order_type"`
+	Price         sdk.Uint           `json:"price" yaml:"price"`
+	Quantity      sdk.Uint           `json:"quantity" yaml:"quantity"`
+	MaxSpend      sdk.Uint           `json:"max_spend" yaml:"max_spend"`
+	TimeInForce   uint16             `json:"time_in_force" yaml:"time_in_force"`
+	TriggerSource TriggerSource      `json:"trigger_source,omitempty" yaml:"trigger_source"`
+}
+
+func NewMsgPostConditional(owner sdk.AccAddress, marketID store.EntityID, direction matcheng.Direction, kind TriggerKind, source TriggerSource, triggerPrice sdk.Uint, orderType matcheng.OrderType, price sdk.Uint, quantity sdk.Uint, maxSpend sdk.Uint, tif uint16) MsgPostConditional {
+	return MsgPostConditional{
+		Owner:         owner,
+		MarketID:      marketID,
+		Direction:     direction,
+		Kind:          kind,
+		TriggerPrice:  triggerPrice,
+		OrderType:     orderType,
+		Price:         price,
+		Quantity:      quantity,
+		MaxSpend:      maxSpend,
+		TimeInForce:   tif,
+		TriggerSource: source,
+	}
+}
+
+func (msg MsgPostConditional) Route() string {
+	return "order"
+}
+
+func (msg MsgPostConditional) Type() string {
+	return "post_conditional"
+}
+
+func (msg MsgPostConditional) ValidateBasic() sdk.Error {
+	if msg.Owner.Empty() {
+		return sdk.ErrUnauthorized("owner cannot be empty")
+	}
+	if !msg.Kind.IsValid() {
+		return sdk.ErrUnknownRequest("invalid trigger kind")
+	}
+	if !msg.TriggerSource.IsValid() {
+		return sdk.ErrUnknownRequest("invalid trigger source")
+	}
+	if isZeroOrUnset(msg.TriggerPrice) {
+		return sdk.ErrInvalidCoins("trigger price cannot be zero")
+	}
+	// the order that is posted once triggered must be valid on its own
+	return NewMsgPostWithType(
+		msg.Owner,
+		msg.MarketID,
+		msg.OrderType,
+		msg.Direction,
+		msg.Price,
+		msg.Quantity,
+		msg.MaxSpend,
+		msg.TimeInForce,
+	).ValidateBasic()
+}
+
+func (msg MsgPostConditional) GetSignBytes() []byte {
+	return serde.MustMarshalSortedJSON(msg)
+}
+
+func (msg MsgPostConditional) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+type MsgCancelConditional struct {
+	Owner         sdk.AccAddress `json:"owner" yaml:"owner"`
+	ConditionalID store.EntityID `json:"conditional_id" yaml:"conditional_id"`
+}
+
+func NewMsgCancelConditional(owner sdk.AccAddress, conditionalID store.EntityID) MsgCancelConditional {
+	return MsgCancelConditional{
+		Owner:         owner,
+		ConditionalID: conditionalID,
+	}
+}
+
+func (msg MsgCancelConditional) Route() string {
+	return "order"
+}
+
+func (msg MsgCancelConditional) Type() string {
+	return "cancel_conditional"
+}
+
+func (msg MsgCancelConditional) ValidateBasic() sdk.Error {
+	if msg.Owner.Empty() {
+		return sdk.ErrUnauthorized("owner cannot be empty")
+	}
+	if !msg.ConditionalID.IsDefined() {
+		return sdk.ErrInternal("invalid conditional order ID")
+	}
+	return nil
+}
+
+func (msg MsgCancelConditional) GetSignBytes() []byte {
+	return serde.MustMarshalSortedJSON(msg)
+}
+
+func (msg MsgCancelConditional) GetSigners() []sdk.AccAddress {
+	return []sdk.AccAddress{msg.Owner}
+}
+
+// isZeroOrUnset treats a Uint that was never initialized, e.g. because the
+// field was omitted from a JSON request, as zero instead of panicking.
+func isZeroOrUnset(u sdk.Uint) bool {
+	return u.String() == "<nil>" || u.IsZero()
+}
@@ -20,9 +20,73 @@ func TestMsgSort(t *testing.T) {
 
 	msg := types.NewMsgPost(addr, marketID, matcheng.Bid, price, quantity, 600)
 
-	require.Equal(t, `{"direction":"BID","market_id":"1","owner":"cosmos1wdhk6e2wv9kk2j88d92","price":"3005","quantity":"10","time_in_force":600}`, string(msg.GetSignBytes()))
+	require.Equal(t, `{"direction":"BID","market_id":"1","max_spend":"0","owner":"cosmos1wdhk6e2wv9kk2j88d92","price":"3005","quantity":"10","time_in_force":600}`, string(msg.GetSignBytes()))
 	signed := auth.StdSignBytes(
 		"xar-chain-zafx", 4, 1, auth.NewStdFee(200000, nil), []sdk.Msg{msg}, "",
 	)
-	require.Equal(t, `{"account_number":"4","chain_id":"xar-chain-zafx","fee":{"amount":[],"gas":"200000"},"memo":"","msgs":[{"direction":"BID","market_id":"1","owner":"cosmos1wdhk6e2wv9kk2j88d92","price":"3005","quantity":"10","time_in_force":600}],"sequence":"1"}`, string(signed))
+	require.Equal(t, `{"account_number":"4","chain_id":"xar-chain-zafx","fee":{"amount":[],"gas":"200000"},"memo":"","msgs":[{"direction":"BID","market_id":"1","max_spend":"0","owner":"cosmos1wdhk6e2wv9kk2j88d92","price":"3005","quantity":"10","time_in_force":600}],"sequence":"1"}`, string(signed))
+}
+
+func TestMsgPost_ValidateBasic(t *testing.T) {
+	addr := sdk.AccAddress([]byte("someName"))
+	marketID := store.NewEntityID(1)
+	price := sdk.NewUint(3005)
+	quantity := sdk.NewUint(10)
+	zero := sdk.ZeroUint()
+
+	tests := []struct {
+		name      string
+		orderType matcheng.OrderType
+		direction matcheng.Direction
+		price     sdk.Uint
+		maxSpend  sdk.Uint
+		tif       uint16
+		ok        bool
+	}{
+		{"limit", matcheng.Limit, matcheng.Bid, price, zero, 600, true},
+		{"limit without tif", matcheng.Limit, matcheng.Bid, price, zero, 0, false},
+		{"limit with max spend", matcheng.Limit, matcheng.Bid, price, sdk.NewUint(1), 600, false},
+		{"ioc without tif", matcheng.ImmediateOrCancel, matcheng.Ask, price, zero, 0, true},
+		{"fok without price", matcheng.FillOrKill, matcheng.Ask, zero, zero, 0, false},
+		{"market bid", matcheng.Market, matcheng.Bid, zero, sdk.NewUint(100), 0, true},
+		{"market bid without max spend", matcheng.Market, matcheng.Bid, zero, zero, 0, false},
+		{"market bid with price", matcheng.Market, matcheng.Bid, price, sdk.NewUint(100), 0, false},
+		{"market ask", matcheng.Market, matcheng.Ask, zero, zero, 0, true},
+		{"market ask with max spend", matcheng.Market, matcheng.Ask, zero, sdk.NewUint(100), 0, false},
+		{"unknown type", matcheng.OrderType(99), matcheng.Bid, price, zero, 600, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := types.NewMsgPostWithType(addr, marketID, tt.orderType, tt.direction, tt.price, quantity, tt.maxSpend, tt.tif)
+			err := msg.ValidateBasic()
+			if tt.ok {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestMsgBatchPost_ValidateBasic(t *testing.T) {
+	addr := sdk.AccAddress([]byte("someName"))
+	valid := types.PostRequest{
+		MarketID:    store.NewEntityID(1),
+		Direction:   matcheng.Bid,
+		Price:       sdk.NewUint(3005),
+		Quantity:    sdk.NewUint(10),
+		TimeInForce: 600,
+		MaxSpend:    sdk.ZeroUint(),
+	}
+	invalid := valid
+	invalid.Quantity = sdk.ZeroUint()
+
+	require.NoError(t, types.NewMsgBatchPost(addr, []types.PostRequest{valid, valid}).ValidateBasic())
+	require.Error(t, types.NewMsgBatchPost(addr, nil).ValidateBasic())
+	require.Error(t, types.NewMsgBatchPost(addr, []types.PostRequest{valid, invalid}).ValidateBasic())
+	tooMany := make([]types.PostRequest, types.MaxBatchSize+1)
+	for i := range tooMany {
+		tooMany[i] = valid
+	}
+	require.Error(t, types.NewMsgBatchPost(addr, tooMany).ValidateBasic())
 }
@@ -5,6 +5,9 @@ import (
 	"strconv"
 
 	"github.com/olekukonko/tablewriter"
+
+	"github.com/xar-network/xar-network/pkg/matcheng"
+	"github.com/xar-network/xar-network/types/store"
 )
 
 type ListQueryResult struct {
@@ -19,6 +22,7 @@ func (l ListQueryResult) String() string {
 		"Owner",
 		"MarketID",
 		"Direction",
+		"Type",
 		"Price",
 		"Quantity",
 		"Time In Force",
@@ -31,6 +35,7 @@ func (l ListQueryResult) String() string {
 			o.Owner.String(),
 			o.MarketID.String(),
 			o.Direction.String(),
+			o.Type.String(),
 			o.Price.String(),
 			o.Quantity.String(),
 			strconv.FormatUint(uint64(o.TimeInForceBlocks), 10),
@@ -40,3 +45,72 @@ func (l ListQueryResult) String() string {
 	t.Render()
 	return string(buf.Bytes())
 }
+
+type ListConditionalQueryResult struct {
+	Orders []ConditionalOrder `json:"orders"`
+}
+
+func (l ListConditionalQueryResult) String() string {
+	var buf bytes.Buffer
+	t := tablewriter.NewWriter(&buf)
+	t.SetHeader([]string{
+		"ID",
+		"Owner",
+		"MarketID",
+		"Direction",
+		"Kind",
+		"Trigger Source",
+		"Trigger Price",
+		"Type",
+		"Price",
+		"Quantity",
+		"Time In Force",
+		"Created Block",
+	})
+
+	for _, o := range l.Orders {
+		t.Append([]string{
+			o.ID.String(),
+			o.Owner.String(),
+			o.MarketID.String(),
+			o.Direction.String(),
+			o.Kind.String(),
+			o.Source.String(),
+			o.TriggerPrice.String(),
+			o.Type.String(),
+			o.Price.String(),
+			o.Quantity.String(),
+			strconv.FormatUint(uint64(o.TimeInForceBlocks), 10),
+			strconv.Itoa(int(o.CreatedBlock)),
+		})
+	}
+	t.Render()
+	return string(buf.Bytes())
+}
+
+// BookQueryResult holds the open orders of a market at a block, each side
+// sorted lowest price first.
+type BookQueryResult struct {
+	MarketID    store.EntityID `json:"market_id"`
+	BlockNumber int64          `json:"block_number"`
+	Bids        []Order        `json:"bids"`
+	Asks        []Order        `json:"asks"`
+}
+
+// Aggregates returns the cumulative bid and ask aggregates of the book.
+func (b BookQueryResult) Aggregates() ([]matcheng.AggregatePrice, []matcheng.AggregatePrice) {
+	return matcheng.AggregateBook(matcherOrders(b.Bids), matcherOrders(b.Asks))
+}
+
+func matcherOrders(orders []Order) []matcheng.Order {
+	out := make([]matcheng.Order, len(orders))
+	for i, o := range orders {
+		out[i] = matcheng.Order{
+			ID:       o.ID,
+			Owner:    o.Owner,
+			Price:    o.Price,
+			Quantity: o.Quantity,
+		}
+	}
+	return out
+}
@@ -18,6 +18,7 @@ type Order struct {
 	Quantity          sdk.Uint           `json:"quantity"`
 	TimeInForceBlocks uint16             `json:"time_in_force_blocks"`
 	CreatedBlock      int64              `json:"created_block"`
+	Type              matcheng.OrderType `json:"type"`
 }
 
 func New(owner sdk.AccAddress, marketID store.EntityID, direction matcheng.Direction, price sdk.Uint, quantity sdk.Uint, tif uint16, created int64) Order {